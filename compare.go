@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/vdmitriyev/gogitstats/pkg/gogitstats"
+)
+
+// runCompare implements the `compare` command, which compares the contributions of two repositories
+// (e.g., a fork and its upstream) and reports how far they diverged.
+func runCompare(args []string) {
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	basePath := flags.String("base", "", "Path to the base git repository, e.g. upstream (directory or URL)")
+	headPath := flags.String("head", "", "Path to the git repository compared with the base, e.g. a fork (directory or URL)")
+	baseBranch := flags.String("base-branch", "main", "Branch of the base repository")
+	headBranch := flags.String("head-branch", "main", "Branch of the compared repository")
+	optionOutput, optionOverwrite := outputOptions(flags)
+	flags.Parse(args)
+	useOutput(*optionOutput)
+
+	if *basePath == "" || *headPath == "" {
+		log.Fatal("Please provide paths to both git repositories with options `--base` and `--head`")
+	}
+
+	analyzer, err := gogitstats.NewAnalyzer(gogitstats.Options{Output: *optionOutput, Overwrite: *optionOverwrite})
+	if err != nil {
+		log.Fatalf("Given options are not supported: %v", err)
+	}
+
+	report, err := analyzer.Compare(*basePath, *baseBranch, *headPath, *headBranch)
+	if err != nil {
+		log.Fatalf("Error comparing repositories: %v", err)
+	}
+
+	if _, err := analyzer.WriteCompare(report); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
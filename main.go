@@ -1,74 +1,246 @@
 package main
 
 import (
-	"bytes"
-	"errors"
 	"flag"
 	"fmt"
-	"html/template"
 	"log"
-	"net/url"
+	"net"
 	"os"
-	"os/exec"
-	"path/filepath"
-	"sort"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"time"
-)
-
-var defaultMainBranchName string = "main"
-var defaultGroupByForLogDate string = "month"
 
-const REPOSITORIES_DIRECTORY = ".repositories"
+	"github.com/vdmitriyev/gogitstats/pkg/gogitstats"
+)
 
 var version string = "0.1.2"
 var build string = "0.0.0" // do not remove or modify
 
-type UserContribution struct {
-	Email                string
-	CommitCount          int
-	ContributionTimeline map[string]int // Year-Week: count
-	LinesAdded           int
-	LinesRemoved         int
-	LinesEdited          int
-	FileFilter           string
+type customLogWriter struct {
+	// toStderr keeps the standard output free for the report written to it, see `--output -`
+	toStderr bool
 }
 
-type BranchReport struct {
-	BranchName    string
-	Contributions map[string]*UserContribution
+func (writer customLogWriter) Write(bytes []byte) (int, error) {
+	line := time.Now().UTC().Format("2006-01-02 15:04:05") + " " + string(bytes)
+	if writer.toStderr {
+		return fmt.Fprint(os.Stderr, line)
+	}
+	return fmt.Print(line)
 }
 
-type ReportData struct {
-	RepoName      string
-	FileFilter    string
-	BranchReports map[string]*BranchReport
+// outputOptions registers `--output` (with the shorthand `-o`) and `--overwrite` on the flag set.
+func outputOptions(flags *flag.FlagSet) (*string, *bool) {
+	output := flags.String("output", "", "File or directory (ending with '/' or existing) the report is written to, '-' writes it to the standard output (default: timestamped file in the current directory)")
+	flags.StringVar(output, "o", "", "Shorthand for 'output'")
+	overwrite := flags.Bool("overwrite", false, "Name the report without timestamp (e.g., report_project.html) and replace an existing file")
+	return output, overwrite
 }
 
-type customLogWriter struct {
+// finishSnapshot completes the snapshot of `--snapshots`, if enabled.
+func finishSnapshot(analyzer *gogitstats.Analyzer, snapshots string) {
+	if snapshots == "" {
+		return
+	}
+	if err := analyzer.FinishSnapshot(); err != nil {
+		log.Fatalf("Error completing the snapshot: %v", err)
+	}
 }
 
-func (writer customLogWriter) Write(bytes []byte) (int, error) {
-	return fmt.Print(time.Now().UTC().Format("2006-01-02 15:04:05") + " " + string(bytes))
+// useOutput sends the log to the standard error if the report is written to the standard output.
+func useOutput(output string) {
+	if output == gogitstats.OUTPUT_STDOUT {
+		log.SetOutput(customLogWriter{toStderr: true})
+	}
+}
+
+// repositoryList collects the repositories given with repeated or comma-separated `--repository` options.
+type repositoryList []string
+
+func (list *repositoryList) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *repositoryList) Set(value string) error {
+	for _, repoPath := range strings.Split(value, ",") {
+		if repoPath = strings.TrimSpace(repoPath); repoPath != "" {
+			*list = append(*list, repoPath)
+		}
+	}
+	return nil
+}
+
+// authorList collects the author patterns given with repeated or comma-separated `--author` options.
+type authorList []string
+
+func (list *authorList) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *authorList) Set(value string) error {
+	for _, pattern := range strings.Split(value, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			*list = append(*list, pattern)
+		}
+	}
+	return nil
+}
+
+// readRepositoryList reads the repositories listed in a file, one path or URL per line. Empty lines and
+// lines starting with '#' are skipped.
+func readRepositoryList(listPath string) ([]string, error) {
+	content, err := os.ReadFile(listPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var repositories []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			repositories = append(repositories, line)
+		}
+	}
+	return repositories, nil
 }
 
 func main() {
 	log.SetFlags(0)
 	log.SetOutput(new(customLogWriter))
 
-	if err := isGitInstalled(); err != nil {
+	gogitstats.Version = version
+	gogitstats.Build = build
+
+	// rendering and merging stored data does not need git
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		runRender(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "merge" {
+		runMerge(os.Args[2:])
+		return
+	}
+
+	if err := gogitstats.IsGitInstalled(); err != nil {
 		log.Fatalf("Error: %s", err)
 	}
 
-	repoPath := flag.String("repository", "", "Path to the git repository (directory or URL)")
-	fileFilter := flag.String("filter", "", "Filter for file types (e.g., go, py, etc.). Optional")
-	optoinMainBranch := flag.String("mainbranch", defaultMainBranchName, "Name of the 'main' branch for merge-base")
-	optionGroupByForLogDate := flag.String("groupby", defaultGroupByForLogDate, "Group git log date by 'week' or 'month'")
+	if len(os.Args) > 1 && os.Args[1] == "compare" {
+		runCompare(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "mirror" {
+		runMirror(os.Args[2:])
+		return
+	}
+	// serve takes the flags of the analysis, which is re-run while the report is served
+	serveMode := len(os.Args) > 1 && os.Args[1] == "serve"
+	if serveMode {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
+	defaults := gogitstats.DefaultOptions()
+
+	var repoPaths repositoryList
+	flag.Var(&repoPaths, "repository", "Path to the git repository (directory or URL). Can be repeated or given as comma-separated list to write a combined report of all repositories")
+	optionRepositoriesFile := flag.String("repositories-file", "", "Path to a file listing git repositories (directories or URLs), one per line, which are analyzed like repeated `--repository` options. Optional")
+	optionDiscover := flag.String("discover", "", "Directory searched for git repositories (including nested and linked ones), each of them is analyzed. Replaces 'repository'")
+	fileFilter := flag.String("filter", "", "Comma-separated list of file types (e.g., go,proto) and pathspecs (e.g., 'src/*.ts', ':!vendor/') the analysis is limited to. Optional")
+	optionSSHKey := flag.String("ssh-key", "", "Private key authenticating to repositories given by SSH URL (e.g., git@github.com:owner/repo.git), stored in the configuration of the clone. Access tokens for HTTPS URLs are taken from the environment variables GIT_TOKEN (the host of the repository) and GITHUB_TOKEN (github.com). Optional")
+	optionCredentialHelper := flag.String("credential-helper", "", "Git credential helper used to clone repositories given by URL next to the configured ones (e.g., store, '!gh auth git-credential'). Optional")
+	optionReference := flag.String("reference", "", "Local repository, or directory of mirrors (e.g., a mirror farm holding 'project.git'), whose objects are borrowed by clones of repositories given by URL instead of downloading them again (git alternates). Optional")
+	optionCloneDepth := flag.Int("clone-depth", 0, "Clone repositories given by URL with the last commits of each branch only (shallow clone), older history is not analyzed. Optional")
+	optionSingleBranch := flag.Bool("single-branch", false, "Clone repositories given by URL with one branch only: the main branch if set with 'mainbranch', otherwise the default branch of the remote")
+	optionSubdir := flag.String("subdir", "", "Subdirectory of the repository the analysis is limited to (e.g., services/billing), repositories given by URL are cloned with a sparse checkout of it. Optional")
+	optoinMainBranch := flag.String("mainbranch", "main", "Name of the 'main' branch for merge-base")
+	optionSince := flag.String("since", "", "Only analyze commits since a date (e.g., 2024-01-01) or a relative value (e.g., 6.months). Optional")
+	optionNoMerges := flag.Bool("no-merges", false, "Exclude merge commits from the contributor statistics")
+	optionUniqueCommits := flag.Bool("unique-commits", false, "Report only the commits of each branch which no other analyzed branch contains, so no commit is counted twice in the branch tables")
+	optionRevRange := flag.String("rev-range", "", "Only analyze the commits of a revision range (e.g., v1.2.0..v1.3.0) in place of the main branch, other branches are not analyzed. Optional")
+	optionUntil := flag.String("until", "", "Only analyze commits until a date (e.g., 2024-03-31) or a relative value (e.g., 1.month). Optional")
+	optionGroupByForLogDate := flag.String("groupby", defaults.GroupBy, "Group git log date by 'week' or 'month'")
+	optionBatchSize := flag.Int("batch-size", defaults.BatchSize, "Number of commits parsed from git log before they are aggregated")
+	optionWorkers := flag.Int("workers", defaults.Workers, "Number of branches analyzed concurrently")
+	optionConfig := flag.String("config", "", "Path to a YAML configuration file (e.g., path rules for roles). Optional")
+	optionConfluenceURL := flag.String("confluence-url", "", "Base URL of Confluence to publish the report to (e.g., https://example.atlassian.net/wiki). Optional")
+	optionConfluencePage := flag.String("confluence-page", "", "ID of the Confluence page replaced by the report. Required with 'confluence-url'")
+	optionJiraURL := flag.String("jira-url", "", "Base URL of Jira used to group work referencing Jira tickets by epic/project. Optional")
+	optionGitHubRepo := flag.String("github-repo", "", "GitHub repository (owner/name) to report review-to-merge latency of pull requests. Optional")
+	optionGitHubAPIURL := flag.String("github-api-url", defaults.GitHubAPIURL, "Base URL of the GitHub API (e.g., of GitHub Enterprise)")
+	optionGitLabProject := flag.String("gitlab-project", "", "GitLab project (group/project) to report review-to-merge latency of merge requests. Optional")
+	optionGitLabURL := flag.String("gitlab-url", defaults.GitLabURL, "Base URL of GitLab")
+	optionReconcileSquash := flag.Bool("reconcile-squash-merges", false, "Credit squash commits of changes merged into the main branch to the authors and co-authors of the squashed commits (fetched from GitHub or GitLab) instead of whoever merged them")
+	optionAPIDump := flag.String("api-dump", "", "Directory of exported GitHub, GitLab and Jira API responses, which are read instead of the live APIs (e.g., without internet access). Optional")
+	optionJiraEpicField := flag.String("jira-epic-field", "", "Jira field holding the epic link of company-managed projects (e.g., customfield_10014). Optional")
+	optionRiskMaxCommits := flag.Int("risk-max-commits", defaults.RiskMaxCommits, "Number of unmerged commits after which a branch is flagged as integration risk")
+	optionDeployMarkers := flag.String("deploy-markers", defaults.DeployMarker, "Deploy markers of the delivery metrics: 'tags' (merged into the main branch) or 'merges' (into the main branch)")
+	optionForecastPeriods := flag.Int("forecast-periods", defaults.ForecastPeriods, "Number of periods (see 'groupby') for which the activity of every branch is forecasted, 0 disables the forecast")
+	optionFocusDepth := flag.Int("focus-depth", defaults.FocusDepth, "Number of leading directories forming an area of the focus metric (e.g., 2: 'src/billing')")
+	optionByPath := flag.String("by-path", "", "Break the contributions of each branch down by 'directory' or 'file' to show who owns which modules. Optional")
+	optionPathDepth := flag.Int("path-depth", defaults.PathDepth, "Number of leading directories forming a directory of the path breakdown (e.g., 1: 'src', 2: 'src/billing')")
+	optionContentionWindow := flag.Int("contention-window", defaults.ContentionWindow, "Window in days, in which edits of a file by different authors count as contention")
+	optionContentionMinAuthors := flag.Int("contention-min-authors", defaults.ContentionMinAuthors, "Number of distinct authors editing a file within the contention window, after which the file is reported as hot zone")
+	optionPairingWindow := flag.Duration("pairing-window", defaults.PairingWindow, "Maximum time between commits of different authors on the same file, which are considered as pairing or hand-off")
+	optionImports := flag.String("imports", defaults.Imports, "How commits detected as initial import or vendoring (e.g., 'Imported from SVN', huge commits only adding files) are counted: 'keep' (only reported), 'exclude' or 'separate' (attributed to 'imports')")
+	optionImportMinFiles := flag.Int("import-min-files", defaults.ImportMinFiles, "Number of files from which a commit adding (almost) only lines is detected as import")
+	optionLocalActivity := flag.Bool("local-activity", false, "Report the work recorded in the reflog of local repositories (amends, rebases, unpublished and abandoned branches)")
+	optionLeaderboard := flag.Bool("leaderboard", false, "Add a gamified section (monthly leaderboard, streak badges, first-contribution shout-outs) for community engagement reports")
+	optionRiskMaxDays := flag.Int("risk-max-days", defaults.RiskMaxDays, "Days since the merge-base after which a branch with unmerged work is flagged as integration risk")
+	var authors authorList
+	flag.Var(&authors, "author", "Limit the contributions to authors whose email matches a glob (e.g., '*@example.com') or a regular expression enclosed in slashes (e.g., '/^(jane|john)@/'). Can be repeated or given as comma-separated list")
+	optionBranches := flag.String("branches", "", "Comma-separated glob patterns of the branches to analyze next to the main branch (e.g., 'feature/*,release/*'). Asked for in a terminal if the repository has many branches, all branches otherwise")
+	optionReleaseBranches := flag.String("release-branches", "", "Glob pattern of release branches to report backport coverage for (e.g., 'release/*'). Optional")
+	optionBackportPattern := flag.String("backport-pattern", defaults.BackportPattern, "Regular expression matching subjects of mainline fixes expected to be backported")
+	optionFormat := flag.String("format", defaults.Format, "Format of the generated report: "+strings.Join(gogitstats.REPORT_FORMATS, ", "))
+	optionOutput, optionOverwrite := outputOptions(flag.CommandLine)
+	optionSnapshots := flag.String("snapshots", "", "Directory of dated snapshots (e.g., for nightly runs): the reports are written into the subdirectory of the day, older snapshots are removed by the retention policy and '"+gogitstats.SNAPSHOT_LATEST+"' and '"+gogitstats.SNAPSHOT_INDEX+"' lead to the latest one. Optional")
+	optionKeepLast := flag.Int("keep-last", defaults.SnapshotKeepLast, "Number of latest snapshots kept by `--snapshots`")
+	optionKeepMonthly := flag.Int("keep-monthly", defaults.SnapshotKeepMonthly, "Number of months whose last snapshot is kept by `--snapshots` in addition")
+	optionOffline := flag.Bool("offline", false, "Inline Bootstrap into HTML reports instead of linking it from the CDN, so they work without internet access (e.g., on air-gapped networks)")
+	optionTheme := flag.String("theme", defaults.Theme, "Default theme of the HTML report: 'dark' or 'light'")
+	optionLanguage := flag.String("lang", defaults.Language, "Language of the report: "+strings.Join(gogitstats.ReportLanguages(), ", "))
+	optionSections := flag.String("sections", "", "Comma-separated list of report sections: "+strings.Join(gogitstats.REPORT_SECTIONS, ", ")+" (default all, or as configured)")
+	optionSortBy := flag.String("sort-by", defaults.SortBy, "Order of the contributor tables: "+strings.Join(gogitstats.CONTRIBUTION_SORT_KEYS, ", "))
+	optionTop := flag.Int("top", 0, "Number of contributors listed per table, further contributors are summed up in an 'others' row (default: all)")
+	optionColumns := flag.String("columns", strings.Join(gogitstats.REPORT_COLUMNS, ","), "Comma-separated list of columns shown in the report. Line counts are hidden everywhere if 'added', 'removed' and 'edited' are omitted")
+	var excludedAuthors authorList
+	flag.Var(&excludedAuthors, "exclude-author", "Exclude the contributions of authors whose email matches a glob or a regular expression enclosed in slashes (like 'author'), e.g. of service accounts. Can be repeated or given as comma-separated list")
+	optionExcludeBots := flag.Bool("exclude-bots", false, "Exclude the contributions of automation accounts (e.g., dependabot[bot], renovate, github-actions, noreply addresses), so they do not dominate the rankings")
+	optionMailmap := flag.String("mailmap", "", "Path to a mailmap file merging the emails of authors (default: .mailmap of the repository). Optional")
+	optionTeams := flag.String("teams", "", "Path to a YAML or JSON file mapping emails to team names, adds the contributions by team to the reports. Optional")
+	optionPreset := flag.String("preset", "", "Preset of analysis settings: "+strings.Join(gogitstats.ANALYSIS_PRESETS, ", ")+" (default 'standard'). Options given explicitly take precedence")
+	optionMainBranchOnly := flag.Bool("main-branch-only", false, "Only analyze the main branch")
+	optionRemoteBranches := flag.Bool("remote-branches", false, "Also analyze the remote-tracking branches of 'origin' without a local branch, read without checking them out (always done for repositories given by URL)")
+	optionProfile := flag.String("profile", "", "Name of an output profile defined in the configuration file (e.g., external), which controls what the report reveals. Optional")
+	optionWatch := flag.Bool("watch", false, "Watch local repositories (`--repository` or `--discover`) for new commits and serve live reports, which are refreshed automatically")
+	optionWatchInterval := flag.Duration("watch-interval", 5*time.Second, "Interval of checking the watched repository for new commits")
+	optionWatchAddress := flag.String("watch-address", "localhost:8080", "Address the live report of `--watch` is served on")
+	optionPort := flag.Int("port", 8080, "Port the reports of `serve` are served on")
+	optionHost := flag.String("host", "localhost", "Host the reports of `serve` are served on, e.g. 0.0.0.0 for all interfaces")
+	optionRefreshInterval := flag.Duration("refresh-interval", 0, "Interval of re-analyzing the repositories served by `serve`, 0 re-analyzes them when a report is requested")
+	optionOIDCIssuer := flag.String("oidc-issuer", "", "Issuer URL of an OpenID Connect provider (e.g., https://example.okta.com), whose login protects the report served by `--watch` or `serve`. Optional")
+	optionOIDCClientID := flag.String("oidc-client-id", "", "Client ID registered at the OpenID Connect provider, the client secret is taken from the environment variable OIDC_CLIENT_SECRET")
+	optionOIDCRedirectURL := flag.String("oidc-redirect-url", "", "Callback URL registered at the OpenID Connect provider (default: http://<watch-address> or http://<host>:<port> of `serve`)"+gogitstats.OIDC_CALLBACK_PATH+")")
+	optionOIDCAllowedDomains := flag.String("oidc-allowed-domains", "", "Comma-separated list of email domains allowed to read the report (default: all authenticated users)")
+	optionAttestationKey := flag.String("attestation-key", "", "Path to a PEM encoded PKCS#8 private key (Ed25519, ECDSA or RSA) signing an in-toto attestation written next to each report. Optional")
+	optionInsights := flag.Bool("insights", false, "Write a one-page contribution insights document (HTML, printable as PDF) per author next to the report")
+	optionCalendars := flag.Bool("calendars", false, "Write the active days of each contributor as iCalendar (.ics) file next to the report")
+	optionCache := flag.Bool("cache", defaults.UseCache, "Reuse results of unchanged branches from previous runs")
+	optionResume := flag.Bool("resume", false, "Save the analyzed branches while the analysis runs, so an interrupted run resumes where it left off when started again with this option (implies 'cache')")
+	optionMaxMemory := flag.String("max-memory", "", "Soft memory limit of the analysis (e.g., 512MB, 2GB), parsed commits beyond a quarter of it are spilled to the commit store of --cache or to a temporary one (default 256MB with --cache). Optional")
+	optionMaxCacheSize := flag.String("max-cache-size", "", "Size limit of the clones and caches in "+gogitstats.REPOSITORIES_DIRECTORY+" (e.g., 5GB), the least recently used are removed before and after the run. Optional")
+	optionStrict := flag.Bool("strict", false, "Fail instead of logging and ignoring a failed merge-base or git log of a branch and unparsable git log output")
 	versionFull := flag.Bool("version", false, "Prints full version of CLI")
 	versionShort := flag.Bool("version-short", false, "Prints version of CLI")
 
 	flag.Parse()
+	useOutput(*optionOutput)
+
+	// options given on the command line take precedence over the configuration
+	explicitOptions := make(map[string]bool)
+	flag.Visit(func(option *flag.Flag) {
+		explicitOptions[option.Name] = true
+	})
 
 	if *versionShort {
 		fmt.Println(version)
@@ -81,403 +253,367 @@ func main() {
 		return
 	}
 
-	if *repoPath == "" {
+	if *optionRepositoriesFile != "" {
+		listed, err := readRepositoryList(*optionRepositoriesFile)
+		if err != nil {
+			log.Fatalf("Error reading repositories file: %v", err)
+		}
+		repoPaths = append(repoPaths, listed...)
+	}
+	if len(repoPaths) == 0 && *optionDiscover == "" {
 		log.Fatal("Please provide path to the git repository with option `--repository`")
 	}
+	if len(repoPaths) > 0 && *optionDiscover != "" {
+		log.Fatal("Options `--repository` and `--discover` must not be used together")
+	}
+	if *optionCloneDepth < 0 {
+		log.Fatal("Option `--clone-depth` must not be negative")
+	}
 
-	u, err := url.Parse(*repoPath)
-	if err == nil && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "git" || u.Scheme == "ssh") {
-		log.Println("URL found. Cloning repository: ", *repoPath)
-		newRepoPath, err := cloneRepository(*repoPath, REPOSITORIES_DIRECTORY)
+	options := gogitstats.Options{
+		Subdir:               *optionSubdir,
+		Since:                *optionSince,
+		Until:                *optionUntil,
+		RevRange:             *optionRevRange,
+		NoMerges:             *optionNoMerges,
+		UniqueCommits:        *optionUniqueCommits,
+		MainBranchOnly:       *optionMainBranchOnly,
+		RemoteBranches:       *optionRemoteBranches,
+		Authors:              authors,
+		ExcludeAuthors:       excludedAuthors,
+		ExcludeBots:          *optionExcludeBots,
+		Preset:               *optionPreset,
+		GroupBy:              *optionGroupByForLogDate,
+		BatchSize:            *optionBatchSize,
+		Workers:              *optionWorkers,
+		UseCache:             *optionCache,
+		Resume:               *optionResume,
+		Strict:               *optionStrict,
+		Mailmap:              *optionMailmap,
+		Teams:                *optionTeams,
+		Profile:              *optionProfile,
+		Columns:              strings.Split(*optionColumns, ","),
+		SortBy:               *optionSortBy,
+		Top:                  *optionTop,
+		Format:               *optionFormat,
+		Theme:                *optionTheme,
+		Offline:              *optionOffline,
+		Output:               *optionOutput,
+		Overwrite:            *optionOverwrite,
+		Snapshots:            *optionSnapshots,
+		SnapshotKeepLast:     *optionKeepLast,
+		SnapshotKeepMonthly:  *optionKeepMonthly,
+		Language:             *optionLanguage,
+		RiskMaxCommits:       *optionRiskMaxCommits,
+		RiskMaxDays:          *optionRiskMaxDays,
+		DeployMarker:         *optionDeployMarkers,
+		BackportPattern:      *optionBackportPattern,
+		ForecastPeriods:      *optionForecastPeriods,
+		FocusDepth:           *optionFocusDepth,
+		PathBreakdown:        *optionByPath,
+		PathDepth:            *optionPathDepth,
+		ContentionWindow:     *optionContentionWindow,
+		ContentionMinAuthors: *optionContentionMinAuthors,
+		PairingWindow:        *optionPairingWindow,
+		Imports:              *optionImports,
+		ImportMinFiles:       *optionImportMinFiles,
+		LocalActivity:        *optionLocalActivity,
+		Leaderboard:          *optionLeaderboard,
+		GitHubRepo:           *optionGitHubRepo,
+		GitHubAPIURL:         *optionGitHubAPIURL,
+		GitLabProject:        *optionGitLabProject,
+		GitLabURL:            *optionGitLabURL,
+		JiraURL:              *optionJiraURL,
+		JiraEpicField:        *optionJiraEpicField,
+		APIDump:              *optionAPIDump,
+		ReconcileSquash:      *optionReconcileSquash,
+		Calendars:            *optionCalendars,
+		Insights:             *optionInsights,
+	}
+	for name, value := range map[string]*string{"max-memory": optionMaxMemory, "max-cache-size": optionMaxCacheSize} {
+		if *value == "" {
+			continue
+		}
+		size, err := gogitstats.ParseByteSize(*value)
 		if err != nil {
-			log.Fatalf("Error cloning repository: %v", err)
+			log.Fatalf("Given option for parameter '%s' is not supported: %v", name, err)
 		}
-
-		*repoPath = newRepoPath
-
-		if err := checkoutRemoteBranches(*repoPath); err != nil {
-			log.Fatalf("Error checking out all branched: %s", err)
+		if name == "max-memory" {
+			options.MaxMemory = size
+		} else {
+			options.MaxCacheSize = size
 		}
 	}
-
-	if _, err := os.Stat(*repoPath); os.IsNotExist(err) {
-		log.Fatalf("Repository path does not exist: %s", *repoPath)
+	if options.MaxMemory > 0 {
+		// the garbage collector runs more often near the limit, which is a setting of the whole process
+		debug.SetMemoryLimit(options.MaxMemory)
 	}
-
-	repoName := filepath.Base(*repoPath)
-	log.Printf("Analyzing repository: %s", repoName)
-
-	if *optoinMainBranch != "" {
-		defaultMainBranchName = *optoinMainBranch
-		log.Printf("Name of the main branch has been set to: %s", defaultMainBranchName)
+	if *optionSections != "" {
+		options.Sections = strings.Split(*optionSections, ",")
 	}
-
-	if *optionGroupByForLogDate != "" {
-		if (*optionGroupByForLogDate != "week") && (*optionGroupByForLogDate != "month") {
-			log.Fatalf("Given option for parameter 'groupby' is not supported. Excepted 'week' or 'month'. Given: %s", *optionGroupByForLogDate)
+	if explicitOptions["mainbranch"] {
+		options.MainBranch = *optoinMainBranch
+		log.Printf("Name of the main branch has been set to: %s", options.MainBranch)
+	}
+	if explicitOptions["filter"] {
+		options.FileFilter = *fileFilter
+	}
+	if *optionBranches != "" {
+		for _, pattern := range strings.Split(*optionBranches, ",") {
+			options.Branches = append(options.Branches, strings.TrimSpace(pattern))
 		}
-
-		defaultGroupByForLogDate = *optionGroupByForLogDate
-		log.Printf("Default group by option has been set to: %s", defaultGroupByForLogDate)
 	}
-
-	branchReports, err := analyzeGitHistoryByBranch(*repoPath, *fileFilter)
-	if err != nil {
-		log.Fatalf("Error analyzing git history: %v", err)
+	if explicitOptions["release-branches"] {
+		options.ReleaseBranches = *optionReleaseBranches
+	}
+	if explicitOptions["groupby"] {
+		log.Printf("Default group by option has been set to: %s", options.GroupBy)
 	}
 
-	htmlReport, err := generateHTMLReportByBranch(branchReports, repoName, *fileFilter)
-	if err != nil {
-		log.Fatalf("Error generating HTML report: %v", err)
+	// live reports are served by `--watch` and `serve`
+	liveMode, liveAddress := *optionWatch, *optionWatchAddress
+	if serveMode {
+		if *optionWatch {
+			log.Fatal("Option `--watch` is not supported with `serve`")
+		}
+		if *optionPort <= 0 || *optionPort > 65535 {
+			log.Fatalf("Given option for parameter 'port' is not supported. Expected a port between 1 and 65535. Given: %d", *optionPort)
+		}
+		liveMode, liveAddress = true, net.JoinHostPort(*optionHost, strconv.Itoa(*optionPort))
 	}
 
-	filename := fmt.Sprintf("report_%s_%s.html", repoName, time.Now().Format("2006-01-02_150405"))
-	err = os.WriteFile(filename, []byte(htmlReport), 0644)
-	if err != nil {
-		log.Fatalf("Error writing HTML report to file: %v", err)
+	if *optionFormat != gogitstats.REPORT_FORMAT_HTML && liveMode {
+		log.Fatal("Live reports of `--watch` and `serve` are HTML reports")
 	}
 
-	log.Printf("HTML report generated: %s\n", filename)
-}
+	if *optionOutput != "" && liveMode {
+		log.Fatal("Option `--output` is not supported with `--watch` and `serve`")
+	}
 
-// isGitInstalled checks if Git is installed and accessible in the system's PATH.
-//
-// It uses exec.LookPath to search for the "git" executable.
-//
-// Returns:
-//   - nil if Git is found.
-//   - An error if Git is not installed or not found in the PATH.
-func isGitInstalled() error {
-	_, err := exec.LookPath("git")
-	if err != nil {
-		return errors.New("git is not installed or not found in PATH")
+	if *optionSnapshots != "" && (liveMode || *optionOutput != "" || *optionOverwrite) {
+		log.Fatal("Option `--snapshots` is not supported with `--output`, `--overwrite`, `--watch` and `serve`")
 	}
-	return nil
-}
 
-// cloneRepository clones a Git repository from the given URL to the specified destination directory.
-//
-// It first checks if the destination directory exists. If not, it creates it.
-// Then, it derives the repository name from the URL and constructs the local repository path.
-// If the local repository does not exist, it executes the "git clone" command.
-// If the local repository already exists, it skips the cloning process.
-//
-// Parameters:
-//   - repoURL: The URL of the Git repository to clone.
-//   - destDir: The destination directory where the repository should be cloned.
-//
-// Returns:
-//   - The local path to the cloned repository.
-//   - An error, if any, occurred during the cloning process.
-func cloneRepository(repoURL, destDir string) (string, error) {
-
-	if _, err := os.Stat(destDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(destDir, 0755); err != nil {
-			return "", fmt.Errorf("failed to create directory %s: %w", destDir, err)
-		}
-	}
-
-	repoName := filepath.Base(repoURL)
-	localRepoPath := filepath.Join(destDir, repoName)
-
-	if _, err := os.Stat(localRepoPath); os.IsNotExist(err) {
-		cmd := exec.Command("git", "clone", repoURL, localRepoPath)
-		output, err := cmd.CombinedOutput()
+	if *optionAttestationKey != "" {
+		if liveMode {
+			log.Fatal("Option `--attestation-key` is not supported with `--watch` and `serve`")
+		}
+		signer, err := gogitstats.LoadAttestationKey(*optionAttestationKey)
 		if err != nil {
-			return "", fmt.Errorf("failed to clone repository: %s, output: %s", err, output)
+			log.Fatalf("Given option for parameter 'attestation-key' is not supported: %v", err)
 		}
-		log.Printf("Repository cloned to: %s", localRepoPath)
-	} else {
-		log.Printf("Repository already exists at: %s", localRepoPath)
-	}
-
-	return localRepoPath, nil
-}
-
-// checkoutRemoteBranches checks out all remote branches of a Git repository located at repoPath.
-//
-// It executes the following steps:
-//  1. Retrieves the list of remote branches using `git branch -r`.
-//  2. Iterates through each remote branch, skipping empty branches and symbolic HEAD references.
-//  3. If a branch starts with "origin/", it extracts the branch name and attempts to check it out locally
-//     using `git checkout -b <local_branch_name> <remote_branch_name>`.
-//  4. If the checkout fails and the error message does not indicate that the branch already exists,
-//     it returns an error.
-//
-// Parameters:
-//   - repoPath: The path to the Git repository.
-//
-// Returns:
-//   - nil if all remote branches are successfully checked out or already exist.
-//   - An error if any other error occurs during the process.
-func checkoutRemoteBranches(repoPath string) error {
-
-	log.Printf("Checking remote branches")
-
-	cmd := exec.Command("git", "branch", "-r")
-	cmd.Dir = repoPath
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to get remote branches: %w, output: %s", err, output)
+		options.AttestationSigner = signer
 	}
 
-	branches := strings.Split(string(output), "\n")
-
-	for _, branch := range branches {
-		branch = strings.TrimSpace(branch)
-		if branch == "" || strings.Contains(branch, "HEAD ->") { // Skip HEAD -> branches
-			continue
+	var err error
+	var auth *gogitstats.OIDCAuthenticator
+	if *optionOIDCIssuer != "" {
+		if !liveMode {
+			log.Fatal("Option `--oidc-issuer` is only supported with `--watch` and `serve`")
 		}
-
-		if strings.HasPrefix(branch, "origin/") {
-			branchName := strings.TrimPrefix(branch, "origin/")
-			branchName = strings.TrimSpace(branchName)
-
-			checkoutCmd := exec.Command("git", "checkout", "-b", branchName, branch)
-			checkoutCmd.Dir = repoPath
-
-			var stderr bytes.Buffer
-			checkoutCmd.Stderr = &stderr
-
-			err := checkoutCmd.Run()
-			if err != nil && !strings.Contains(stderr.String(), "already exists") {
-				return fmt.Errorf("failed to checkout branch %s: %w, stderr: %s", branchName, err, stderr.String())
+		if *optionOIDCClientID == "" {
+			log.Fatal("Please provide the client ID registered at the OpenID Connect provider with option `--oidc-client-id`")
+		}
+		redirectURL := *optionOIDCRedirectURL
+		if redirectURL == "" {
+			redirectURL = "http://" + liveAddress + gogitstats.OIDC_CALLBACK_PATH
+		}
+		if !strings.HasSuffix(redirectURL, gogitstats.OIDC_CALLBACK_PATH) {
+			log.Fatalf("Given option for parameter 'oidc-redirect-url' is not supported. Expected an URL ending with '%s'. Given: %s", gogitstats.OIDC_CALLBACK_PATH, redirectURL)
+		}
+		var allowedDomains []string
+		for _, domain := range strings.Split(*optionOIDCAllowedDomains, ",") {
+			if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+				allowedDomains = append(allowedDomains, domain)
 			}
 		}
+		auth, err = gogitstats.NewOIDCAuthenticator(*optionOIDCIssuer, *optionOIDCClientID, os.Getenv("OIDC_CLIENT_SECRET"), redirectURL, allowedDomains)
+		if err != nil {
+			log.Fatalf("Error configuring OIDC login: %v", err)
+		}
 	}
 
-	return nil
-}
-
-func analyzeGitHistoryByBranch(repoPath string, fileFilter string) (map[string]*BranchReport, error) {
-	cmdBranches := exec.Command("git", "branch", "--format=%(refname:short)")
-	cmdBranches.Dir = repoPath
-	outputBranches, err := cmdBranches.CombinedOutput()
-	if err != nil {
-		return nil, fmt.Errorf("git branch failed: %v, output: %s", err, outputBranches)
+	if (*optionConfluenceURL == "") != (*optionConfluencePage == "") {
+		log.Fatal("Options `--confluence-url` and `--confluence-page` must be used together")
 	}
 
-	branchNames := strings.Split(string(outputBranches), "\n")
-	branchReports := make(map[string]*BranchReport)
+	if *optionConfig != "" {
+		config, err := gogitstats.LoadConfig(*optionConfig)
+		if err != nil {
+			log.Fatalf("Error loading configuration: %v", err)
+		}
+		options.Config = config
+		log.Printf("Configuration has been loaded from: %s", *optionConfig)
+	}
 
-	for _, branchName := range branchNames {
+	if liveMode {
+		// unchanged branches are taken from the cache, so refreshes only analyze new commits
+		options.UseCache = true
+	}
 
-		branchName = strings.TrimSpace(branchName)
-		if branchName == "" {
-			continue
+	// clones and caches used by this run are not evicted
+	runStarted := time.Now()
+	enforceCacheSize := func() {
+		if err := gogitstats.EnforceCacheSize(options.MaxCacheSize, runStarted); err != nil {
+			log.Printf("Error limiting the cache size: %v", err)
 		}
+	}
+	enforceCacheSize()
+
+	cloneOptions := gogitstats.CloneOptions{
+		Subdir:           *optionSubdir,
+		Reference:        *optionReference,
+		Depth:            *optionCloneDepth,
+		SingleBranch:     *optionSingleBranch,
+		SSHKey:           *optionSSHKey,
+		CredentialHelper: *optionCredentialHelper,
+		// the contents of commits before the analyzed period are not needed
+		Blobless: *optionSince != "",
+	}
+	if explicitOptions["mainbranch"] {
+		cloneOptions.Branch = *optoinMainBranch
+	}
 
-		logRange := branchName
-
-		// Get merge base to get stats from the branch only
-		if branchName != defaultMainBranchName {
-			cmdMergeBase := exec.Command("git", "merge-base", defaultMainBranchName, branchName)
-			cmdMergeBase.Dir = repoPath
-			outputMergeBase, err := cmdMergeBase.CombinedOutput()
+	var repositories []string
+	var repoNames []string
+	// repoURLs are the URLs of cloned repositories, empty for local ones
+	var repoURLs []string
+	if *optionDiscover != "" {
+		repositories, err = gogitstats.DiscoverRepositories(*optionDiscover)
+		if err != nil {
+			log.Fatalf("Error discovering repositories: %v", err)
+		}
+		if len(repositories) == 0 {
+			log.Fatalf("No git repositories found in: %s", *optionDiscover)
+		}
+		log.Printf("Discovered %d git repositories in: %s", len(repositories), *optionDiscover)
+		for _, localPath := range repositories {
+			repoNames = append(repoNames, gogitstats.DiscoveredRepositoryName(*optionDiscover, localPath))
+		}
+	} else {
+		usedNames := make(map[string]int)
+		for _, repoPath := range repoPaths {
+			if *optionWatch && gogitstats.IsRepositoryURL(repoPath) {
+				log.Fatal("Option `--watch` is only supported for local repositories")
+			}
+			localPath, err := gogitstats.PrepareRepositoryWith(repoPath, cloneOptions)
 			if err != nil {
-				log.Printf("command 'git merge-base' for branch '%s' failed: %v; message: %s", branchName, err, outputMergeBase)
-				log.Printf("using default 'git log' range: %s", logRange)
+				log.Fatalf("Error: %v", err)
+			}
+			// equally named repositories (e.g., 'api' of different groups) are told apart by a suffix
+			repoName := gogitstats.RepositoryName(localPath)
+			if usedNames[repoName]++; usedNames[repoName] > 1 {
+				repoName = fmt.Sprintf("%s_%d", repoName, usedNames[repoName])
+			}
+			repositories = append(repositories, localPath)
+			repoNames = append(repoNames, repoName)
+			if gogitstats.IsRepositoryURL(repoPath) {
+				repoURLs = append(repoURLs, repoPath)
 			} else {
-				mergeBase := strings.TrimSpace(string(outputMergeBase))
-				logRange = fmt.Sprintf("%s..%s", mergeBase, branchName)
+				repoURLs = append(repoURLs, "")
 			}
 		}
+	}
 
-		branchReports[branchName] = &BranchReport{
-			BranchName:    branchName,
-			Contributions: make(map[string]*UserContribution),
+	// repositories with many branches are narrowed down interactively instead of analyzing everything
+	if len(repositories) == 1 && !explicitOptions["branches"] && !*optionMainBranchOnly && *optionPreset != gogitstats.PRESET_QUICK && !liveMode && *optionOutput != gogitstats.OUTPUT_STDOUT && isInteractive() {
+		branches, err := gogitstats.ListBranchesByActivity(repositories[0], *optionRemoteBranches)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
 		}
-
-		cmdLog := exec.Command("git", "log", "--pretty=format:%ae,%ad,%H", "--date=short", "--numstat", branchName)
-		if fileFilter != "" {
-			log.Printf("Applying for branch '%s' filter: %s", branchName, fileFilter)
-			cmdLog = exec.Command("git", "log", "--pretty=format:%ae,%ad,%H", "--date=short", "--numstat", logRange, "--", fileFilter)
+		if len(branches) >= promptBranchThreshold {
+			selected, err := promptBranches(os.Stdin, os.Stdout, repoNames[0], branches, *optoinMainBranch)
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+			options.Branches = selected
 		}
+	}
 
-		//log.Printf("git cmd: %s", cmdLog)
+	analyzer, err := gogitstats.NewAnalyzer(options)
+	if err != nil {
+		log.Fatalf("Given options are not supported: %v", err)
+	}
+	if *optionProfile != "" {
+		log.Printf("Output profile has been set to: %s", *optionProfile)
+	}
 
-		cmdLog.Dir = repoPath
-		outputLog, err := cmdLog.CombinedOutput()
+	if *optionWatch {
+		var watched []gogitstats.WatchedRepository
+		for i, localPath := range repositories {
+			watched = append(watched, gogitstats.WatchedRepository{Path: localPath, Name: repoNames[i]})
+		}
+		err := gogitstats.Watch(analyzer, watched, *optionWatchInterval, *optionWatchAddress, auth)
 		if err != nil {
-			log.Printf("git log for branch %s failed: %v, output: %s", branchName, err, outputLog)
-			continue
+			log.Fatalf("Error watching repository: %v", err)
 		}
+		return
+	}
 
-		linesLog := strings.Split(string(outputLog), "\n")
-		var currentCommit string
-		var currentDate string
-		var currentEmail string
-
-		for _, line := range linesLog {
-			if strings.Contains(line, "@") && strings.Contains(line, ",") {
-				parts := strings.Split(line, ",")
-				if len(parts) >= 3 {
-					currentEmail = parts[0]
-					currentDate = parts[1]
-					currentCommit = parts[2]
-					if _, ok := branchReports[branchName].Contributions[currentEmail]; !ok {
-						branchReports[branchName].Contributions[currentEmail] = &UserContribution{
-							Email:                currentEmail,
-							ContributionTimeline: make(map[string]int),
-							FileFilter:           fileFilter,
-						}
-					}
-					branchReports[branchName].Contributions[currentEmail].CommitCount++
-
-					dateParsed, err := time.Parse("2006-01-02", currentDate)
-					if err == nil {
-						if defaultGroupByForLogDate == "month" {
-							//yearMonth := fmt.Sprintf("%d-%s", dateParsed.Year(), dateParsed.Month().String())
-							//branchReports[branchName].Contributions[currentEmail].ContributionTimeline[yearMonth]++
-							yearMonth := fmt.Sprintf("%d-%s", dateParsed.Year(), dateParsed.Month().String()[:3])
-							branchReports[branchName].Contributions[currentEmail].ContributionTimeline[strings.ToUpper(yearMonth)]++
-						} else {
-							_, week := dateParsed.ISOWeek()
-							yearWeek := fmt.Sprintf("%d-%02d", dateParsed.Year(), week)
-							branchReports[branchName].Contributions[currentEmail].ContributionTimeline[yearWeek]++
-						}
-					}
-				}
-			} else if strings.Contains(line, "\t") && currentCommit != "" {
-				parts := strings.Split(line, "\t")
-				if len(parts) == 3 && parts[0] != "-" && parts[1] != "-" {
-					added, _ := strconv.Atoi(parts[0])
-					removed, _ := strconv.Atoi(parts[1])
-					branchReports[branchName].Contributions[currentEmail].LinesAdded += added
-					branchReports[branchName].Contributions[currentEmail].LinesRemoved += removed
-					branchReports[branchName].Contributions[currentEmail].LinesEdited += added + removed
-				}
+	if serveMode {
+		var served []gogitstats.WatchedRepository
+		for i, localPath := range repositories {
+			repository := gogitstats.WatchedRepository{Path: localPath, Name: repoNames[i]}
+			if i < len(repoURLs) {
+				repository.URL = repoURLs[i]
 			}
+			served = append(served, repository)
 		}
-	}
-
-	// Remove empty branch reports
-	for branchName, report := range branchReports {
-		if len(report.Contributions) == 0 {
-			delete(branchReports, branchName)
+		err := gogitstats.Serve(analyzer, served, gogitstats.ServeOptions{Address: liveAddress, Interval: *optionRefreshInterval, Auth: auth})
+		if err != nil {
+			log.Fatalf("Error serving reports: %v", err)
 		}
+		return
 	}
 
-	return branchReports, nil
-}
+	if len(repositories) > 1 {
+		if *optionConfluenceURL != "" {
+			log.Fatal("Option `--confluence-url` is only supported for a single repository")
+		}
+		if *optionOutput != "" && !gogitstats.IsOutputDirectory(*optionOutput) {
+			log.Fatal("Option `--output` must name a directory (e.g., reports/) if several repositories are analyzed")
+		}
 
-func generateHTMLReportByBranch(branchReports map[string]*BranchReport, repoName string, fileFilter string) (string, error) {
-	tmpl := `
-<!DOCTYPE html>
-<html lang="en" data-bs-theme="dark">
-<head>
-<meta charset="UTF-8">
-<meta name="viewport" content="width=device-width, initial-scale=1.0">
-<title>Git Contribution Report: {{.RepoName}}</title>
-<link href="https://cdn.jsdelivr.net/npm/bootstrap@5.3.2/dist/css/bootstrap.min.css" rel="stylesheet">
-<script src="https://cdn.jsdelivr.net/npm/bootstrap@5.3.2/dist/js/bootstrap.bundle.min.js"></script>
-<style>
-	.fixed-width {
-		width: 150px;
-	}
-</style>
-</head>
-<body>
-
-<div class="container mt-4">
-
-<h4> Repository name: <span class="badge text-bg-success">{{.RepoName}}</span></h4>
-<h4> Applied file filter: <span class="badge text-bg-info">{{.FileFilter}}</span></h4>
-
-<div class="d-flex justify-content-end mb-3">
-	<button id="themeToggle" class="btn btn-outline-light">Light Theme</button>
-</div>
-
-{{range $branchName, $branchReport := .BranchReports}}
-<h4> Branch: <span class="badge text-bg-warning">{{$branchName}}</span></h4>
-
-<table class="table table-dark table-striped">
-	<thead>
-		<tr>
-			<th class="fixed-width">Email</th>
-			<th class="fixed-width">Commit Count</th>
-			<th class="fixed-width">Contribution Timeline</th>
-			<th>Lines Added</th>
-			<th>Lines Removed</th>
-			<th>Lines Edited</th>
-			<th>File Filter</th>
-		</tr>
-	</thead>
-	<tbody>
-		{{range sortContributions .Contributions}}
-		<tr>
-			<td>{{.Email}}</td>
-			<td>{{.CommitCount}}</td>
-			<td>
-				{{range $yearWeek, $count := .ContributionTimeline}}
-					{{$yearWeek}}: {{$count}}<br>
-				{{end}}
-			</td>
-			<td>{{.LinesAdded}}</td>
-			<td>{{.LinesRemoved}}</td>
-			<td>{{.LinesEdited}}</td>
-			<td>{{.FileFilter}}</td>
-		</tr>
-		{{end}}
-	</tbody>
-</table>
-{{end}}
-</div>
-
-<script>
-const themeToggle = document.getElementById('themeToggle');
-let currentTheme = 'dark';
-
-themeToggle.addEventListener('click', () => {
-	if (currentTheme === 'dark') {
-		document.documentElement.setAttribute('data-bs-theme', 'light');
-		document.querySelectorAll('table').forEach(table => {
-			table.classList.remove('table-dark');
-		});
-		themeToggle.textContent = 'Dark Theme';
-		currentTheme = 'light';
-	} else {
-		document.documentElement.setAttribute('data-bs-theme', 'dark');
-		document.querySelectorAll('table').forEach(table => {
-			table.classList.add('table-dark');
-		});
-		themeToggle.textContent = 'Light Theme';
-		currentTheme = 'dark';
-	}
-});
-
-</script>
-</body>
-</html>
-`
-	t, err := template.New("report").Funcs(template.FuncMap{
-		"sortContributions": func(contributions map[string]*UserContribution) []*UserContribution {
-			sorted := make([]*UserContribution, 0, len(contributions))
-			for _, c := range contributions {
-				sorted = append(sorted, c)
+		var reports []*gogitstats.Report
+		for i, localPath := range repositories {
+			log.Printf("Analyzing repository: %s", repoNames[i])
+			report, err := analyzer.WriteReport(localPath, repoNames[i])
+			if err != nil {
+				log.Printf("Error analyzing repository '%s': %v", repoNames[i], err)
+				continue
 			}
-			sort.Slice(sorted, func(i, j int) bool {
-				return sorted[i].LinesAdded > sorted[j].LinesAdded // Sort by LinesAdded descending
-			})
-			return sorted
-		},
-	}).Parse(tmpl)
-
-	if err != nil {
-		return "", err
+			reports = append(reports, report)
+		}
+		enforceCacheSize()
+		if len(reports) > 1 {
+			if _, err := analyzer.Combine(reports).Write(); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		}
+		finishSnapshot(analyzer, *optionSnapshots)
+		if failed := len(repositories) - len(reports); failed > 0 {
+			log.Fatalf("Analysis of %d of %d repositories failed", failed, len(repositories))
+		}
+		return
 	}
 
-	data := ReportData{
-		RepoName:      repoName,
-		FileFilter:    fileFilter,
-		BranchReports: branchReports,
-	}
+	log.Printf("Analyzing repository: %s", repoNames[0])
 
-	var buf bytes.Buffer
-	err = t.Execute(&buf, data)
+	report, err := analyzer.WriteReport(repositories[0], repoNames[0])
 	if err != nil {
-		return "", err
+		log.Fatalf("Error: %v", err)
 	}
+	enforceCacheSize()
+	finishSnapshot(analyzer, *optionSnapshots)
 
-	return buf.String(), nil
+	if *optionConfluenceURL != "" {
+		storageReport, err := report.ConfluenceStorage()
+		if err != nil {
+			log.Fatalf("Error generating Confluence report: %v", err)
+		}
+
+		if err := gogitstats.PublishToConfluence(*optionConfluenceURL, *optionConfluencePage, storageReport); err != nil {
+			log.Fatalf("Error publishing report to Confluence: %v", err)
+		}
+	}
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+
+	"github.com/vdmitriyev/gogitstats/pkg/gogitstats"
+)
+
+// dataList collects the exported reports given with repeated or comma-separated `--data` options.
+type dataList []string
+
+func (list *dataList) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *dataList) Set(value string) error {
+	for _, dataPath := range strings.Split(value, ",") {
+		if dataPath = strings.TrimSpace(dataPath); dataPath != "" {
+			*list = append(*list, dataPath)
+		}
+	}
+	return nil
+}
+
+// runMerge implements the `merge` command, which combines reports exported with `--format json` (e.g., on
+// different CI runners for different repositories) into a combined report.
+func runMerge(args []string) {
+	defaults := gogitstats.DefaultOptions()
+
+	flags := flag.NewFlagSet("merge", flag.ExitOnError)
+	var dataPaths dataList
+	flags.Var(&dataPaths, "data", "Path to a report exported with `--format json` (of a repository or combined). Can be repeated or given as comma-separated list, further paths can follow the options")
+	optionFormat := flags.String("format", defaults.Format, "Format of the combined report: "+strings.Join(gogitstats.REPORT_FORMATS, ", ")+" ('json' writes the merged dataset)")
+	optionOutput, optionOverwrite := outputOptions(flags)
+	optionOffline := flags.Bool("offline", false, "Inline Bootstrap into HTML reports instead of linking it from the CDN, so they work without internet access (e.g., on air-gapped networks)")
+	optionTheme := flags.String("theme", defaults.Theme, "Default theme of the HTML report: 'dark' or 'light'")
+	optionLanguage := flags.String("lang", defaults.Language, "Language of the report: "+strings.Join(gogitstats.ReportLanguages(), ", "))
+	optionColumns := flags.String("columns", strings.Join(gogitstats.REPORT_COLUMNS, ","), "Comma-separated list of columns shown in the report")
+	flags.Parse(args)
+	useOutput(*optionOutput)
+	dataPaths = append(dataPaths, flags.Args()...)
+
+	if len(dataPaths) < 2 {
+		log.Fatal("Please provide at least two reports exported with `--format json` with option `--data`")
+	}
+
+	analyzer, err := gogitstats.NewAnalyzer(gogitstats.Options{
+		Columns:   strings.Split(*optionColumns, ","),
+		Format:    *optionFormat,
+		Theme:     *optionTheme,
+		Offline:   *optionOffline,
+		Output:    *optionOutput,
+		Overwrite: *optionOverwrite,
+		Language:  *optionLanguage,
+	})
+	if err != nil {
+		log.Fatalf("Given options are not supported: %v", err)
+	}
+
+	combined, err := analyzer.Merge(dataPaths)
+	if err != nil {
+		log.Fatalf("Error merging reports: %v", err)
+	}
+	log.Printf("Merged %d reports", len(dataPaths))
+
+	if _, err := combined.Write(); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/vdmitriyev/gogitstats/pkg/gogitstats"
+)
+
+const mirrorUsage = "Usage: gogitstats mirror add|fetch-all|prune [options] [repository URLs]"
+
+// runMirror implements the `mirror` commands, which maintain a directory of `git clone --mirror` clones (a mirror
+// farm) analyzed by scheduled org-wide runs with `--discover`:
+//   - add: mirrors the given repositories
+//   - fetch-all: updates all mirrors
+//   - prune: removes the mirrors of repositories which are not listed anymore
+func runMirror(args []string) {
+	if len(args) == 0 {
+		log.Fatal(mirrorUsage)
+	}
+	command := args[0]
+	if command != "add" && command != "fetch-all" && command != "prune" {
+		log.Fatalf("Unknown mirror command '%s'. %s", command, mirrorUsage)
+	}
+
+	flags := flag.NewFlagSet("mirror "+command, flag.ExitOnError)
+	optionDir := flags.String("dir", gogitstats.MIRRORS_DIRECTORY, "Directory of the mirrors")
+	var repoURLs repositoryList
+	optionRepositoriesFile := ""
+	optionDryRun := false
+	if command == "add" || command == "prune" {
+		flags.Var(&repoURLs, "repository", "URL of a mirrored repository. Can be repeated or given as comma-separated list, further URLs can follow the options")
+		flags.StringVar(&optionRepositoriesFile, "repositories-file", "", "Path to a file listing the URLs of mirrored repositories, one per line. Optional")
+	}
+	if command == "prune" {
+		flags.BoolVar(&optionDryRun, "dry-run", false, "Only list the mirrors which would be removed")
+	}
+	flags.Parse(args[1:])
+
+	repoURLs = append(repoURLs, flags.Args()...)
+	if optionRepositoriesFile != "" {
+		listed, err := readRepositoryList(optionRepositoriesFile)
+		if err != nil {
+			log.Fatalf("Error reading repositories file: %v", err)
+		}
+		repoURLs = append(repoURLs, listed...)
+	}
+
+	switch command {
+	case "add":
+		if len(repoURLs) == 0 {
+			log.Fatal("Please provide the URLs of the repositories to mirror with option `--repository`")
+		}
+		failed := 0
+		for _, repoURL := range repoURLs {
+			if !gogitstats.IsRepositoryURL(repoURL) {
+				log.Printf("Error: %s is not a repository URL", repoURL)
+				failed++
+				continue
+			}
+			if _, err := gogitstats.AddMirror(*optionDir, repoURL); err != nil {
+				log.Printf("Error: %v", err)
+				failed++
+			}
+		}
+		if failed > 0 {
+			log.Fatalf("Failed to mirror %d of %d repositories", failed, len(repoURLs))
+		}
+
+	case "fetch-all":
+		updated, err := gogitstats.FetchMirrors(*optionDir)
+		log.Printf("Updated %d mirrors in: %s", updated, *optionDir)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+
+	case "prune":
+		// an empty list would remove the whole mirror farm
+		if len(repoURLs) == 0 {
+			log.Fatal("Please provide the URLs of the repositories to keep with option `--repository` or `--repositories-file`")
+		}
+		pruned, err := gogitstats.PruneMirrors(*optionDir, repoURLs, optionDryRun)
+		for _, mirror := range pruned {
+			if optionDryRun {
+				fmt.Fprintf(os.Stdout, "%s\t%s\n", mirror.Path, mirror.URL)
+			} else {
+				log.Printf("Removed mirror of %s: %s", mirror.URL, mirror.Path)
+			}
+		}
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if optionDryRun {
+			log.Printf("%d mirrors would be pruned in: %s", len(pruned), *optionDir)
+		} else {
+			log.Printf("Pruned %d mirrors in: %s", len(pruned), *optionDir)
+		}
+	}
+}
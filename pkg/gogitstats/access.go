@@ -0,0 +1,118 @@
+package gogitstats
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ACCESS_EVERYONE is the principal matching every logged in user.
+const ACCESS_EVERYONE = "*"
+
+// ACCESS_GROUP_PREFIX marks principals matching the groups of the OIDC login (claim "groups").
+const ACCESS_GROUP_PREFIX = "group:"
+
+// AccessConfig restricts the reports served by Watch to the users logged in by OIDC.
+//
+// A principal is an email (e.g., jane@example.com), an email domain (e.g., @example.com), a group
+// of the OIDC login (e.g., group:platform) or ACCESS_EVERYONE.
+type AccessConfig struct {
+	// Admins read the reports of all repositories without the viewer profile
+	Admins []string `yaml:"admins" json:"admins"`
+	// ViewerProfile names the output profile (see Config.Profiles) applied to the reports served to viewers, optional
+	ViewerProfile string `yaml:"viewerProfile" json:"viewerProfile"`
+	// Repositories maps repository names to the principals reading their reports (viewers), unlisted repositories are served to admins only
+	Repositories map[string][]string `yaml:"repositories" json:"repositories"`
+}
+
+// validate checks the principals of the access rules and the viewer profile.
+func (access *AccessConfig) validate(config *Config) error {
+	principals := append([]string{}, access.Admins...)
+	for repoName, viewers := range access.Repositories {
+		if repoName == "" {
+			return fmt.Errorf("invalid access rule: the repository name must be set")
+		}
+		principals = append(principals, viewers...)
+	}
+	for _, principal := range principals {
+		if err := validatePrincipal(principal); err != nil {
+			return err
+		}
+	}
+
+	if access.ViewerProfile != "" {
+		if _, err := selectOutputProfile(config, access.ViewerProfile); err != nil {
+			return fmt.Errorf("invalid viewer profile of the access rules: %w", err)
+		}
+	}
+	return nil
+}
+
+// validatePrincipal checks, whether the principal is an email, an email domain, a group or ACCESS_EVERYONE.
+func validatePrincipal(principal string) error {
+	switch {
+	case principal == ACCESS_EVERYONE:
+		return nil
+	case strings.HasPrefix(principal, ACCESS_GROUP_PREFIX):
+		if strings.TrimPrefix(principal, ACCESS_GROUP_PREFIX) != "" {
+			return nil
+		}
+	case strings.HasPrefix(principal, "@"):
+		if len(principal) > 1 && !strings.Contains(principal[1:], "@") {
+			return nil
+		}
+	default:
+		if local, domain, found := strings.Cut(principal, "@"); found && local != "" && domain != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid principal '%s' in the access rules: expected an email, an @domain, a %s<name> or '%s'", principal, ACCESS_GROUP_PREFIX, ACCESS_EVERYONE)
+}
+
+// usesGroups reports, whether any of the access rules matches groups, which need to be requested at login.
+func (access *AccessConfig) usesGroups() bool {
+	principals := append([]string{}, access.Admins...)
+	for _, viewers := range access.Repositories {
+		principals = append(principals, viewers...)
+	}
+	return slices.ContainsFunc(principals, func(principal string) bool {
+		return strings.HasPrefix(principal, ACCESS_GROUP_PREFIX)
+	})
+}
+
+// isAdmin reports, whether the user reads all reports without the viewer profile.
+func (access *AccessConfig) isAdmin(user *sessionUser) bool {
+	return matchesPrincipals(user, access.Admins)
+}
+
+// canRead reports, whether the user reads the report of the repository.
+func (access *AccessConfig) canRead(user *sessionUser, repoName string) bool {
+	return access.isAdmin(user) || matchesPrincipals(user, access.Repositories[repoName])
+}
+
+// matchesPrincipals reports, whether any of the principals matches the user.
+func matchesPrincipals(user *sessionUser, principals []string) bool {
+	if user == nil {
+		return false
+	}
+	email := strings.ToLower(user.Email)
+	for _, principal := range principals {
+		switch {
+		case principal == ACCESS_EVERYONE:
+			return true
+		case strings.HasPrefix(principal, ACCESS_GROUP_PREFIX):
+			if slices.Contains(user.Groups, strings.TrimPrefix(principal, ACCESS_GROUP_PREFIX)) {
+				return true
+			}
+		case strings.HasPrefix(principal, "@"):
+			if strings.HasSuffix(email, strings.ToLower(principal)) {
+				return true
+			}
+		default:
+			if email == strings.ToLower(principal) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,964 @@
+// Package gogitstats analyzes the contributions to git repositories by branch and generates reports
+// (HTML, JSON, CSV), it implements the gogitstats CLI.
+package gogitstats
+
+import (
+	"crypto"
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Options configure the analysis of repositories and the generated reports, see DefaultOptions. Settings
+// left empty (or zero, where zero is not a valid setting) select their defaults.
+type Options struct {
+	// MainBranch, FileFilter and ReleaseBranches are taken from the configuration (see Config) if not set,
+	// FileFilter is a comma-separated list of file types and pathspecs (see fileFilterPathspecs)
+	MainBranch      string
+	FileFilter      string
+	ReleaseBranches string
+	// Branches limits the analysis to branches matching any of the glob patterns (e.g., release/*) next to the
+	// main branch, all branches are analyzed if empty
+	Branches []string
+	// Subdir limits the analysis to a subdirectory of the repository, FileFilter applies within it, repositories
+	// given by URL are cloned limited to it (see CloneOptions)
+	Subdir string
+	// Authors limits the contributions to authors whose email matches any of the globs (e.g., *@example.com) or
+	// regular expressions enclosed in slashes (e.g., /^jane@/), all authors are reported if empty
+	Authors []string
+	// ExcludeAuthors excludes the contributions of authors whose email matches any of the patterns (same syntax
+	// as Authors), exclusions take precedence over Authors
+	ExcludeAuthors []string
+	// ExcludeBots excludes the contributions of common automation accounts, see BOT_AUTHOR_PATTERNS
+	ExcludeBots bool
+	// MainBranchOnly limits the analysis to the main branch, Branches are ignored
+	MainBranchOnly bool
+	// RemoteBranches adds the remote-tracking branches of TRACKED_REMOTE without a local branch to the analyzed
+	// branches, which are read without checking them out. Clones of repositories given by URL are always analyzed so
+	RemoteBranches bool
+	// Preset fills the settings left empty with the values of a preset: quick, standard or deep, see ANALYSIS_PRESETS
+	Preset string
+	// Since and Until limit the analysis to a period, given as dates (e.g., 2024-01-01) or relative to now (e.g., 6.months)
+	Since string
+	Until string
+	// RevRange limits the analysis to the commits of a revision range (e.g., v1.2.0..v1.3.0 for the work which went
+	// into a release), analyzed in place of the main branch, other branches are not analyzed
+	RevRange string
+	// NoMerges excludes merge commits from the contributions
+	NoMerges bool
+	// UniqueCommits limits each branch other than the main branch to the commits no other analyzed branch
+	// contains, so a commit is counted in one branch table at most
+	UniqueCommits bool
+	// GroupBy groups the contribution timelines by "week" or "month"
+	GroupBy   string
+	BatchSize int
+	// Workers is the number of branches analyzed concurrently, the default (the number of CPUs, see DefaultOptions)
+	// if 0. At least one worker runs, which analyzes the branches one after another
+	Workers int
+	// UseCache reuses results of unchanged branches from previous analyses
+	UseCache bool
+	// Resume saves the results of the analyzed branches while the analysis runs, so an interrupted run resumes where
+	// it left off: analyzed branches and repositories are taken from the cache, implies UseCache
+	Resume bool
+	// MaxMemory is the soft memory limit of the analysis in bytes: parsed commits are kept in a commit store holding a
+	// quarter of it in memory (instead of defaultCommitStoreMemoryBudget), further commits are spilled to the store
+	// file of the cache or, without UseCache, to a temporary one. No commit store without the cache if 0. The soft
+	// memory limit of the process (debug.SetMemoryLimit) is left to the caller, e.g. the CLI sets it to MaxMemory
+	MaxMemory int64
+	// MaxCacheSize limits the size of the clones and caches below REPOSITORIES_DIRECTORY in bytes, the least
+	// recently used are removed by EnforceCacheSize, unlimited if 0
+	MaxCacheSize int64
+	// Strict fails the analysis on conditions otherwise logged and ignored: a failed merge-base or git log of a
+	// branch and unparsable lines of git log output
+	Strict bool
+	// Config is merged with the configuration shipped in each analyzed repository, its settings take precedence
+	Config *Config
+	// Mailmap is the path of a mailmap file merging the emails of authors, the .mailmap of each repository is used if empty
+	Mailmap string
+	// Teams is the path of a YAML (or JSON) file mapping emails to team names, which adds the contributions by
+	// team to the reports, optional
+	Teams string
+	// Profile names the output profile of Config applied to the reports (e.g., external), optional
+	Profile string
+	// Sections selects the report sections (all or as configured if empty), see REPORT_SECTIONS
+	Sections []string
+	// Columns selects the columns of the contribution tables, see REPORT_COLUMNS
+	Columns []string
+	// SortBy orders the contribution tables, see CONTRIBUTION_SORT_KEYS
+	SortBy string
+	// Top limits the rows of the contribution tables, further contributors are summed up in an others row, all
+	// contributors are listed if 0
+	Top      int
+	Format   string
+	Theme    string
+	Language string
+
+	RiskMaxCommits       int
+	RiskMaxDays          int
+	DeployMarker         string
+	BackportPattern      string
+	ForecastPeriods      int
+	FocusDepth           int
+	ContentionWindow     int // days
+	ContentionMinAuthors int
+	PairingWindow        time.Duration
+	// PathBreakdown breaks the contributions down by "directory" or "file", disabled if empty
+	PathBreakdown string
+	// PathDepth is the number of leading directories forming a directory of the path breakdown
+	PathDepth int
+	// Imports selects how commits detected as initial import or vendoring are counted: IMPORTS_KEEP (reported
+	// only), IMPORTS_EXCLUDE or IMPORTS_SEPARATE (attributed to IMPORTS_AUTHOR)
+	Imports string
+	// ImportMinFiles is the number of files from which a commit adding (almost) only lines is an import
+	ImportMinFiles int
+	// LocalActivity reports the work recorded in the reflog of each repository (e.g., amends, rebases, abandoned branches)
+	LocalActivity bool
+	// Leaderboard adds a gamified section (monthly leaderboard, streak badges, first contributions) to the reports
+	Leaderboard bool
+
+	// GitHubRepo (owner/name) or GitLabProject (group/project) enable the review latency, tokens are taken from the environment
+	GitHubRepo    string
+	GitHubAPIURL  string
+	GitLabProject string
+	GitLabURL     string
+	// JiraURL enables grouping work by initiative
+	JiraURL       string
+	JiraEpicField string
+	// ReconcileSquash credits the squash commits of changes merged into the main branch to the authors (and
+	// co-authors) of the squashed commits as listed by the hosting platform, instead of the committer who merged them
+	ReconcileSquash bool
+	// APIDump is a directory of exported API responses (see DUMP_GITHUB_PULLS) read instead of the live APIs, optional
+	APIDump string
+
+	// Calendars writes the active days of each contributor as iCalendar files next to each report
+	Calendars bool
+	// Insights writes a one-page contribution insights document per author next to each report
+	Insights bool
+	// Output is the file (OUTPUT_STDOUT for the standard output) or the directory (ending with a separator or existing)
+	// the reports are written to, timestamped files in the current directory if empty
+	Output string
+	// Overwrite names the reports without timestamp (e.g., report_api.html) and replaces existing files
+	Overwrite bool
+	// Snapshots is a directory of dated snapshots: the reports of a run are written into the subdirectory of the day
+	// (e.g., 2024-05-31), which replaces an earlier snapshot of the day, see Analyzer.FinishSnapshot. Output and
+	// Overwrite are ignored
+	Snapshots string
+	// SnapshotKeepLast is the number of latest snapshots kept, SnapshotKeepMonthly the number of months whose last
+	// snapshot is kept in addition, older snapshots are removed. The latest snapshot is always kept, so 0 latest
+	// snapshots keeps the monthly ones only (see DefaultOptions for the defaults)
+	SnapshotKeepLast    int
+	SnapshotKeepMonthly int
+	// Offline inlines Bootstrap into the HTML reports instead of linking it from the CDN, e.g., for air-gapped networks
+	Offline bool
+
+	// AttestationSigner signs an in-toto attestation written next to each report, optional
+	AttestationSigner crypto.Signer
+}
+
+// Analyzer analyzes git repositories and generates reports with a fixed set of options.
+type Analyzer struct {
+	options         Options
+	snapshot        string
+	config          *Config
+	sections        []string
+	columns         []string
+	messages        map[string]string
+	profile         *OutputProfile
+	mailmap         map[string]string
+	teams           map[string]string
+	backportPattern *regexp.Regexp
+	authors         []authorPattern
+	excludedAuthors []authorPattern
+	hosting         hostingClient
+	jira            *jiraClient
+}
+
+// Report is the analysis of a repository, which can be rendered in all formats (see REPORT_FORMATS).
+type Report struct {
+	RepoName   string
+	RepoPath   string
+	MainBranch string
+	FileFilter string
+	// Branches holds the contributions by branch, repository-level results are attached to the main branch
+	Branches  map[string]*BranchReport
+	StartedOn time.Time
+
+	analyzer *Analyzer
+	run      *analysisRun
+	heads    []attestedBranch
+	// data holds the stored results of a report loaded with Analyzer.LoadReport, nil for analyzed reports
+	data *ReportData
+}
+
+// analysisRun holds the settings of an analysis and of the rendering of its report: the options of the analyzer
+// and what the analysis resolved from the repository (e.g., its configuration, main branch and period). Each
+// analysis and each loaded report has its own, so analyzers can run concurrently.
+type analysisRun struct {
+	// config is the configuration of the analyzer with the defaults of the repository, see Config.withDefaults
+	config     *Config
+	mainBranch string
+	// fileFilter is the pathspec of the analysis, see fileFilterPathspecs
+	fileFilter string
+	groupBy    string
+	batchSize  int
+	workers    int
+	useCache   bool
+	// resume saves the analyzed branches while the analysis runs, see Options.Resume
+	resume bool
+	// branchPatterns limits the analyzed branches, all branches are analyzed if empty, see branchSelected
+	branchPatterns []string
+	// mainBranchOnly limits the analysis to the main branch, branchPatterns are ignored
+	mainBranchOnly bool
+	// remoteBranches adds the remote-tracking branches of TRACKED_REMOTE without a local branch to the analyzed
+	// branches, see Options.RemoteBranches. Clones of repositories given by URL are always analyzed this way.
+	remoteBranches bool
+	// branchRevisions maps the branches analyzed by their remote-tracking refs to these refs (e.g., "feature/x" to
+	// "refs/remotes/origin/feature/x"), see listBranches and branchRevision
+	branchRevisions map[string]string
+	// authorPatterns limits the contributions to the authors matching any of the patterns, all authors are
+	// reported if empty, excludedAuthorPatterns excludes the authors matching any of its patterns (e.g., bots)
+	authorPatterns         []authorPattern
+	excludedAuthorPatterns []authorPattern
+	// since and until are the limits of the period as given, gitDateRange holds the `git log` arguments limiting
+	// the analysis to the period, see resolveDateRange
+	since        string
+	until        string
+	gitDateRange []string
+	// revRange is the revision range analyzed in place of the main branch (e.g., v1.2.0..v1.3.0), see
+	// Options.RevRange, and gitRevRange holds its resolved revisions, see resolveRevRange
+	revRange    string
+	gitRevRange []string
+	// excludeMerges excludes merge commits from the contributions, see gitLogArgs
+	excludeMerges bool
+	// strict fails the analysis on conditions otherwise logged and ignored, see Options.Strict
+	strict bool
+	// uniqueCommits limits each branch to the commits no other analyzed branch contains, see Options.UniqueCommits
+	uniqueCommits bool
+	// storeMemoryBudget bounds the commits the commit store holds in memory, see Options.MaxMemory
+	storeMemoryBudget int64
+	// spillCommits keeps the parsed commits in a temporary commit store if the cache is not used, see Options.MaxMemory
+	spillCommits bool
+	theme        string
+	columns      []string
+	sortBy       string
+	// top limits the rows of the contribution tables, further contributors are summed up in an others row, all
+	// are listed if 0
+	top      int
+	sections []string
+	// offline inlines Bootstrap into the HTML reports instead of linking it from the CDN, so the reports work
+	// without internet access
+	offline  bool
+	language string
+	// messages is the message catalog of the report language
+	messages map[string]string
+	// profile is the profile applied to the generated reports, the zero value reveals everything
+	profile *OutputProfile
+	// teams maps lower case emails to team names, the team roll-up is reported if set, see Options.Teams
+	teams map[string]string
+	// attestationSigner signs attestations of generated reports, no attestations are written if nil
+	attestationSigner crypto.Signer
+	riskMaxCommits    int
+	riskMaxDays       int
+	deployMarker      string
+	forecastPeriods   int
+	// focusDepth is the number of leading directories identifying the area a file belongs to
+	focusDepth int
+	// pathBreakdown enables the breakdown of contributions by directory or file, disabled if empty, pathDepth is
+	// the number of leading directories forming a directory of the breakdown
+	pathBreakdown        string
+	pathDepth            int
+	contentionWindow     int // days
+	contentionMinAuthors int
+	pairingWindow        time.Duration
+	// importsMode selects how detected initial imports are counted, importMinFiles is the number of files from
+	// which a commit adding (almost) only lines is an import
+	importsMode    string
+	importMinFiles int
+	// localActivity enables reading the reflog, see assessLocalActivity
+	localActivity bool
+	// leaderboard enables the gamified leaderboard section, see assessLeaderboard
+	leaderboard bool
+	// squashedAuthors maps squash commits to the authors of the commits squashed into them, see reconcileSquashMerges
+	squashedAuthors map[string][]squashAuthor
+}
+
+// DefaultOptions returns the options used by the CLI if none are given.
+func DefaultOptions() Options {
+	return Options{
+		GroupBy:              "month",
+		BatchSize:            1000,
+		Workers:              runtime.NumCPU(),
+		Columns:              REPORT_COLUMNS,
+		SortBy:               "lines-added",
+		Format:               REPORT_FORMAT_HTML,
+		Theme:                "dark",
+		Language:             "en",
+		RiskMaxCommits:       50,
+		RiskMaxDays:          30,
+		DeployMarker:         DEPLOY_MARKER_TAGS,
+		BackportPattern:      `(?i)\bfix`,
+		FocusDepth:           2,
+		PathDepth:            1,
+		ContentionWindow:     14,
+		ContentionMinAuthors: 3,
+		PairingWindow:        2 * time.Hour,
+		Imports:              IMPORTS_KEEP,
+		ImportMinFiles:       500,
+		GitHubAPIURL:         "https://api.github.com",
+		GitLabURL:            "https://gitlab.com",
+		SnapshotKeepLast:     7,
+		SnapshotKeepMonthly:  12,
+	}
+}
+
+// NewAnalyzer validates the options and creates an analyzer.
+//
+// Returns:
+//   - The analyzer.
+//   - An error if any of the options is not supported.
+func NewAnalyzer(options Options) (*Analyzer, error) {
+	if err := options.applyPreset(); err != nil {
+		return nil, err
+	}
+
+	defaults := DefaultOptions()
+	if options.GroupBy == "" {
+		options.GroupBy = defaults.GroupBy
+	}
+	if options.Format == "" {
+		options.Format = defaults.Format
+	}
+	if options.Theme == "" {
+		options.Theme = defaults.Theme
+	}
+	if options.Language == "" {
+		options.Language = defaults.Language
+	}
+	if options.SortBy == "" {
+		options.SortBy = defaults.SortBy
+	}
+	if options.DeployMarker == "" {
+		options.DeployMarker = defaults.DeployMarker
+	}
+	if options.BackportPattern == "" {
+		options.BackportPattern = defaults.BackportPattern
+	}
+	if options.GitHubAPIURL == "" {
+		options.GitHubAPIURL = defaults.GitHubAPIURL
+	}
+	if options.GitLabURL == "" {
+		options.GitLabURL = defaults.GitLabURL
+	}
+	if options.Imports == "" {
+		options.Imports = defaults.Imports
+	}
+	if options.ImportMinFiles == 0 {
+		options.ImportMinFiles = defaults.ImportMinFiles
+	}
+	if options.Columns == nil {
+		options.Columns = defaults.Columns
+	}
+	if options.BatchSize == 0 {
+		options.BatchSize = defaults.BatchSize
+	}
+	if options.Workers == 0 {
+		options.Workers = defaults.Workers
+	}
+	if options.FocusDepth == 0 {
+		options.FocusDepth = defaults.FocusDepth
+	}
+	if options.PathDepth == 0 {
+		options.PathDepth = defaults.PathDepth
+	}
+	if options.ContentionWindow == 0 {
+		options.ContentionWindow = defaults.ContentionWindow
+	}
+	if options.ContentionMinAuthors == 0 {
+		options.ContentionMinAuthors = defaults.ContentionMinAuthors
+	}
+	if options.PairingWindow == 0 {
+		options.PairingWindow = defaults.PairingWindow
+	}
+
+	if options.Resume {
+		options.UseCache = true
+	}
+	snapshot := ""
+	if options.Snapshots != "" {
+		if options.SnapshotKeepLast < 0 || options.SnapshotKeepMonthly < 0 {
+			return nil, fmt.Errorf("numbers of kept snapshots must not be negative")
+		}
+		// all reports of the run are written into a staging directory, which replaces the snapshot of the day the
+		// run started when it is finished, so no files of an earlier run of the day are left
+		snapshot = snapshotDirectory(options.Snapshots, time.Now())
+		options.Output = snapshotStagingDirectory(snapshot) + string(filepath.Separator)
+		options.Overwrite = true
+	}
+	analyzer := &Analyzer{options: options, config: options.Config, snapshot: snapshot}
+	if analyzer.config == nil {
+		analyzer.config = &Config{}
+	}
+
+	if options.GroupBy != "week" && options.GroupBy != "month" {
+		return nil, fmt.Errorf("grouping '%s' is not supported, expected 'week' or 'month'", options.GroupBy)
+	}
+	if !slices.Contains(REPORT_FORMATS, options.Format) {
+		return nil, fmt.Errorf("format '%s' is not supported, expected any of: %s", options.Format, strings.Join(REPORT_FORMATS, ", "))
+	}
+	if !slices.Contains(CONTRIBUTION_SORT_KEYS, options.SortBy) {
+		return nil, fmt.Errorf("sort key '%s' is not supported, expected any of: %s", options.SortBy, strings.Join(CONTRIBUTION_SORT_KEYS, ", "))
+	}
+	if options.MaxMemory < 0 || options.MaxCacheSize < 0 {
+		return nil, fmt.Errorf("memory and cache limits must not be negative")
+	}
+	if options.Top < 0 {
+		return nil, fmt.Errorf("number of top contributors must not be negative, given: %d", options.Top)
+	}
+	if options.Theme != "dark" && options.Theme != "light" {
+		return nil, fmt.Errorf("theme '%s' is not supported, expected 'dark' or 'light'", options.Theme)
+	}
+	if options.DeployMarker != DEPLOY_MARKER_TAGS && options.DeployMarker != DEPLOY_MARKER_MERGES {
+		return nil, fmt.Errorf("deploy marker '%s' is not supported, expected '%s' or '%s'", options.DeployMarker, DEPLOY_MARKER_TAGS, DEPLOY_MARKER_MERGES)
+	}
+	for _, limit := range []string{options.Since, options.Until} {
+		if limit != "" && !dateLimitPattern.MatchString(limit) {
+			return nil, fmt.Errorf("date '%s' is not supported, expected a date (e.g., 2024-01-01) or a relative value (e.g., 6.months)", limit)
+		}
+	}
+	for _, revision := range strings.Fields(options.RevRange) {
+		if strings.HasPrefix(revision, "-") {
+			return nil, fmt.Errorf("revision range '%s' is not supported, expected revisions (e.g., v1.2.0..v1.3.0)", options.RevRange)
+		}
+	}
+	if options.BatchSize <= 0 {
+		return nil, fmt.Errorf("batch size must be a positive number, given: %d", options.BatchSize)
+	}
+	if options.Workers < 0 {
+		return nil, fmt.Errorf("number of workers must not be negative, given: %d", options.Workers)
+	}
+	if options.RiskMaxCommits < 0 || options.RiskMaxDays < 0 {
+		return nil, fmt.Errorf("risk thresholds must not be negative, given: %d commits, %d days", options.RiskMaxCommits, options.RiskMaxDays)
+	}
+	if options.ForecastPeriods < 0 {
+		return nil, fmt.Errorf("forecast periods must not be negative, given: %d", options.ForecastPeriods)
+	}
+	if options.FocusDepth < 1 {
+		return nil, fmt.Errorf("focus depth must be at least 1, given: %d", options.FocusDepth)
+	}
+	if options.PathBreakdown != "" && options.PathBreakdown != PATH_BREAKDOWN_DIRECTORY && options.PathBreakdown != PATH_BREAKDOWN_FILE {
+		return nil, fmt.Errorf("path breakdown '%s' is not supported, expected '%s' or '%s'", options.PathBreakdown, PATH_BREAKDOWN_DIRECTORY, PATH_BREAKDOWN_FILE)
+	}
+	if options.PathDepth < 1 {
+		return nil, fmt.Errorf("path depth must be at least 1, given: %d", options.PathDepth)
+	}
+	if options.ContentionWindow < 1 || options.ContentionMinAuthors < 2 {
+		return nil, fmt.Errorf("contention window and minimum authors must be at least 1 and 2, given: %d, %d", options.ContentionWindow, options.ContentionMinAuthors)
+	}
+	if options.Imports != IMPORTS_KEEP && options.Imports != IMPORTS_EXCLUDE && options.Imports != IMPORTS_SEPARATE {
+		return nil, fmt.Errorf("imports '%s' are not supported, expected '%s', '%s' or '%s'", options.Imports, IMPORTS_KEEP, IMPORTS_EXCLUDE, IMPORTS_SEPARATE)
+	}
+	if options.ImportMinFiles < 1 {
+		return nil, fmt.Errorf("minimum files of imports must be at least 1, given: %d", options.ImportMinFiles)
+	}
+	if options.PairingWindow <= 0 {
+		return nil, fmt.Errorf("pairing window must be positive, given: %s", options.PairingWindow)
+	}
+	if options.ReconcileSquash && options.GitHubRepo == "" && options.GitLabProject == "" {
+		return nil, fmt.Errorf("reconciling squash merges requires a GitHub repository or a GitLab project")
+	}
+	if options.ReconcileSquash && options.APIDump != "" {
+		return nil, fmt.Errorf("reconciling squash merges requires the API of the hosting platform, API dumps lack the commits of merged changes")
+	}
+	if options.APIDump != "" && options.GitHubRepo == "" && options.GitLabProject == "" && options.JiraURL == "" {
+		return nil, fmt.Errorf("API dump requires a GitHub repository, a GitLab project or a Jira URL, which select the dumps read")
+	}
+	if err := validateBranchPatterns(options.Branches); err != nil {
+		return nil, err
+	}
+	if options.Subdir != "" {
+		subdir, err := normalizeSubdir(options.Subdir)
+		if err != nil {
+			return nil, err
+		}
+		analyzer.options.Subdir = subdir
+	}
+	if options.Output == OUTPUT_STDOUT && options.AttestationSigner != nil {
+		return nil, fmt.Errorf("attestations are written next to the report file, the report must not be written to the standard output")
+	}
+	if options.Offline {
+		if err := checkOfflineAssets(); err != nil {
+			return nil, err
+		}
+	}
+	if options.GitHubRepo != "" && options.GitLabProject != "" {
+		return nil, fmt.Errorf("GitHub repository and GitLab project must not be used together")
+	}
+
+	messages, err := loadMessageCatalog(options.Language)
+	if err != nil {
+		return nil, err
+	}
+	analyzer.messages = messages
+
+	columns, err := parseReportColumns(strings.Join(options.Columns, ","))
+	if err != nil {
+		return nil, err
+	}
+	analyzer.columns = columns
+
+	analyzer.sections = REPORT_SECTIONS
+	if analyzer.config.Sections != nil {
+		sections, err := parseReportSections(strings.Join(analyzer.config.Sections, ","))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sections in configuration: %w", err)
+		}
+		analyzer.sections = sections
+	}
+	if len(options.Sections) > 0 {
+		sections, err := parseReportSections(strings.Join(options.Sections, ","))
+		if err != nil {
+			return nil, err
+		}
+		analyzer.sections = sections
+	}
+
+	if options.Mailmap != "" {
+		analyzer.mailmap, err = loadMailmap(options.Mailmap)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Teams != "" {
+		analyzer.teams, err = loadTeams(options.Teams)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	analyzer.profile = &OutputProfile{}
+	if options.Profile != "" {
+		profile, err := selectOutputProfile(analyzer.config, options.Profile)
+		if err != nil {
+			return nil, err
+		}
+		analyzer.profile = profile
+	}
+
+	analyzer.backportPattern, err = regexp.Compile(options.BackportPattern)
+	if err != nil {
+		return nil, fmt.Errorf("backport pattern is not a valid regular expression: %w", err)
+	}
+
+	analyzer.authors, err = parseAuthorPatterns(options.Authors)
+	if err != nil {
+		return nil, err
+	}
+	excludedAuthors := options.ExcludeAuthors
+	if options.ExcludeBots {
+		excludedAuthors = append(slices.Clone(BOT_AUTHOR_PATTERNS), excludedAuthors...)
+	}
+	analyzer.excludedAuthors, err = parseAuthorPatterns(excludedAuthors)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.APIDump != "" {
+		if options.GitHubRepo != "" {
+			analyzer.hosting, err = loadGitHubDump(options.APIDump)
+		} else if options.GitLabProject != "" {
+			analyzer.hosting, err = loadGitLabDump(options.APIDump)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if options.JiraURL != "" {
+			analyzer.jira, err = loadJiraDump(options.APIDump, options.JiraURL, options.JiraEpicField)
+			if err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		if options.GitHubRepo != "" {
+			analyzer.hosting = newGitHubClient(options.GitHubAPIURL, options.GitHubRepo)
+		} else if options.GitLabProject != "" {
+			analyzer.hosting = newGitLabClient(options.GitLabURL, options.GitLabProject)
+		}
+		if options.JiraURL != "" {
+			analyzer.jira = newJiraClient(options.JiraURL, options.JiraEpicField)
+		}
+	}
+
+	return analyzer, nil
+}
+
+// Options returns the options of the analyzer, with defaults applied.
+func (analyzer *Analyzer) Options() Options {
+	return analyzer.options
+}
+
+// newRun takes the settings of an analysis from the options of the analyzer, the analysis completes them from the
+// repository.
+func (analyzer *Analyzer) newRun() *analysisRun {
+	options := analyzer.options
+	run := &analysisRun{
+		config:                 analyzer.config,
+		mainBranch:             "main",
+		groupBy:                options.GroupBy,
+		batchSize:              options.BatchSize,
+		workers:                options.Workers,
+		useCache:               options.UseCache,
+		resume:                 options.Resume,
+		branchPatterns:         options.Branches,
+		mainBranchOnly:         options.MainBranchOnly || options.RevRange != "",
+		remoteBranches:         options.RemoteBranches,
+		authorPatterns:         analyzer.authors,
+		excludedAuthorPatterns: analyzer.excludedAuthors,
+		since:                  options.Since,
+		until:                  options.Until,
+		revRange:               options.RevRange,
+		excludeMerges:          options.NoMerges,
+		strict:                 options.Strict,
+		uniqueCommits:          options.UniqueCommits,
+		storeMemoryBudget:      defaultCommitStoreMemoryBudget,
+		theme:                  options.Theme,
+		columns:                analyzer.columns,
+		sortBy:                 options.SortBy,
+		top:                    options.Top,
+		sections:               analyzer.sections,
+		offline:                options.Offline,
+		language:               options.Language,
+		messages:               analyzer.messages,
+		profile:                analyzer.profile,
+		teams:                  analyzer.teams,
+		attestationSigner:      options.AttestationSigner,
+		riskMaxCommits:         options.RiskMaxCommits,
+		riskMaxDays:            options.RiskMaxDays,
+		deployMarker:           options.DeployMarker,
+		forecastPeriods:        options.ForecastPeriods,
+		focusDepth:             options.FocusDepth,
+		pathBreakdown:          options.PathBreakdown,
+		pathDepth:              options.PathDepth,
+		contentionWindow:       options.ContentionWindow,
+		contentionMinAuthors:   options.ContentionMinAuthors,
+		pairingWindow:          options.PairingWindow,
+		importsMode:            options.Imports,
+		importMinFiles:         options.ImportMinFiles,
+		localActivity:          options.LocalActivity,
+		leaderboard:            options.Leaderboard,
+	}
+	if options.MaxMemory > 0 {
+		run.storeMemoryBudget = options.MaxMemory / 4
+		run.spillCommits = true
+	}
+	return run
+}
+
+// Analyze runs the analysis and all enrichments of the branch reports of the local repository located at repoPath.
+//
+// The configuration shipped in the repository is applied, the options and the configuration of the analyzer take precedence.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository, see PrepareRepository for repositories given by URL.
+//   - repoName: The name of the repository shown in the report.
+//
+// Returns:
+//   - The report of the repository.
+//   - An error if the configuration of the repository is invalid or the analysis failed.
+func (analyzer *Analyzer) Analyze(repoPath string, repoName string) (*Report, error) {
+	run := analyzer.newRun()
+	report := &Report{RepoName: repoName, RepoPath: repoPath, StartedOn: time.Now(), analyzer: analyzer, run: run}
+	if run.attestationSigner != nil {
+		heads, err := run.listBranchHeads(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		report.heads = heads
+	}
+
+	repoConfig, err := loadRepositoryConfig(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	if repoConfig != nil {
+		log.Printf("Configuration of the repository has been loaded from: %s", REPOSITORY_CONFIG_FILE)
+	}
+	run.config = analyzer.config.withDefaults(repoConfig)
+
+	mailmap := analyzer.mailmap
+	if analyzer.options.Mailmap == "" {
+		mailmap, err = loadRepositoryMailmap(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		if mailmap != nil {
+			log.Printf("Mailmap of the repository has been loaded from: %s", MAILMAP_FILE)
+		}
+	}
+	run.config = run.config.withMailmap(mailmap)
+
+	if analyzer.options.MainBranch != "" {
+		run.mainBranch = analyzer.options.MainBranch
+	} else if run.config.MainBranch != "" {
+		run.mainBranch = run.config.MainBranch
+		log.Printf("Name of the main branch has been set to: %s", run.mainBranch)
+	}
+	run.fileFilter = analyzer.options.FileFilter
+	if run.fileFilter == "" {
+		run.fileFilter = run.config.Filter
+	}
+	if analyzer.options.Subdir != "" {
+		run.fileFilter = subdirFileFilter(analyzer.options.Subdir, run.fileFilter)
+	}
+	releaseBranches := analyzer.options.ReleaseBranches
+	if releaseBranches == "" {
+		releaseBranches = run.config.ReleaseBranches
+	}
+	report.MainBranch = run.mainBranch
+	report.FileFilter = run.fileFilter
+
+	run.gitDateRange, err = resolveDateRange(repoPath, run.since, run.until)
+	if err != nil {
+		return nil, err
+	}
+
+	run.gitRevRange, err = resolveRevRange(repoPath, run.revRange)
+	if err != nil {
+		return nil, err
+	}
+
+	var reportCachePath, reportCacheKey string
+	if run.useCache && analyzer.reportCacheable(run) {
+		reportCachePath, err = reportCacheFilePath(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		reportCacheKey, err = analyzer.reportCacheKey(run, repoPath)
+		if err != nil {
+			return nil, err
+		}
+		if cached := run.loadReportCache(reportCachePath, reportCacheKey); cached != nil {
+			log.Printf("Neither the repository nor the options changed, the report has been taken from the cache: %s", reportCachePath)
+			report.Branches = cached
+			return report, nil
+		}
+	}
+
+	if analyzer.options.ReconcileSquash && analyzer.hosting != nil {
+		run.squashedAuthors = run.loadSquashedAuthors(repoPath, analyzer.hosting)
+	}
+
+	branchReports, err := run.analyzeGitHistoryByBranch(repoPath, run.fileFilter)
+	if err != nil {
+		return nil, fmt.Errorf("error analyzing git history: %w", err)
+	}
+	report.Branches = branchReports
+
+	if slices.Contains(run.sections, "totals") {
+		run.assessRepositoryTotals(repoPath, branchReports, run.fileFilter)
+	}
+	if slices.Contains(run.sections, "branch-health") {
+		run.assessBranchRisk(repoPath, branchReports)
+	}
+	if slices.Contains(run.sections, "naming") {
+		run.assessBranchNaming(repoPath, branchReports)
+	}
+	if slices.Contains(run.sections, "protected-paths") {
+		run.assessProtectedPaths(repoPath, branchReports, run.fileFilter)
+	}
+	if slices.Contains(run.sections, "delivery") {
+		run.assessDeliveryMetrics(repoPath, branchReports)
+	}
+	if slices.Contains(run.sections, "signatures") {
+		run.assessTagSignatures(repoPath, branchReports)
+	}
+	if slices.Contains(run.sections, "contention") {
+		run.assessContention(repoPath, branchReports, run.fileFilter)
+	}
+	if slices.Contains(run.sections, "pairing") {
+		run.assessPairing(repoPath, branchReports, run.fileFilter)
+	}
+	if run.localActivity && slices.Contains(run.sections, "local-activity") {
+		run.assessLocalActivity(repoPath, branchReports)
+	}
+	if run.leaderboard && slices.Contains(run.sections, "leaderboard") {
+		run.assessLeaderboard(repoPath, branchReports, run.fileFilter)
+	}
+	if slices.Contains(run.sections, "heatmap") {
+		run.assessHeatmap(repoPath, branchReports, run.fileFilter)
+	}
+	if slices.Contains(run.sections, "file-age") {
+		run.assessFileAgeChurn(repoPath, branchReports, run.fileFilter)
+	}
+	if slices.Contains(run.sections, "forecast") {
+		run.forecastBranchActivity(branchReports, run.forecastPeriods)
+	}
+
+	if releaseBranches != "" && slices.Contains(run.sections, "backports") {
+		run.assessBackportCoverage(repoPath, branchReports, releaseBranches, analyzer.backportPattern)
+	}
+
+	if analyzer.hosting != nil && slices.Contains(run.sections, "reviews") {
+		run.assessReviewLatency(analyzer.hosting, branchReports)
+	}
+
+	if run.config.Identities != nil && slices.Contains(run.sections, "departments") {
+		run.assessDepartments(newIdentityProvider(run.config.Identities), branchReports)
+	}
+
+	if analyzer.jira != nil && slices.Contains(run.sections, "initiatives") {
+		log.Printf("Grouping work by initiative using Jira: %s", analyzer.options.JiraURL)
+		for _, branchReport := range branchReports {
+			branchReport.Initiatives = groupWorkByInitiative(branchReport, analyzer.jira)
+		}
+	}
+
+	if reportCachePath != "" {
+		if err := run.saveReportCache(reportCachePath, reportCacheKey, branchReports); err != nil {
+			log.Printf("Failed to save report cache: %v", err)
+		}
+	}
+
+	return report, nil
+}
+
+// WriteReport analyzes the repository located at repoPath and writes the report (see Options.Format) into the
+// current directory, signed with an attestation if Options.AttestationSigner is set.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - repoName: The name of the repository shown in the report and used in the file name.
+//
+// Returns:
+//   - The report of the repository.
+//   - An error if the analysis failed or the report could not be written.
+func (analyzer *Analyzer) WriteReport(repoPath string, repoName string) (*Report, error) {
+	report, err := analyzer.Analyze(repoPath, repoName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := report.Write(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Render generates the report in the given format (see REPORT_FORMATS).
+func (report *Report) Render(format string) (string, error) {
+	if report.data != nil {
+		return report.renderStored(format)
+	}
+
+	switch format {
+	case REPORT_FORMAT_HTML:
+		return report.run.generateHTMLReportByBranch(report.Branches, report.RepoName, report.FileFilter)
+	case REPORT_FORMAT_JSON:
+		return report.run.generateJSONReport(report.Branches, report.RepoName, report.FileFilter)
+	case REPORT_FORMAT_CSV:
+		return report.run.generateCSVReport(report.Branches, report.FileFilter)
+	case REPORT_FORMAT_MARKDOWN:
+		return report.run.encodeMarkdownReport(report.run.newReportData(report.Branches, report.RepoName, report.FileFilter)), nil
+	}
+	return "", fmt.Errorf("format '%s' is not supported, expected any of: %s", format, strings.Join(REPORT_FORMATS, ", "))
+}
+
+// HTML generates the HTML report.
+func (report *Report) HTML() (string, error) {
+	return report.Render(REPORT_FORMAT_HTML)
+}
+
+// ConfluenceStorage generates the report in the storage format of Confluence pages, see PublishToConfluence.
+func (report *Report) ConfluenceStorage() (string, error) {
+	return report.run.generateConfluenceStorage(report.Branches, report.RepoName, report.FileFilter)
+}
+
+// Write writes the report in the format of the analyzer (see Options.Output), next to an attestation if
+// Options.AttestationSigner is set.
+//
+// Returns:
+//   - The name of the written report file.
+//   - An error if the report could not be generated or written.
+func (report *Report) Write() (string, error) {
+	format := report.analyzer.options.Format
+	content, err := report.Render(format)
+	if err != nil {
+		return "", fmt.Errorf("error generating %s report: %w", strings.ToUpper(format), err)
+	}
+
+	filename, err := report.analyzer.outputPath("report_"+report.RepoName, reportFileExtension(format), time.Now())
+	if err != nil {
+		return "", err
+	}
+	if err := writeOutput(filename, content); err != nil {
+		return "", fmt.Errorf("error writing %s report to file: %w", strings.ToUpper(format), err)
+	}
+
+	log.Printf("%s report generated: %s\n", strings.ToUpper(format), filename)
+
+	if report.analyzer.options.AttestationSigner != nil {
+		attestation, err := report.run.writeAttestation(report.RepoPath, report.RepoName, report.heads, filename, report.StartedOn)
+		if err != nil {
+			return "", fmt.Errorf("error writing attestation: %w", err)
+		}
+		log.Printf("Attestation generated: %s\n", attestation)
+	}
+
+	if report.analyzer.options.Calendars {
+		if _, err := report.WriteCalendars(); err != nil {
+			return "", err
+		}
+	}
+
+	if report.analyzer.options.Insights {
+		if _, err := report.WriteInsights(); err != nil {
+			return "", err
+		}
+	}
+	return filename, nil
+}
+
+// WriteCalendars writes an iCalendar file per contributor with an all-day event per active day (on any local branch)
+// into a directory next to the report.
+//
+// Returns:
+//   - The name of the directory holding the calendars.
+//   - An error if git failed or the calendars could not be written.
+func (report *Report) WriteCalendars() (string, error) {
+	generatedOn := time.Now()
+	directory, err := report.analyzer.outputSubdirectory("calendars_"+report.RepoName, generatedOn)
+	if err != nil {
+		return "", err
+	}
+	count, err := report.run.writeCalendars(report.RepoPath, report.RepoName, directory, generatedOn)
+	if err != nil {
+		return "", fmt.Errorf("error writing calendars: %w", err)
+	}
+
+	log.Printf("Calendars of %d contributors generated: %s\n", count, directory)
+	return directory, nil
+}
+
+// WriteInsights writes a one-page HTML document per author (tenure, totals, focus areas and trend) into a
+// directory next to the report, the documents are laid out to be printed or saved as PDF.
+//
+// Returns:
+//   - The name of the directory holding the documents.
+//   - An error if git failed or the documents could not be written.
+func (report *Report) WriteInsights() (string, error) {
+	directory, err := report.analyzer.outputSubdirectory("insights_"+report.RepoName, time.Now())
+	if err != nil {
+		return "", err
+	}
+	count, err := report.run.writeAuthorInsights(report.RepoPath, report.RepoName, report.Branches, directory)
+	if err != nil {
+		return "", fmt.Errorf("error writing insights: %w", err)
+	}
+
+	log.Printf("Insights of %d authors generated: %s\n", count, directory)
+	return directory, nil
+}
@@ -0,0 +1,56 @@
+package gogitstats
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// BOOTSTRAP_VERSION is the version of Bootstrap used by the HTML reports, linked from the CDN or embedded.
+const BOOTSTRAP_VERSION = "5.3.2"
+
+const bootstrapCDN = "https://cdn.jsdelivr.net/npm/bootstrap@" + BOOTSTRAP_VERSION + "/dist"
+
+const bootstrapStylesAsset = "assets/bootstrap.min.css"
+const bootstrapScriptsAsset = "assets/bootstrap.bundle.min.js"
+
+// reportAssets holds the files inlined into offline reports, see assets/README.md.
+//
+//go:embed assets
+var reportAssets embed.FS
+
+// checkOfflineAssets reports an error if the assets inlined into offline reports are not embedded into the binary.
+func checkOfflineAssets() error {
+	for _, asset := range []string{bootstrapStylesAsset, bootstrapScriptsAsset} {
+		if _, err := reportAssets.ReadFile(asset); err != nil {
+			return fmt.Errorf("offline reports are not supported by this build, %s has not been embedded (see pkg/gogitstats/assets/README.md)", asset)
+		}
+	}
+	return nil
+}
+
+// bootstrapStyles returns the markup including the Bootstrap stylesheet into the head of a report.
+func (run *analysisRun) bootstrapStyles() (template.HTML, error) {
+	if !run.offline {
+		return template.HTML(`<link href="` + bootstrapCDN + `/css/bootstrap.min.css" rel="stylesheet">`), nil
+	}
+	content, err := reportAssets.ReadFile(bootstrapStylesAsset)
+	if err != nil {
+		return "", checkOfflineAssets()
+	}
+	return template.HTML("<style>\n" + string(content) + "\n</style>"), nil
+}
+
+// bootstrapScripts returns the markup including the Bootstrap scripts (with Popper) into a report.
+func (run *analysisRun) bootstrapScripts() (template.HTML, error) {
+	if !run.offline {
+		return template.HTML(`<script src="` + bootstrapCDN + `/js/bootstrap.bundle.min.js"></script>`), nil
+	}
+	content, err := reportAssets.ReadFile(bootstrapScriptsAsset)
+	if err != nil {
+		return "", checkOfflineAssets()
+	}
+	// a closing script tag within the bundle would end the inlined script early
+	return template.HTML("<script>\n" + strings.ReplaceAll(string(content), "</script", `<\/script`) + "\n</script>"), nil
+}
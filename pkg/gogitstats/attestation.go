@@ -0,0 +1,221 @@
+package gogitstats
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const ATTESTATION_FILE_SUFFIX = ".intoto.json"
+
+// in-toto statement (https://github.com/in-toto/attestation) wrapped in a DSSE envelope
+const intotoStatementType = "https://in-toto.io/Statement/v1"
+const intotoPayloadType = "application/vnd.in-toto+json"
+const analysisPredicateType = "https://github.com/vdmitriyev/gogitstats/analysis/v1"
+
+// Version and Build of the program embedding the package are recorded in attestations.
+var Version string = ""
+var Build string = ""
+
+type attestationSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type attestedBranch struct {
+	Name   string `json:"name"`
+	Commit string `json:"commit"`
+}
+
+type analysisPredicate struct {
+	Tool struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Build   string `json:"build"`
+	} `json:"tool"`
+	Repository struct {
+		Name string `json:"name"`
+		URI  string `json:"uri,omitempty"`
+	} `json:"repository"`
+	Branches []attestedBranch `json:"branches"`
+	Options  struct {
+		MainBranch    string   `json:"mainBranch"`
+		FileFilter    string   `json:"fileFilter,omitempty"`
+		GroupBy       string   `json:"groupBy"`
+		Since         string   `json:"since,omitempty"`
+		Until         string   `json:"until,omitempty"`
+		Sections      []string `json:"sections"`
+		ConfigDigest  string   `json:"configDigest"`
+		OutputProfile bool     `json:"outputProfile"`
+	} `json:"options"`
+	StartedOn  string `json:"startedOn"`
+	FinishedOn string `json:"finishedOn"`
+}
+
+type intotoStatement struct {
+	Type          string               `json:"_type"`
+	Subject       []attestationSubject `json:"subject"`
+	PredicateType string               `json:"predicateType"`
+	Predicate     *analysisPredicate   `json:"predicate"`
+}
+
+type dsseSignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+type dsseEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     string          `json:"payload"`
+	Signatures  []dsseSignature `json:"signatures"`
+}
+
+// LoadAttestationKey reads a PEM encoded PKCS#8 private key (Ed25519, ECDSA or RSA), e.g. created by
+// `openssl genpkey -algorithm ed25519 -out key.pem`.
+func LoadAttestationKey(keyPath string) (crypto.Signer, error) {
+	content, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", keyPath, err)
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded key found in %s", keyPath)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PKCS#8 private key in %s: %w", keyPath, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type in %s", keyPath)
+	}
+	return signer, nil
+}
+
+// listBranchHeads returns the commits the branches (see listBranchRefs) point to, which are the inputs of the analysis.
+func (run *analysisRun) listBranchHeads(repoPath string) ([]attestedBranch, error) {
+	refs, err := run.listBranchRefs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []attestedBranch
+	for _, ref := range refs {
+		branches = append(branches, attestedBranch{Name: ref.Name, Commit: ref.Commit})
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		return branches[i].Name < branches[j].Name
+	})
+	return branches, nil
+}
+
+// writeAttestation writes a signed in-toto statement next to the report, which binds the report
+// (by its SHA-256 digest) to the analyzed commits and the options of the analysis.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - repoName: The name of the repository.
+//   - branches: The branch heads read before the analysis.
+//   - reportFile: The generated report.
+//   - startedOn: The start of the analysis.
+//
+// Returns:
+//   - The name of the attestation file.
+//   - An error if the report could not be read or the attestation could not be signed or written.
+func (run *analysisRun) writeAttestation(repoPath string, repoName string, branches []attestedBranch, reportFile string, startedOn time.Time) (string, error) {
+	content, err := os.ReadFile(reportFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read report %s: %w", reportFile, err)
+	}
+	reportDigest := sha256.Sum256(content)
+
+	predicate := &analysisPredicate{Branches: branches, StartedOn: startedOn.UTC().Format(time.RFC3339), FinishedOn: time.Now().UTC().Format(time.RFC3339)}
+	predicate.Tool.Name = "gogitstats"
+	predicate.Tool.Version = Version
+	predicate.Tool.Build = Build
+	predicate.Repository.Name = repoName
+	if output, err := gitCommand(repoPath, "remote", "get-url", "origin").Output(); err == nil {
+		predicate.Repository.URI = strings.TrimSpace(string(output))
+	}
+	predicate.Options.MainBranch = run.mainBranch
+	predicate.Options.FileFilter = run.fileFilter
+	predicate.Options.GroupBy = run.groupBy
+	predicate.Options.Since = run.since
+	predicate.Options.Until = run.until
+	predicate.Options.Sections = run.sections
+	configDigest := sha256.Sum256([]byte(run.analysisOptionsKey(run.fileFilter)))
+	predicate.Options.ConfigDigest = "sha256:" + hex.EncodeToString(configDigest[:])
+	predicate.Options.OutputProfile = run.profile.AnonymizeEmails || run.profile.HidePaths || run.profile.Columns != nil
+
+	statement := intotoStatement{
+		Type:          intotoStatementType,
+		Subject:       []attestationSubject{{Name: reportFile, Digest: map[string]string{"sha256": hex.EncodeToString(reportDigest[:])}}},
+		PredicateType: analysisPredicateType,
+		Predicate:     predicate,
+	}
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode attestation: %w", err)
+	}
+
+	signature, keyID, err := signDSSE(run.attestationSigner, intotoPayloadType, payload)
+	if err != nil {
+		return "", err
+	}
+	envelope, err := json.MarshalIndent(dsseEnvelope{
+		PayloadType: intotoPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []dsseSignature{{KeyID: keyID, Sig: base64.StdEncoding.EncodeToString(signature)}},
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode attestation: %w", err)
+	}
+
+	filename := reportFile + ATTESTATION_FILE_SUFFIX
+	if err := os.WriteFile(filename, envelope, 0644); err != nil {
+		return "", fmt.Errorf("failed to write attestation %s: %w", filename, err)
+	}
+	return filename, nil
+}
+
+// signDSSE signs the pre-authentication encoding of the payload (DSSE v1).
+//
+// Returns:
+//   - The signature.
+//   - The ID of the key, the SHA-256 digest of its public key (PKIX, DER).
+//   - An error if signing failed.
+func signDSSE(signer crypto.Signer, payloadType string, payload []byte) ([]byte, string, error) {
+	message := []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+
+	var signature []byte
+	var err error
+	switch signer.(type) {
+	case ed25519.PrivateKey:
+		signature, err = signer.Sign(rand.Reader, message, crypto.Hash(0))
+	default:
+		digest := sha256.Sum256(message)
+		signature, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sign attestation: %w", err)
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	keyDigest := sha256.Sum256(publicKey)
+	return signature, "sha256:" + hex.EncodeToString(keyDigest[:]), nil
+}
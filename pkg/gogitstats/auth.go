@@ -0,0 +1,144 @@
+package gogitstats
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GIT_TOKEN_VARIABLE names the environment variable holding an access token for the host of the HTTPS remote
+// being cloned or fetched, GITHUB_TOKEN is used for github.com. The user name sent with it is taken from
+// GIT_USERNAME_VARIABLE.
+const GIT_TOKEN_VARIABLE = "GIT_TOKEN"
+const GIT_USERNAME_VARIABLE = "GIT_USERNAME"
+
+// defaultTokenUsername is sent with access tokens if GIT_USERNAME_VARIABLE is not set, GitHub and GitLab accept
+// any user name with a token.
+const defaultTokenUsername = "x-access-token"
+
+// remoteAccessFailures are fragments of git messages telling that a remote denied access, matched lower case.
+var remoteAccessFailures = []string{
+	"authentication failed", "could not read username", "could not read password", "terminal prompts disabled",
+	"permission denied (publickey", "repository not found", "access denied", "the requested url returned error: 401",
+	"the requested url returned error: 403",
+}
+
+// remoteTokens returns the access tokens of the environment by the prefix of the HTTPS URLs they are sent to.
+// GIT_TOKEN_VARIABLE is limited to the host of the given remote, so git does not send it to other hosts it may
+// access along the way (e.g., submodules or the repository of `--reference`).
+func remoteTokens(remoteURL string) map[string]string {
+	tokens := make(map[string]string)
+	if token := os.Getenv(GIT_TOKEN_VARIABLE); token != "" {
+		if u, err := url.Parse(remoteURL); err == nil && u.Scheme == "https" && u.Host != "" && u.User == nil {
+			tokens["https://"+u.Host+"/"] = token
+		}
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		tokens["https://github.com/"] = token
+	}
+	return tokens
+}
+
+// remoteTokenEnvironment returns the environment variables injecting the access tokens of the environment into
+// the HTTPS URLs git accesses (url.<base>.insteadOf, the longest prefix wins), see remoteTokens. The rewrite is passed by
+// GIT_CONFIG_COUNT (git 2.31 or newer), so tokens neither show in the process list nor end up in the remote
+// URL stored in a clone, which keeps the URL it has been given.
+func remoteTokenEnvironment(remoteURL string) []string {
+	tokens := remoteTokens(remoteURL)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	username := cmp.Or(os.Getenv(GIT_USERNAME_VARIABLE), defaultTokenUsername)
+	env := []string{"GIT_CONFIG_COUNT=" + strconv.Itoa(len(tokens))}
+	i := 0
+	for prefix, token := range tokens {
+		rewritten := "https://" + url.UserPassword(username, token).String() + "@" + strings.TrimPrefix(prefix, "https://")
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=url.%s.insteadOf", i, rewritten),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, prefix))
+		i++
+	}
+	return env
+}
+
+// redactRemoteTokens replaces the access tokens of the environment in git output, e.g. in URLs of error messages.
+func redactRemoteTokens(output string) string {
+	for _, variable := range []string{GIT_TOKEN_VARIABLE, "GITHUB_TOKEN"} {
+		token := os.Getenv(variable)
+		if token == "" {
+			continue
+		}
+		output = strings.ReplaceAll(output, token, "***")
+		output = strings.ReplaceAll(output, url.QueryEscape(token), "***")
+	}
+	return output
+}
+
+// remoteCommandError describes a failed git command accessing a remote, with a hint on authentication if the remote
+// denied access, since private repositories fail with the same messages as missing ones.
+func remoteCommandError(action string, err error, output []byte) error {
+	text := redactRemoteTokens(string(output))
+	lower := strings.ToLower(text)
+	for _, failure := range remoteAccessFailures {
+		if strings.Contains(lower, failure) {
+			return fmt.Errorf("failed to %s: %s, output: %s\nThe remote denied access, private repositories need credentials: "+
+				"an access token in %s (sent to the host of the repository only, GITHUB_TOKEN for github.com) for HTTPS URLs, a key (`--ssh-key`) for SSH URLs "+
+				"or a credential helper (`--credential-helper`)", action, err, strings.TrimSpace(text), GIT_TOKEN_VARIABLE)
+		}
+	}
+	return fmt.Errorf("failed to %s: %s, output: %s", action, err, text)
+}
+
+// authArgs returns the arguments of `git clone` configuring the clone to authenticate with the SSH key and the
+// credential helper of the options. They are stored in the configuration of the clone, so fetching it later
+// (e.g., by `serve`) authenticates alike.
+//
+// Returns:
+//   - The arguments, nil if neither is configured.
+//   - An error if the SSH key does not exist.
+func (options CloneOptions) authArgs() ([]string, error) {
+	var args []string
+	for _, setting := range options.authSettings() {
+		args = append(args, "--config", setting[0]+"="+setting[1])
+	}
+	if options.SSHKey != "" {
+		if info, err := os.Stat(options.SSHKey); err != nil || info.IsDir() {
+			return nil, fmt.Errorf("SSH key %s does not exist", options.SSHKey)
+		}
+	}
+	return args, nil
+}
+
+// authSettings returns the git configuration (name, value) authenticating with the SSH key and the credential
+// helper of the options.
+func (options CloneOptions) authSettings() [][2]string {
+	var settings [][2]string
+	if options.SSHKey != "" {
+		// the clone is fetched from other directories, so the key is given by an absolute path
+		keyPath, err := filepath.Abs(options.SSHKey)
+		if err != nil {
+			keyPath = options.SSHKey
+		}
+		quoted := "'" + strings.ReplaceAll(filepath.ToSlash(keyPath), "'", `'\''`) + "'"
+		settings = append(settings, [2]string{"core.sshCommand", "ssh -i " + quoted + " -o IdentitiesOnly=yes"})
+	}
+	if options.CredentialHelper != "" {
+		settings = append(settings, [2]string{"credential.helper", options.CredentialHelper})
+	}
+	return settings
+}
+
+// configureAuth updates the authentication settings of an existing clone, e.g. if another SSH key is given.
+func (options CloneOptions) configureAuth(repoPath string) error {
+	for _, setting := range options.authSettings() {
+		if output, err := gitCommand(repoPath, "config", setting[0], setting[1]).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to configure %s of %s: %w, output: %s", setting[0], repoPath, err, output)
+		}
+	}
+	return nil
+}
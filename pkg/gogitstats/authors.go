@@ -0,0 +1,96 @@
+package gogitstats
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// authorPattern matches the emails of authors: a glob (e.g., *@example.com) or a regular expression
+// enclosed in slashes (e.g., /^(jane|john)@example\.com$/), both case-insensitive.
+type authorPattern struct {
+	glob  string
+	regex *regexp.Regexp
+}
+
+// BOT_AUTHOR_PATTERNS match the emails of common automation accounts excluded by Options.ExcludeBots: GitHub apps
+// (e.g., dependabot[bot]), Dependabot, Renovate, GitHub Actions and generic no-reply or bot addresses. The noreply
+// addresses of GitHub users (e.g., 12345+jane@users.noreply.github.com) are not matched.
+var BOT_AUTHOR_PATTERNS = []string{
+	`*\[bot\]*`,
+	"*dependabot*",
+	"*renovate*",
+	"github-actions@*",
+	"actions@github.com",
+	"/^(no-?reply|bot)@/",
+	"/[-._+]bot@/",
+}
+
+// parseAuthorPatterns parses and validates the patterns of Options.Authors.
+func parseAuthorPatterns(values []string) ([]authorPattern, error) {
+	var patterns []authorPattern
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil, fmt.Errorf("author patterns must not be empty")
+		}
+		if len(value) > 2 && strings.HasPrefix(value, "/") && strings.HasSuffix(value, "/") {
+			regex, err := regexp.Compile("(?i)" + value[1:len(value)-1])
+			if err != nil {
+				return nil, fmt.Errorf("author pattern '%s' is not a valid regular expression: %w", value, err)
+			}
+			patterns = append(patterns, authorPattern{regex: regex})
+			continue
+		}
+		if _, err := path.Match(value, ""); err != nil {
+			return nil, fmt.Errorf("author pattern '%s' is not supported: %w", value, err)
+		}
+		patterns = append(patterns, authorPattern{glob: strings.ToLower(value)})
+	}
+	return patterns, nil
+}
+
+// authorSelected reports whether the contributions of the author (given by the canonical email) are reported.
+func (run *analysisRun) authorSelected(email string) bool {
+	if matchesAuthorPattern(run.excludedAuthorPatterns, email) {
+		return false
+	}
+	return len(run.authorPatterns) == 0 || matchesAuthorPattern(run.authorPatterns, email)
+}
+
+// matchesAuthorPattern reports whether the email matches any of the patterns.
+func matchesAuthorPattern(patterns []authorPattern, email string) bool {
+	for _, pattern := range patterns {
+		if pattern.regex != nil {
+			if pattern.regex.MatchString(email) {
+				return true
+			}
+		} else if matched, _ := path.Match(pattern.glob, strings.ToLower(email)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// authorPatternsKey describes the author patterns and exclusions for the analysis cache key.
+func (run *analysisRun) authorPatternsKey() string {
+	key := describeAuthorPatterns(run.authorPatterns)
+	if len(run.excludedAuthorPatterns) > 0 {
+		key += "!" + describeAuthorPatterns(run.excludedAuthorPatterns)
+	}
+	return key
+}
+
+// describeAuthorPatterns joins the patterns as given.
+func describeAuthorPatterns(patterns []authorPattern) string {
+	var keys []string
+	for _, pattern := range patterns {
+		if pattern.regex != nil {
+			keys = append(keys, "/"+pattern.regex.String()+"/")
+		} else {
+			keys = append(keys, pattern.glob)
+		}
+	}
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,182 @@
+package gogitstats
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var defaultBackportPattern string = `(?i)\bfix`
+
+type BackportedFix struct {
+	Hash          string
+	Email         string
+	Date          string
+	Subject       string
+	Backported    bool
+	MatchedBy     string // "patch-id" or "subject"
+	BackportHash  string
+	BackportEmail string
+	BackportDate  string
+}
+
+type BackportCoverage struct {
+	ReleaseBranch   string
+	MergeBase       string
+	Fixes           []*BackportedFix
+	BackportedCount int
+}
+
+type logEntry struct {
+	Hash    string
+	Email   string
+	Date    string
+	Subject string
+	PatchID string
+}
+
+// assessBackportCoverage reports for every release branch which mainline fixes have been backported.
+//
+// Mainline fixes are the non-merge commits on the main branch since the release branch has been forked,
+// whose subject matches fixPattern. A fix counts as backported if a commit on the release branch has the
+// same patch-id (the same change, e.g., cherry-picked) or, as a fallback, the same subject.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - branchReports: The branch reports, the coverage is attached to the reports of release branches.
+//   - releasePattern: The glob pattern identifying release branches (e.g., release/*).
+//   - fixPattern: The regular expression identifying fixes by commit subject.
+func (run *analysisRun) assessBackportCoverage(repoPath string, branchReports map[string]*BranchReport, releasePattern string, fixPattern *regexp.Regexp) {
+	for branchName, report := range branchReports {
+		report.Backports = nil
+		if branchName == run.mainBranch {
+			continue
+		}
+		if matched, _ := path.Match(releasePattern, branchName); !matched {
+			continue
+		}
+
+		coverage, err := run.measureBackportCoverage(repoPath, branchName, fixPattern)
+		if err != nil {
+			log.Printf("Measuring backport coverage of branch '%s' failed: %v", branchName, err)
+			continue
+		}
+		report.Backports = coverage
+		log.Printf("Release branch '%s': %d of %d mainline fixes backported", branchName, coverage.BackportedCount, len(coverage.Fixes))
+	}
+}
+
+func (run *analysisRun) measureBackportCoverage(repoPath string, releaseBranch string, fixPattern *regexp.Regexp) (*BackportCoverage, error) {
+	cmdMergeBase := gitCommand(repoPath, "merge-base", run.branchRevision(run.mainBranch), run.branchRevision(releaseBranch))
+	output, err := cmdMergeBase.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git merge-base failed: %w, output: %s", err, output)
+	}
+	coverage := &BackportCoverage{ReleaseBranch: releaseBranch, MergeBase: strings.TrimSpace(string(output))}
+
+	mainline, err := listCommitsWithPatchID(repoPath, fmt.Sprintf("%s..%s", coverage.MergeBase, run.branchRevision(run.mainBranch)))
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := listCommitsWithPatchID(repoPath, fmt.Sprintf("%s..%s", coverage.MergeBase, run.branchRevision(releaseBranch)))
+	if err != nil {
+		return nil, err
+	}
+
+	byPatchID := make(map[string]*logEntry)
+	bySubject := make(map[string]*logEntry)
+	for _, entry := range release {
+		if entry.PatchID != "" {
+			byPatchID[entry.PatchID] = entry
+		}
+		bySubject[entry.Subject] = entry
+	}
+
+	for _, entry := range mainline {
+		if !fixPattern.MatchString(entry.Subject) {
+			continue
+		}
+
+		fix := &BackportedFix{Hash: entry.Hash, Email: entry.Email, Date: entry.Date, Subject: entry.Subject}
+		backport, matchedBy := byPatchID[entry.PatchID], "patch-id"
+		if entry.PatchID == "" || backport == nil {
+			backport, matchedBy = bySubject[entry.Subject], "subject"
+		}
+		if backport != nil {
+			fix.Backported = true
+			fix.MatchedBy = matchedBy
+			fix.BackportHash = backport.Hash
+			fix.BackportEmail = backport.Email
+			fix.BackportDate = backport.Date
+			coverage.BackportedCount++
+		}
+		coverage.Fixes = append(coverage.Fixes, fix)
+	}
+
+	return coverage, nil
+}
+
+// listCommitsWithPatchID lists the non-merge commits of the given range together with their stable patch-id.
+func listCommitsWithPatchID(repoPath string, logRange string) ([]*logEntry, error) {
+	cmdLog := gitCommand(repoPath, "log", "--no-merges", gitLogDateFormat, "--format=%H%x1f%ae%x1f%ad%x1f%s", logRange)
+	output, err := cmdLog.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w, output: %s", err, output)
+	}
+
+	var entries []*logEntry
+	byHash := make(map[string]*logEntry)
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.SplitN(line, "\x1f", 4)
+		if len(parts) != 4 {
+			continue
+		}
+		entry := &logEntry{Hash: parts[0], Email: parts[1], Date: parts[2], Subject: parts[3]}
+		entries = append(entries, entry)
+		byHash[entry.Hash] = entry
+	}
+
+	if len(entries) == 0 {
+		return entries, nil
+	}
+
+	cmdPatch := gitCommand(repoPath, "log", "--no-merges", "-p", "--format=commit %H", logRange)
+	patches, err := cmdPatch.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git log output: %w", err)
+	}
+
+	cmdPatchID := gitCommand(repoPath, "patch-id", "--stable")
+	cmdPatchID.Stdin = patches
+	var patchIDs, stderr bytes.Buffer
+	cmdPatchID.Stdout = &patchIDs
+	cmdPatchID.Stderr = &stderr
+
+	if err := cmdPatch.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start git log: %w", err)
+	}
+	if err := cmdPatchID.Run(); err != nil {
+		_ = cmdPatch.Wait()
+		return nil, fmt.Errorf("git patch-id failed: %w, stderr: %s", err, stderr.String())
+	}
+	if err := cmdPatch.Wait(); err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+
+	scanner := bufio.NewScanner(&patchIDs)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 {
+			if entry, ok := byHash[fields[1]]; ok {
+				entry.PatchID = fields[0]
+			}
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,64 @@
+package gogitstats
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// isBareRepository reports whether the repository located at repoPath has no working tree (e.g., a mirror on a
+// server). Branches of bare repositories are analyzed by their refs as well, but files shipped in the working
+// tree (configuration, mailmap) are read from the commit HEAD points to.
+func isBareRepository(repoPath string) bool {
+	output, err := gitCommand(repoPath, "rev-parse", "--is-bare-repository").Output()
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
+
+// readHeadFile reads a file of the commit HEAD points to.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - name: The path of the file relative to the root of the repository.
+//
+// Returns:
+//   - The content of the file, nil if HEAD does not contain the file or the repository has no commits.
+//   - An error if git failed to read the file.
+func readHeadFile(repoPath string, name string) ([]byte, error) {
+	if err := gitCommand(repoPath, "cat-file", "-e", "HEAD:"+name).Run(); err != nil {
+		return nil, nil
+	}
+	output, err := gitCommand(repoPath, "cat-file", "blob", "HEAD:"+name).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD:%s: %w", name, err)
+	}
+	return output, nil
+}
+
+// isBareRepositoryDirectory reports whether the directory looks like a bare repository, i.e. holds HEAD,
+// objects and refs like the .git directory of a working tree.
+func isBareRepositoryDirectory(dir string) bool {
+	if info, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || info.IsDir() {
+		return false
+	}
+	for _, name := range []string{"objects", "refs"} {
+		if info, err := os.Stat(filepath.Join(dir, name)); err != nil || !info.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// RepositoryName names a local repository after its directory, the suffix ".git" of bare repositories
+// (e.g., "api.git") is dropped.
+func RepositoryName(repoPath string) string {
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		absPath = repoPath
+	}
+	name := filepath.Base(absPath)
+	if trimmed := strings.TrimSuffix(name, ".git"); trimmed != "" {
+		return trimmed
+	}
+	return name
+}
@@ -0,0 +1,172 @@
+package gogitstats
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TRACKED_REMOTE is the remote whose remote-tracking branches are analyzed, see Options.RemoteBranches.
+const TRACKED_REMOTE = "origin"
+
+// BranchActivity is a local branch with the date of its last commit.
+type BranchActivity struct {
+	Name       string
+	LastCommit string // 2006-01-02
+}
+
+// ListBranchesByActivity lists the branches of the repository (see listBranchRefs), most recently active first.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - remoteBranches: Whether the remote-tracking branches without a local branch are listed, see Options.RemoteBranches.
+//
+// Returns:
+//   - The branches ordered by the committer date of their last commit.
+//   - An error if git failed.
+func ListBranchesByActivity(repoPath string, remoteBranches bool) ([]BranchActivity, error) {
+	run := &analysisRun{remoteBranches: remoteBranches}
+	refs, err := run.listBranchRefs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []BranchActivity
+	for _, ref := range refs {
+		branches = append(branches, BranchActivity{Name: ref.Name, LastCommit: ref.LastCommit})
+	}
+	return branches, nil
+}
+
+// branchRef is a branch of the repository, see listBranchRefs.
+type branchRef struct {
+	Name       string
+	Revision   string // the name of a local branch, or the remote-tracking ref of a remote branch
+	Commit     string
+	LastCommit string // 2006-01-02
+}
+
+// listBranchRefs lists the local branches of the repository and, if it is a clone of a repository given by URL or
+// remote branches are analyzed (see Options.RemoteBranches), the remote-tracking branches of TRACKED_REMOTE without
+// a local branch, named like the local branch they would be checked out as. The remote branches are analyzed by
+// their remote-tracking refs, so neither the clone is changed by checkouts nor a dirty working tree gets in the way.
+//
+// Returns:
+//   - The branches ordered by the committer date of their last commit, most recent first.
+//   - An error if git failed.
+func (run *analysisRun) listBranchRefs(repoPath string) ([]*branchRef, error) {
+	patterns := []string{"refs/heads"}
+	if run.remoteBranches || isRepositoryClone(repoPath) {
+		patterns = append(patterns, "refs/remotes/"+TRACKED_REMOTE)
+	}
+	args := append([]string{"for-each-ref", "--sort=-committerdate", "--format=%(refname)%1f%(objectname)%1f%(committerdate:short)"}, patterns...)
+	output, err := gitCommand(repoPath, args...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref failed: %w, output: %s", err, output)
+	}
+
+	var lines [][]string
+	local := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 3 {
+			continue
+		}
+		lines = append(lines, fields)
+		if name, ok := strings.CutPrefix(fields[0], "refs/heads/"); ok {
+			local[name] = true
+		}
+	}
+
+	var refs []*branchRef
+	for _, fields := range lines {
+		if name, ok := strings.CutPrefix(fields[0], "refs/heads/"); ok {
+			refs = append(refs, &branchRef{Name: name, Revision: name, Commit: fields[1], LastCommit: fields[2]})
+		} else if name, ok := strings.CutPrefix(fields[0], "refs/remotes/"+TRACKED_REMOTE+"/"); ok && name != "HEAD" && !local[name] {
+			refs = append(refs, &branchRef{Name: name, Revision: fields[0], Commit: fields[1], LastCommit: fields[2]})
+		}
+	}
+	return refs, nil
+}
+
+// listBranches lists the names of the branches of the repository (see listBranchRefs) and records the revisions of
+// the remote branches in branchRevisions.
+//
+// Returns:
+//   - The branch names in alphabetical order.
+//   - An error if git failed.
+func (run *analysisRun) listBranches(repoPath string) ([]string, error) {
+	refs, err := run.listBranchRefs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	run.branchRevisions = make(map[string]string)
+	var branchNames []string
+	for _, ref := range refs {
+		branchNames = append(branchNames, ref.Name)
+		if ref.Revision != ref.Name {
+			run.branchRevisions[ref.Name] = ref.Revision
+		}
+	}
+	sort.Strings(branchNames)
+	return branchNames, nil
+}
+
+// branchRevision returns the revision git commands read a branch from: the remote-tracking ref of a remote branch
+// (see listBranches), or else the branch name.
+func (run *analysisRun) branchRevision(branchName string) string {
+	if revision, ok := run.branchRevisions[branchName]; ok {
+		return revision
+	}
+	return branchName
+}
+
+// isRepositoryClone reports whether the repository is a clone of a repository given by URL, see PrepareRepository.
+func isRepositoryClone(repoPath string) bool {
+	clonesDir, err := filepath.Abs(REPOSITORIES_DIRECTORY)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return false
+	}
+	relPath, err := filepath.Rel(clonesDir, absPath)
+	return err == nil && relPath != "." && !strings.HasPrefix(relPath, "..")
+}
+
+// branchSelected reports whether the branch is analyzed: the main branch always is, since the other branches
+// are analyzed since their merge-base with it, the others if they match any of the branch patterns.
+func (run *analysisRun) branchSelected(branchName string) bool {
+	if branchName == run.mainBranch {
+		return true
+	}
+	if run.mainBranchOnly {
+		return false
+	}
+	if len(run.branchPatterns) == 0 {
+		return true
+	}
+	for _, pattern := range run.branchPatterns {
+		if matched, _ := path.Match(pattern, branchName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// validateBranchPatterns checks the syntax of glob patterns selecting branches (e.g., release/*).
+func validateBranchPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return fmt.Errorf("branch patterns must not be empty")
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("branch pattern '%s' is not supported: %w", pattern, err)
+		}
+	}
+	return nil
+}
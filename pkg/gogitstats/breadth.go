@@ -0,0 +1,41 @@
+package gogitstats
+
+import "fmt"
+
+// addTouchedFiles records the breadth of a commit: the number of files it touched and, in the period of the
+// commit, which files the author touched.
+func addTouchedFiles(contribution *UserContribution, commit CommitRecord, period string) {
+	contribution.FilesTouched += len(commit.Files)
+	if period == "" || len(commit.Files) == 0 {
+		return
+	}
+
+	files, ok := contribution.TouchedFiles[period]
+	if !ok {
+		files = make(map[string]bool)
+		contribution.TouchedFiles[period] = files
+	}
+	for _, change := range commit.Files {
+		files[change.Path] = true
+	}
+}
+
+// filesPerCommit returns the average number of files touched by the commits of a contributor, many files per
+// commit indicate wide shallow changes (e.g., renames, formatting), few files deep focused ones.
+func filesPerCommit(contribution *UserContribution) string {
+	if contribution.CommitCount == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%.1f", float64(contribution.FilesTouched)/float64(contribution.CommitCount))
+}
+
+// distinctFiles returns the number of distinct files a contributor touched in all periods.
+func distinctFiles(contribution *UserContribution) int {
+	files := make(map[string]bool)
+	for _, touched := range contribution.TouchedFiles {
+		for file := range touched {
+			files[file] = true
+		}
+	}
+	return len(files)
+}
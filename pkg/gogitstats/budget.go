@@ -0,0 +1,174 @@
+package gogitstats
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// byteSizePattern matches sizes like 512MB, 1.5GiB, 2g or plain bytes.
+var byteSizePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([kmgt]?)(?:i?b)?$`)
+
+// byteSizeUnits are the multipliers of the units of byteSizePattern, powers of 1024 with or without "i".
+var byteSizeUnits = map[string]float64{"": 1, "k": 1 << 10, "m": 1 << 20, "g": 1 << 30, "t": 1 << 40}
+
+// ParseByteSize parses a size given in bytes or with a unit (e.g., 512MB, 1.5GiB, 2g), units are powers of
+// 1024 whether written KB or KiB.
+//
+// Returns:
+//   - The size in bytes.
+//   - An error if the size is not a non-negative number with an optional unit.
+func ParseByteSize(value string) (int64, error) {
+	match := byteSizePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	if match == nil {
+		return 0, fmt.Errorf("size '%s' is not supported, expected bytes or a number with a unit (e.g., 512MB, 2GB)", value)
+	}
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("size '%s' is not supported: %w", value, err)
+	}
+	size := number * byteSizeUnits[match[2]]
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("size '%s' is too large", value)
+	}
+	return int64(size), nil
+}
+
+// cacheEntry is a clone or a cache file below REPOSITORIES_DIRECTORY, evicted as a whole.
+type cacheEntry struct {
+	path     string
+	size     int64
+	lastUsed time.Time
+}
+
+// EnforceCacheSize removes the least recently used clones (see PrepareRepository) and cache files (analysis
+// caches, commit stores and API responses) below REPOSITORIES_DIRECTORY until their size is within maxBytes.
+// Entries used since the given time (i.e., by the current run) are kept, even if they exceed the limit alone.
+//
+// Returns:
+//   - An error if the directory could not be read or an entry could not be removed.
+func EnforceCacheSize(maxBytes int64, since time.Time) error {
+	if maxBytes <= 0 {
+		return nil
+	}
+	entries, err := listCacheEntries()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.size
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUsed.Before(entries[j].lastUsed)
+	})
+	for _, entry := range entries {
+		if total <= maxBytes {
+			return nil
+		}
+		if !entry.lastUsed.Before(since) {
+			break
+		}
+		if err := os.RemoveAll(entry.path); err != nil {
+			return fmt.Errorf("failed to evict %s: %w", entry.path, err)
+		}
+		total -= entry.size
+		log.Printf("Evicted %s (%s, last used %s) to keep %s within %s", entry.path, formatByteSize(entry.size), entry.lastUsed.Format("2006-01-02"), REPOSITORIES_DIRECTORY, formatByteSize(maxBytes))
+	}
+	if total > maxBytes {
+		log.Printf("%s takes %s, more than %s, with the clones and caches of this run only", REPOSITORIES_DIRECTORY, formatByteSize(total), formatByteSize(maxBytes))
+	}
+	return nil
+}
+
+// listCacheEntries lists the clones and the cache files below REPOSITORIES_DIRECTORY, a clone is used when
+// prepared for an analysis, a cache file when written.
+func listCacheEntries() ([]*cacheEntry, error) {
+	var entries []*cacheEntry
+	dirEntries, err := os.ReadDir(REPOSITORIES_DIRECTORY)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", REPOSITORIES_DIRECTORY, err)
+	}
+	for _, dirEntry := range dirEntries {
+		entryPath := filepath.Join(REPOSITORIES_DIRECTORY, dirEntry.Name())
+		if dirEntry.Name() == CACHE_DIRECTORY {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entryPath, err)
+		}
+		size, err := directorySize(entryPath)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &cacheEntry{path: entryPath, size: size, lastUsed: info.ModTime()})
+	}
+
+	cacheDir := filepath.Join(REPOSITORIES_DIRECTORY, CACHE_DIRECTORY)
+	err = filepath.WalkDir(cacheDir, func(filePath string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !dirEntry.Type().IsRegular() {
+			return nil
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, &cacheEntry{path: filePath, size: info.Size(), lastUsed: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", cacheDir, err)
+	}
+	return entries, nil
+}
+
+// directorySize sums the sizes of the regular files below a directory.
+func directorySize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(filePath string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if dirEntry.Type().IsRegular() {
+			info, err := dirEntry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	return size, nil
+}
+
+// formatByteSize formats a size in bytes with the largest unit of byteSizeUnits keeping it at least 1, e.g. 1.5 GB.
+func formatByteSize(size int64) string {
+	for _, unit := range []string{"t", "g", "m", "k"} {
+		if multiplier := byteSizeUnits[unit]; float64(size) >= multiplier {
+			return strconv.FormatFloat(float64(size)/multiplier, 'f', 1, 64) + " " + strings.ToUpper(unit) + "B"
+		}
+	}
+	return strconv.Itoa(int(size)) + " B"
+}
@@ -0,0 +1,143 @@
+package gogitstats
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const CACHE_DIRECTORY = "cache"
+
+// resumeCheckpointInterval is the minimum time between two saves of the analyzed branches while resuming is enabled,
+// since the whole cache is written each time.
+const resumeCheckpointInterval = 10 * time.Second
+
+// cacheFormatVersion must be increased whenever the layout or the meaning of the cached data changes.
+const cacheFormatVersion = 19
+
+type BranchCacheEntry struct {
+	Tip        string
+	MainTip    string
+	MergeBase  string
+	OptionsKey string
+	Report     *BranchReport
+}
+
+type AnalysisCache struct {
+	Version  int
+	Branches map[string]*BranchCacheEntry
+}
+
+// cacheFilePath returns the location of the analysis cache of the repository located at repoPath.
+//
+// The cache file lives in the cache directory next to the cloned repositories and is named after
+// the repository and a hash of its absolute path, so equally named checkouts do not collide.
+func cacheFilePath(repoPath string) (string, error) {
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve repository path %s: %w", repoPath, err)
+	}
+
+	hash := sha1.Sum([]byte(absPath))
+	fileName := fmt.Sprintf("%s-%s.json", filepath.Base(absPath), hex.EncodeToString(hash[:])[:12])
+	return filepath.Join(REPOSITORIES_DIRECTORY, CACHE_DIRECTORY, fileName), nil
+}
+
+func newAnalysisCache() *AnalysisCache {
+	return &AnalysisCache{
+		Version:  cacheFormatVersion,
+		Branches: make(map[string]*BranchCacheEntry),
+	}
+}
+
+// loadAnalysisCache reads the analysis cache from the given file.
+//
+// A missing, unreadable or outdated cache file is not an error; an empty cache is returned instead.
+func loadAnalysisCache(cachePath string) *AnalysisCache {
+	content, err := os.ReadFile(cachePath)
+	if err != nil {
+		return newAnalysisCache()
+	}
+
+	var stored AnalysisCache
+	if err := json.Unmarshal(content, &stored); err != nil || stored.Version != cacheFormatVersion || stored.Branches == nil {
+		return newAnalysisCache()
+	}
+
+	return &stored
+}
+
+// saveAnalysisCache writes the analysis cache to the given file, creating the cache directory if needed.
+func saveAnalysisCache(cachePath string, cache *AnalysisCache) error {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(cachePath), err)
+	}
+
+	// results of report-time enrichments (e.g., Jira, forecasts) depend on more than the history of the branch
+	stored := &AnalysisCache{Version: cache.Version, Branches: make(map[string]*BranchCacheEntry, len(cache.Branches))}
+	for branchName, entry := range cache.Branches {
+		storedEntry := *entry
+		if entry.Report != nil {
+			report := *entry.Report
+			report.Initiatives = nil
+			report.Forecast = nil
+			storedEntry.Report = &report
+		}
+		stored.Branches[branchName] = &storedEntry
+	}
+
+	content, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode analysis cache: %w", err)
+	}
+
+	return os.WriteFile(cachePath, content, 0644)
+}
+
+// checkpointAnalysisCache saves the results of the branches analyzed so far, see Options.Resume. Entries of the
+// previous cache not analyzed yet are kept, so a run interrupted again still finds them.
+//
+// Parameters:
+//   - cachePath: The cache file.
+//   - previous: The cache loaded when the analysis started.
+//   - updated: The entries of the branches analyzed so far.
+//   - commitStore: The parsed commits, saved as well.
+func checkpointAnalysisCache(cachePath string, previous *AnalysisCache, updated *AnalysisCache, commitStore *CommitStore) {
+	checkpoint := newAnalysisCache()
+	for branchName, entry := range previous.Branches {
+		checkpoint.Branches[branchName] = entry
+	}
+	for branchName, entry := range updated.Branches {
+		checkpoint.Branches[branchName] = entry
+	}
+	if err := saveAnalysisCache(cachePath, checkpoint); err != nil {
+		log.Printf("Failed to save the analyzed branches for resuming: %v", err)
+	}
+	if err := commitStore.save(); err != nil {
+		log.Printf("Failed to save commit store: %v", err)
+	}
+}
+
+// analysisOptionsKey describes all options which influence the content of a branch report.
+// Cached reports are only reused if they were produced with the same key.
+func (run *analysisRun) analysisOptionsKey(fileFilter string) string {
+	config, _ := json.Marshal(run.config)
+	return strings.Join([]string{run.mainBranch, run.groupBy, strconv.Itoa(run.focusDepth), run.pathBreakdown, strconv.Itoa(run.pathDepth), fileFilter, run.dateRangeKey(), strings.Join(run.gitRevRange, " "), strconv.FormatBool(run.excludeMerges), strconv.FormatBool(run.uniqueCommits), run.authorPatternsKey(), run.importsMode, strconv.Itoa(run.importMinFiles), run.squashedAuthorsKey(), string(config)}, "|")
+}
+
+// resolveRevision returns the commit SHA the given revision points to.
+func resolveRevision(repoPath string, revision string) (string, error) {
+	cmd := gitCommand(repoPath, "rev-parse", "--verify", "--quiet", revision+"^{commit}")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,167 @@
+package gogitstats
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// icsLineLength is the maximum length of a content line of iCalendar files in octets, longer lines are folded.
+const icsLineLength = 75
+
+// listActiveDays returns the number of commits of each author per day (of the author date) on all local branches.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - fileFilter: The pathspec limiting the commits, ignored if empty.
+//
+// Returns:
+//   - The commits by email and day (2006-01-02).
+//   - An error if git failed.
+func (run *analysisRun) listActiveDays(repoPath string, fileFilter string) (map[string]map[string]int, error) {
+	args := []string{"log", "--branches", "--format=%H%x1f%ae%x1f%ad", "--date=short"}
+	if run.excludeMerges {
+		args = append(args, "--no-merges")
+	}
+	args = append(args, run.gitDateRange...)
+	args = append(args, pathspecArgs(fileFilter)...)
+	cmd := gitCommand(repoPath, args...)
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git log output: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start git log: %w", err)
+	}
+
+	activeDays := make(map[string]map[string]int)
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\x1f")
+		if len(fields) != 3 || run.config.excludesCommit(fields[0]) {
+			continue
+		}
+		email := run.config.canonicalEmail(fields[1])
+		if !run.authorSelected(email) {
+			continue
+		}
+		days, ok := activeDays[email]
+		if !ok {
+			days = make(map[string]int)
+			activeDays[email] = days
+		}
+		days[fields[2]]++
+	}
+	if err := scanner.Err(); err != nil {
+		cmd.Wait()
+		return nil, fmt.Errorf("failed to read git log output: %w", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	return activeDays, nil
+}
+
+// writeCalendars writes an iCalendar file per contributor into the given directory, every active day of the
+// contributor is an all-day event, so the files can be subscribed to or overlaid on sprint calendars.
+//
+// Returns:
+//   - The number of written calendars.
+//   - An error if git failed or a file could not be written.
+func (run *analysisRun) writeCalendars(repoPath string, repoName string, directory string, generatedOn time.Time) (int, error) {
+	activeDays, err := run.listActiveDays(repoPath, run.fileFilter)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create directory %s: %w", directory, err)
+	}
+	for email, days := range activeDays {
+		contributor := run.profile.displayEmail(email)
+		calendar := run.generateICSCalendar(repoName, contributor, days, generatedOn)
+		calendarPath := filepath.Join(directory, sanitizeDirectoryName(contributor)+".ics")
+		if err := os.WriteFile(calendarPath, []byte(calendar), 0644); err != nil {
+			return 0, fmt.Errorf("failed to write calendar %s: %w", calendarPath, err)
+		}
+	}
+	return len(activeDays), nil
+}
+
+// generateICSCalendar generates an iCalendar (RFC 5545) document with an all-day event per active day.
+//
+// Parameters:
+//   - repoName: The name of the repository.
+//   - contributor: The contributor as shown in the report (email or pseudonym).
+//   - days: The number of commits per day (2006-01-02).
+//   - generatedOn: The time stamp of the events.
+func (run *analysisRun) generateICSCalendar(repoName string, contributor string, days map[string]int, generatedOn time.Time) string {
+	dates := make([]string, 0, len(days))
+	for date := range days {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	// stable identifiers, so subscribed calendars update events instead of duplicating them
+	hash := sha1.Sum([]byte(repoName + "\n" + contributor))
+	uidSuffix := hex.EncodeToString(hash[:])[:16]
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//gogitstats//Contribution Calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:" + escapeICSText(fmt.Sprintf("%s: %s", repoName, contributor)),
+	}
+	for _, date := range dates {
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			continue
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%s-%s@gogitstats", day.Format("20060102"), uidSuffix),
+			"DTSTAMP:"+generatedOn.UTC().Format("20060102T150405Z"),
+			"DTSTART;VALUE=DATE:"+day.Format("20060102"),
+			"DTEND;VALUE=DATE:"+day.AddDate(0, 0, 1).Format("20060102"),
+			"SUMMARY:"+escapeICSText(run.translate("%d commits", days[date])+" ("+repoName+")"),
+			"TRANSP:TRANSPARENT",
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	var calendar strings.Builder
+	for _, line := range lines {
+		calendar.WriteString(foldICSLine(line))
+		calendar.WriteString("\r\n")
+	}
+	return calendar.String()
+}
+
+// escapeICSText escapes a value of the type TEXT of iCalendar.
+func escapeICSText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(text)
+}
+
+// foldICSLine splits content lines longer than icsLineLength octets, continuation lines start with a space.
+func foldICSLine(line string) string {
+	var folded strings.Builder
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > icsLineLength {
+			folded.WriteString("\r\n ")
+			length = 1
+		}
+		folded.WriteRune(r)
+		length += size
+	}
+	return folded.String()
+}
@@ -0,0 +1,200 @@
+package gogitstats
+
+import (
+	"sort"
+)
+
+// maxFlaggedCommits limits the number of commits listed per branch and category.
+const maxFlaggedCommits = 100
+
+const CATEGORY_SECURITY = "security"
+const CATEGORY_DEPENDENCIES = "dependencies"
+const CATEGORY_CI = "ci"
+
+// defaultDependencyManifests lists the manifests and lock files of common package managers.
+var defaultDependencyManifests = []string{
+	"go.mod", "go.sum",
+	"package.json", "package-lock.json", "yarn.lock", "pnpm-lock.yaml",
+	"requirements*.txt", "Pipfile", "Pipfile.lock", "pyproject.toml", "poetry.lock",
+	"pom.xml", "build.gradle", "build.gradle.kts",
+	"Cargo.toml", "Cargo.lock",
+	"Gemfile", "Gemfile.lock",
+	"composer.json", "composer.lock",
+	"*.csproj", "packages.config",
+}
+
+// defaultCIPaths lists the configuration files of common CI/CD systems.
+var defaultCIPaths = []string{
+	".github/workflows/", ".github/actions/",
+	".gitlab-ci.yml", ".gitlab/ci/",
+	"Jenkinsfile", ".circleci/", ".travis.yml",
+	"azure-pipelines.yml", ".azure-pipelines/",
+	"bitbucket-pipelines.yml", ".buildkite/", ".drone.yml",
+}
+
+// PathCategory describes a group of paths whose changes are reported separately.
+type PathCategory struct {
+	Key         string
+	Title       string
+	Patterns    []string
+	ListCommits bool
+}
+
+type CategoryContribution struct {
+	Email       string
+	CommitCount int
+	LinesEdited int
+	LastChange  string
+	Timeline    map[string]int // Period: commit count
+}
+
+type FlaggedCommit struct {
+	Hash    string
+	Date    string
+	Email   string
+	Subject string
+	Paths   []string
+}
+
+type CategoryReport struct {
+	Key           string
+	CommitCount   int
+	LinesEdited   int
+	Contributors  map[string]*CategoryContribution
+	Timeline      map[string]int // Period: commit count
+	Commits       []*FlaggedCommit
+	CommitsListed bool
+}
+
+// pathCategories returns the path categories to report on, based on the configuration.
+func (config *Config) pathCategories() []PathCategory {
+	var categories []PathCategory
+
+	if len(config.SecurityPaths) > 0 {
+		categories = append(categories, PathCategory{
+			Key:         CATEGORY_SECURITY,
+			Title:       "Security-relevant changes",
+			Patterns:    config.SecurityPaths,
+			ListCommits: true,
+		})
+	}
+
+	dependencyManifests := config.DependencyManifests
+	if dependencyManifests == nil {
+		dependencyManifests = defaultDependencyManifests
+	}
+	if len(dependencyManifests) > 0 {
+		categories = append(categories, PathCategory{
+			Key:      CATEGORY_DEPENDENCIES,
+			Title:    "Dependency updates",
+			Patterns: dependencyManifests,
+		})
+	}
+
+	ciPaths := config.CIPaths
+	if ciPaths == nil {
+		ciPaths = defaultCIPaths
+	}
+	if len(ciPaths) > 0 {
+		categories = append(categories, PathCategory{
+			Key:      CATEGORY_CI,
+			Title:    "CI/pipeline configuration changes",
+			Patterns: ciPaths,
+		})
+	}
+
+	return categories
+}
+
+// matchingPaths returns the paths of the commit matching any of the category patterns
+// together with the number of lines edited in them.
+func (category PathCategory) matchingPaths(commit CommitRecord) ([]string, int) {
+	var paths []string
+	linesEdited := 0
+	for _, change := range commit.Files {
+		for _, pattern := range category.Patterns {
+			if matchPathPattern(pattern, change.Path) {
+				paths = append(paths, change.Path)
+				linesEdited += change.Added + change.Removed
+				break
+			}
+		}
+	}
+	return paths, linesEdited
+}
+
+// addCategoryCommit accounts a commit for every category of paths it touches.
+func addCategoryCommit(report *BranchReport, categories []PathCategory, commit CommitRecord, period string) {
+	for _, category := range categories {
+		paths, linesEdited := category.matchingPaths(commit)
+		if len(paths) == 0 {
+			continue
+		}
+
+		categoryReport, ok := report.Categories[category.Key]
+		if !ok {
+			categoryReport = &CategoryReport{
+				Key:          category.Key,
+				Contributors: make(map[string]*CategoryContribution),
+				Timeline:     make(map[string]int),
+			}
+			report.Categories[category.Key] = categoryReport
+		}
+		categoryReport.CommitCount++
+		categoryReport.LinesEdited += linesEdited
+		if period != "" {
+			categoryReport.Timeline[period]++
+		}
+
+		contribution, ok := categoryReport.Contributors[commit.Email]
+		if !ok {
+			contribution = &CategoryContribution{Email: commit.Email, Timeline: make(map[string]int)}
+			categoryReport.Contributors[commit.Email] = contribution
+		}
+		contribution.CommitCount++
+		contribution.LinesEdited += linesEdited
+		if period != "" {
+			contribution.Timeline[period]++
+		}
+		if commit.Date > contribution.LastChange {
+			contribution.LastChange = commit.Date
+		}
+
+		if category.ListCommits {
+			categoryReport.CommitsListed = true
+			if len(categoryReport.Commits) < maxFlaggedCommits {
+				categoryReport.Commits = append(categoryReport.Commits, &FlaggedCommit{
+					Hash:    commit.Hash,
+					Date:    commit.Date,
+					Email:   commit.Email,
+					Subject: commit.Subject,
+					Paths:   paths,
+				})
+			}
+		}
+	}
+}
+
+// sortCategoryContributions orders contributors of a category by commit count, descending.
+func sortCategoryContributions(contributions map[string]*CategoryContribution) []*CategoryContribution {
+	sorted := make([]*CategoryContribution, 0, len(contributions))
+	for _, c := range contributions {
+		sorted = append(sorted, c)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].CommitCount != sorted[j].CommitCount {
+			return sorted[i].CommitCount > sorted[j].CommitCount
+		}
+		return sorted[i].Email < sorted[j].Email
+	})
+	return sorted
+}
+
+// sortedPeriods returns the periods of a timeline in chronological order.
+func sortedPeriods(timeline map[string]int) []string {
+	periods := make([]string, 0, len(timeline))
+	for period := range timeline {
+		periods = append(periods, period)
+	}
+	return sortPeriods(periods)
+}
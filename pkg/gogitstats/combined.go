@@ -0,0 +1,383 @@
+package gogitstats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RepositorySummary is the section of a repository in a combined report, it covers the main branch of the repository.
+type RepositorySummary struct {
+	Name          string
+	MainBranch    string
+	BranchCount   int
+	CommitCount   int
+	LinesEdited   int
+	Contributions []*UserContribution // contributions to the main branch, most commits first
+}
+
+// CrossRepositoryContribution sums up the contributions of an author to the main branches of all repositories.
+type CrossRepositoryContribution struct {
+	Email        string
+	CommitCount  int
+	LinesAdded   int
+	LinesRemoved int
+	LinesEdited  int
+	Repositories map[string]int // Repository: commit count
+}
+
+// CombinedReport gives an overview of several repositories analyzed in one run (e.g., the services of a product).
+type CombinedReport struct {
+	Repositories []*RepositorySummary
+	Contributors []*CrossRepositoryContribution // most commits first
+	StartedOn    time.Time
+
+	analyzer *Analyzer
+	run      *analysisRun
+}
+
+// Combine combines the reports of several repositories into a report with a section per repository and
+// the contributions of each author summed up across the repositories.
+//
+// Only the main branch of each repository is taken into account, so work merged into it is not counted twice.
+func (analyzer *Analyzer) Combine(reports []*Report) *CombinedReport {
+	startedOn := time.Now()
+	summaries := make([]*RepositorySummary, 0, len(reports))
+	for _, report := range reports {
+		summaries = append(summaries, summarizeRepository(report))
+		if report.StartedOn.Before(startedOn) {
+			startedOn = report.StartedOn
+		}
+	}
+	return analyzer.combineSummaries(summaries, startedOn)
+}
+
+// summarizeRepository summarizes the contributions to the main branch of the report of a repository.
+func summarizeRepository(report *Report) *RepositorySummary {
+	summary := &RepositorySummary{Name: report.RepoName, MainBranch: report.MainBranch, BranchCount: len(report.Branches)}
+	mainReport, ok := report.Branches[report.MainBranch]
+	if !ok {
+		log.Printf("Main branch '%s' of '%s' has not been analyzed, the repository is not part of the combined totals", report.MainBranch, report.RepoName)
+		return summary
+	}
+
+	for _, contribution := range mainReport.Contributions {
+		summary.Contributions = append(summary.Contributions, contribution)
+		summary.CommitCount += contribution.CommitCount
+		summary.LinesEdited += contribution.LinesEdited
+	}
+	sort.Slice(summary.Contributions, func(i, j int) bool {
+		if summary.Contributions[i].CommitCount != summary.Contributions[j].CommitCount {
+			return summary.Contributions[i].CommitCount > summary.Contributions[j].CommitCount
+		}
+		return summary.Contributions[i].Email < summary.Contributions[j].Email
+	})
+	return summary
+}
+
+// combineSummaries sums up the contributions of each author across the summaries of the repositories.
+func (analyzer *Analyzer) combineSummaries(summaries []*RepositorySummary, startedOn time.Time) *CombinedReport {
+	combined := &CombinedReport{Repositories: summaries, StartedOn: startedOn, analyzer: analyzer, run: analyzer.newRun()}
+	contributors := make(map[string]*CrossRepositoryContribution)
+
+	for _, summary := range summaries {
+		for _, contribution := range summary.Contributions {
+			contributor, ok := contributors[contribution.Email]
+			if !ok {
+				contributor = &CrossRepositoryContribution{Email: contribution.Email, Repositories: make(map[string]int)}
+				contributors[contribution.Email] = contributor
+			}
+			contributor.CommitCount += contribution.CommitCount
+			contributor.LinesAdded += contribution.LinesAdded
+			contributor.LinesRemoved += contribution.LinesRemoved
+			contributor.LinesEdited += contribution.LinesEdited
+			contributor.Repositories[summary.Name] += contribution.CommitCount
+		}
+	}
+
+	for _, contributor := range contributors {
+		combined.Contributors = append(combined.Contributors, contributor)
+	}
+	sort.Slice(combined.Contributors, func(i, j int) bool {
+		if combined.Contributors[i].CommitCount != combined.Contributors[j].CommitCount {
+			return combined.Contributors[i].CommitCount > combined.Contributors[j].CommitCount
+		}
+		return combined.Contributors[i].Email < combined.Contributors[j].Email
+	})
+
+	return combined
+}
+
+// Render generates the combined report in the given format (see REPORT_FORMATS).
+func (combined *CombinedReport) Render(format string) (string, error) {
+	switch format {
+	case REPORT_FORMAT_HTML:
+		return combined.run.generateHTMLCombinedReport(combined)
+	case REPORT_FORMAT_JSON:
+		return combined.run.generateJSONCombinedReport(combined)
+	case REPORT_FORMAT_CSV:
+		return combined.run.generateCSVCombinedReport(combined)
+	case REPORT_FORMAT_MARKDOWN:
+		return combined.run.generateMarkdownCombinedReport(combined), nil
+	}
+	return "", fmt.Errorf("format '%s' is not supported, expected any of: %s", format, strings.Join(REPORT_FORMATS, ", "))
+}
+
+// Write writes the combined report in the format of the analyzer, see Options.Output.
+//
+// Returns:
+//   - The name of the written report file.
+//   - An error if the report could not be generated or written.
+func (combined *CombinedReport) Write() (string, error) {
+	format := combined.analyzer.options.Format
+	content, err := combined.Render(format)
+	if err != nil {
+		return "", fmt.Errorf("error generating combined %s report: %w", strings.ToUpper(format), err)
+	}
+
+	filename, err := combined.analyzer.outputPath("report_combined", reportFileExtension(format), time.Now())
+	if err != nil {
+		return "", err
+	}
+	if err := writeOutput(filename, content); err != nil {
+		return "", fmt.Errorf("error writing combined %s report to file: %w", strings.ToUpper(format), err)
+	}
+
+	log.Printf("Combined %s report generated: %s\n", strings.ToUpper(format), filename)
+	return filename, nil
+}
+
+// repositoryNames returns the names of the repositories in the order they were analyzed.
+func (combined *CombinedReport) repositoryNames() []string {
+	names := make([]string, 0, len(combined.Repositories))
+	for _, repository := range combined.Repositories {
+		names = append(names, repository.Name)
+	}
+	return names
+}
+
+// generateJSONCombinedReport serializes the combined report, the output profile applies as for generateJSONReport.
+func (run *analysisRun) generateJSONCombinedReport(combined *CombinedReport) (string, error) {
+	content, err := json.Marshal(combined)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	var document any
+	if err := json.Unmarshal(content, &document); err != nil {
+		return "", fmt.Errorf("failed to encode report: %w", err)
+	}
+	if run.profile.AnonymizeEmails || run.profile.HidePaths {
+		document = run.applyOutputProfile(document)
+	}
+
+	content, err = json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode report: %w", err)
+	}
+	return string(content), nil
+}
+
+// generateCSVCombinedReport writes one row per contributor with the totals across all repositories (as far as the
+// columns are selected) followed by one column per repository holding the commits to it.
+func (run *analysisRun) generateCSVCombinedReport(combined *CombinedReport) (string, error) {
+	columns := make(map[string]bool)
+	for _, column := range run.profile.reportColumns(run.columns) {
+		columns[column] = true
+	}
+	repositories := combined.repositoryNames()
+
+	header := []string{"Email", "Repositories"}
+	if columns["commits"] {
+		header = append(header, "Commit Count")
+	}
+	if columns["added"] {
+		header = append(header, "Lines Added")
+	}
+	if columns["removed"] {
+		header = append(header, "Lines Removed")
+	}
+	if columns["edited"] {
+		header = append(header, "Lines Edited")
+	}
+	for _, repository := range repositories {
+		header = append(header, "Commits: "+repository)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(header); err != nil {
+		return "", err
+	}
+
+	for _, contributor := range combined.Contributors {
+		row := []string{run.profile.displayEmail(contributor.Email), strconv.Itoa(len(contributor.Repositories))}
+		if columns["commits"] {
+			row = append(row, strconv.Itoa(contributor.CommitCount))
+		}
+		if columns["added"] {
+			row = append(row, strconv.Itoa(contributor.LinesAdded))
+		}
+		if columns["removed"] {
+			row = append(row, strconv.Itoa(contributor.LinesRemoved))
+		}
+		if columns["edited"] {
+			row = append(row, strconv.Itoa(contributor.LinesEdited))
+		}
+		for _, repository := range repositories {
+			row = append(row, strconv.Itoa(contributor.Repositories[repository]))
+		}
+		if err := writer.Write(row); err != nil {
+			return "", err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (run *analysisRun) generateHTMLCombinedReport(combined *CombinedReport) (string, error) {
+	tmpl := `
+<!DOCTYPE html>
+<html lang="{{.Data.Language}}" data-bs-theme="{{.Data.Theme}}">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>{{t "Combined Git Contribution Report"}}</title>
+{{bootstrapStyles}}
+<style>
+	.fixed-width {
+		width: 150px;
+	}
+</style>
+</head>
+<body>
+
+<main class="container mt-4">
+
+<header>
+<h1 class="h4">{{t "Combined report of %d repositories" (len .Report.Repositories)}}</h1>
+{{if or .Data.Since .Data.Until}}<p class="h4"> {{t "Period:"}} <span class="badge text-bg-info">{{if .Data.Since}}{{t "since"}} {{.Data.Since}}{{end}} {{if .Data.Until}}{{t "until"}} {{.Data.Until}}{{end}}</span></p>{{end}}
+{{if .Data.RevRange}}<p class="h4"> {{t "Revision range:"}} <span class="badge text-bg-info">{{.Data.RevRange}}</span></p>{{end}}
+<p>{{t "Contributions to the main branch of each repository."}}</p>
+</header>
+
+<section aria-labelledby="repositories">
+<h2 class="h4" id="repositories">{{t "Repositories"}}</h2>
+<table class="table {{.Data.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Repository"}}</th>
+			<th scope="col" class="fixed-width">{{t "Main Branch"}}</th>
+			<th scope="col" class="fixed-width">{{t "Branches"}}</th>
+			<th scope="col" class="fixed-width">{{t "Contributors"}}</th>
+			<th scope="col" class="fixed-width">{{t "Commits"}}</th>
+			{{if .Data.ShowLines}}<th scope="col">{{t "Lines Edited"}}</th>{{end}}
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Report.Repositories}}
+		<tr>
+			<td><a href="#repository-{{.Name}}">{{.Name}}</a></td>
+			<td>{{.MainBranch}}</td>
+			<td>{{.BranchCount}}</td>
+			<td>{{len .Contributions}}</td>
+			<td>{{.CommitCount}}</td>
+			{{if $.Data.ShowLines}}<td>{{.LinesEdited}}</td>{{end}}
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</section>
+
+<section aria-labelledby="contributors">
+<h2 class="h4" id="contributors">{{t "Contributions across repositories"}}</h2>
+<table class="table {{.Data.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			{{if index .Data.Columns "email"}}<th scope="col" class="fixed-width">{{t "Email"}}</th>{{end}}
+			{{if index .Data.Columns "commits"}}<th scope="col" class="fixed-width">{{t "Commit Count"}}</th>{{end}}
+			{{if index .Data.Columns "added"}}<th scope="col">{{t "Lines Added"}}</th>{{end}}
+			{{if index .Data.Columns "removed"}}<th scope="col">{{t "Lines Removed"}}</th>{{end}}
+			{{if index .Data.Columns "edited"}}<th scope="col">{{t "Lines Edited"}}</th>{{end}}
+			<th scope="col">{{t "Commits by repository"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Report.Contributors}}
+		<tr>
+			{{if index $.Data.Columns "email"}}<td>{{email .Email}}</td>{{end}}
+			{{if index $.Data.Columns "commits"}}<td>{{.CommitCount}}</td>{{end}}
+			{{if index $.Data.Columns "added"}}<td>{{.LinesAdded}}</td>{{end}}
+			{{if index $.Data.Columns "removed"}}<td>{{.LinesRemoved}}</td>{{end}}
+			{{if index $.Data.Columns "edited"}}<td>{{.LinesEdited}}</td>{{end}}
+			<td>{{$repositories := .Repositories}}{{range $name := $.RepositoryNames}}{{with index $repositories $name}}{{$name}}: {{.}}<br>{{end}}{{end}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</section>
+
+{{range .Report.Repositories}}
+<section aria-labelledby="repository-{{.Name}}">
+<h2 class="h4" id="repository-{{.Name}}"> {{t "Repository name:"}} <span class="badge text-bg-success">{{.Name}}</span> <span class="badge text-bg-warning">{{.MainBranch}}</span></h2>
+<table class="table {{$.Data.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			{{if index $.Data.Columns "email"}}<th scope="col" class="fixed-width">{{t "Email"}}</th>{{end}}
+			{{if index $.Data.Columns "commits"}}<th scope="col" class="fixed-width">{{t "Commit Count"}}</th>{{end}}
+			{{if index $.Data.Columns "added"}}<th scope="col">{{t "Lines Added"}}</th>{{end}}
+			{{if index $.Data.Columns "removed"}}<th scope="col">{{t "Lines Removed"}}</th>{{end}}
+			{{if index $.Data.Columns "edited"}}<th scope="col">{{t "Lines Edited"}}</th>{{end}}
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Contributions}}
+		<tr>
+			{{if index $.Data.Columns "email"}}<td>{{email .Email}}</td>{{end}}
+			{{if index $.Data.Columns "commits"}}<td>{{.CommitCount}}</td>{{end}}
+			{{if index $.Data.Columns "added"}}<td>{{.LinesAdded}}</td>{{end}}
+			{{if index $.Data.Columns "removed"}}<td>{{.LinesRemoved}}</td>{{end}}
+			{{if index $.Data.Columns "edited"}}<td>{{.LinesEdited}}</td>{{end}}
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</section>
+{{end}}
+</main>
+</body>
+</html>
+`
+	t, err := template.New("combined").Funcs(run.reportTemplateFuncs()).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	data := struct {
+		Report          *CombinedReport
+		Data            ReportData
+		RepositoryNames []string
+	}{
+		Report:          combined,
+		Data:            run.newReportData(map[string]*BranchReport{}, "", ""),
+		RepositoryNames: combined.repositoryNames(),
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
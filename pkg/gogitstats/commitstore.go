@@ -0,0 +1,344 @@
+package gogitstats
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// CommitStore caches the parsed commits (header and numstat) of a repository by hash, so an analysis only
+// runs the expensive `git log --numstat` for commits it has not seen before, even if a branch has changed.
+//
+// The store is a file of JSON lines next to the analysis cache: a header followed by one commit per line.
+// New commits are appended, the file is rewritten only if the header does not match (e.g., another pathspec).
+// Beyond its memory budget, commits are spilled to the file and read from it when needed, so the memory
+// taken by the store is bounded however large the repository is.
+type CommitStore struct {
+	path        string
+	fileFilter  string
+	mutex       sync.Mutex
+	commits     map[string]*CommitRecord // commits held in memory
+	offsets     map[string]int64         // offsets of the lines of the commits in the store file
+	added       []*CommitRecord
+	rewrite     bool
+	memoryBytes int64 // estimated size of the commits held in memory
+	// memoryBudget is the estimated size of the commits held in memory at most, see Options.MaxMemory
+	memoryBudget int64
+}
+
+// defaultCommitStoreMemoryBudget bounds the commits a commit store holds in memory unless Options.MaxMemory is set.
+const defaultCommitStoreMemoryBudget int64 = 256 << 20
+
+// commitStoreHeader is the first line of the commit store file. The numstat of a commit is limited to the
+// files matching the pathspec, so a store is only valid for the pathspec it has been written with.
+type commitStoreHeader struct {
+	Version    int
+	FileFilter string
+}
+
+// commitStoreFilePath returns the location of the commit store of the repository, next to its analysis cache.
+func commitStoreFilePath(repoPath string) (string, error) {
+	cachePath, err := cacheFilePath(repoPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(cachePath, ".json") + "-commits.jsonl", nil
+}
+
+// openCommitStore reads the commit store from the given file, holding commits up to the estimated size memoryBudget
+// in memory.
+//
+// A missing, unreadable or outdated store file is not an error; an empty store is returned instead, which
+// replaces the file when saved.
+func openCommitStore(storePath string, fileFilter string, memoryBudget int64) *CommitStore {
+	store := &CommitStore{path: storePath, fileFilter: fileFilter, commits: make(map[string]*CommitRecord), offsets: make(map[string]int64), rewrite: true, memoryBudget: memoryBudget}
+
+	file, err := os.Open(storePath)
+	if err != nil {
+		return store
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+	if !scanner.Scan() {
+		return store
+	}
+	var header commitStoreHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Version != cacheFormatVersion || header.FileFilter != fileFilter {
+		return store
+	}
+
+	// each line of the store ends with a single newline
+	offset := int64(len(scanner.Bytes()) + 1)
+	for scanner.Scan() {
+		var commit CommitRecord
+		// a line truncated by an interrupted run is skipped, the commit is parsed again
+		if err := json.Unmarshal(scanner.Bytes(), &commit); err == nil && commit.Hash != "" {
+			store.offsets[commit.Hash] = offset
+			if store.memoryBytes < store.memoryBudget {
+				store.commits[commit.Hash] = &commit
+				store.memoryBytes += commitRecordSize(&commit)
+			}
+		}
+		offset += int64(len(scanner.Bytes()) + 1)
+	}
+	if err := scanner.Err(); err != nil {
+		log.Printf("Failed to read commit store %s: %v", storePath, err)
+		store.commits = make(map[string]*CommitRecord)
+		store.offsets = make(map[string]int64)
+		store.memoryBytes = 0
+		return store
+	}
+
+	store.rewrite = false
+	return store
+}
+
+// save appends the commits parsed since the store has been opened to the store file, creating the cache
+// directory if needed.
+func (store *CommitStore) save() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return store.saveLocked()
+}
+
+// openTemporaryCommitStore creates an empty commit store in a temporary directory, which the caller removes.
+func openTemporaryCommitStore(fileFilter string, memoryBudget int64) (*CommitStore, string, error) {
+	directory, err := os.MkdirTemp("", "gogitstats-commits-*")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create temporary commit store: %w", err)
+	}
+	return openCommitStore(filepath.Join(directory, "commits.jsonl"), fileFilter, memoryBudget), directory, nil
+}
+
+// saveLocked saves the store like save, the caller holds the mutex of the store.
+func (store *CommitStore) saveLocked() error {
+	if len(store.added) == 0 && !store.rewrite {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(store.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(store.path), err)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	commits := store.added
+	if store.rewrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		commits = make([]*CommitRecord, 0, len(store.commits))
+		for _, commit := range store.commits {
+			commits = append(commits, commit)
+		}
+	}
+	file, err := os.OpenFile(store.path, flags, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open commit store %s: %w", store.path, err)
+	}
+	defer file.Close()
+
+	var offset int64
+	if !store.rewrite {
+		info, err := file.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to read commit store %s: %w", store.path, err)
+		}
+		offset = info.Size()
+	}
+	writer := bufio.NewWriter(file)
+	writeLine := func(value any) error {
+		line, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode commit store: %w", err)
+		}
+		line = append(line, '\n')
+		if _, err := writer.Write(line); err != nil {
+			return fmt.Errorf("failed to write commit store %s: %w", store.path, err)
+		}
+		offset += int64(len(line))
+		return nil
+	}
+	if store.rewrite {
+		if err := writeLine(commitStoreHeader{Version: cacheFormatVersion, FileFilter: store.fileFilter}); err != nil {
+			return err
+		}
+	}
+	for _, commit := range commits {
+		lineOffset := offset
+		if err := writeLine(commit); err != nil {
+			return err
+		}
+		store.offsets[commit.Hash] = lineOffset
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write commit store %s: %w", store.path, err)
+	}
+
+	store.added = nil
+	store.rewrite = false
+	return nil
+}
+
+// spillLocked writes the commits held in memory to the store file and drops them from memory, they are read from
+// the file when needed. The caller holds the mutex of the store.
+func (store *CommitStore) spillLocked() error {
+	if err := store.saveLocked(); err != nil {
+		return err
+	}
+	for hash, commit := range store.commits {
+		if _, ok := store.offsets[hash]; ok {
+			delete(store.commits, hash)
+			store.memoryBytes -= commitRecordSize(commit)
+		}
+	}
+	return nil
+}
+
+// readStoredCommit reads the commit whose line starts at the given offset of the store file.
+func readStoredCommit(file *os.File, offset int64) (*CommitRecord, error) {
+	line, err := bufio.NewReader(io.NewSectionReader(file, offset, maxLogLineSize)).ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit store %s: %w", file.Name(), err)
+	}
+	var commit CommitRecord
+	if err := json.Unmarshal(line, &commit); err != nil {
+		return nil, fmt.Errorf("failed to decode commit store %s: %w", file.Name(), err)
+	}
+	return &commit, nil
+}
+
+// commitRecordSize estimates the memory held by a parsed commit in bytes.
+func commitRecordSize(commit *CommitRecord) int64 {
+	size := 128 + len(commit.Hash) + len(commit.Email) + len(commit.Date) + len(commit.Subject)
+	for _, trailer := range commit.Trailers {
+		size += 16 + len(trailer)
+	}
+	for _, change := range commit.Files {
+		size += 48 + len(change.Path)
+	}
+	return int64(size)
+}
+
+// streamCommits hands the commits of the revision range over to handleBatch like streamGitLog, taking the
+// commits known to the store from it and parsing only the others.
+//
+// Parameters:
+//   - run: The analysis, which limits the commits (e.g., to its period).
+//   - repoPath: The path to the Git repository.
+//   - logRange: The revision range, e.g. a branch or "<merge-base>..<branch>".
+//   - fileFilter: The pathspec limiting the commits and files, ignored if empty.
+//   - batchSize: The maximum number of commits handed over at once.
+//   - handleBatch: The function aggregating a batch of commits. The batch must not be retained.
+//   - stats: Counts the git log output skipped while parsing, see streamGitLog.
+//
+// Returns:
+//   - An error if git failed.
+func (store *CommitStore) streamCommits(run *analysisRun, repoPath string, logRange string, fileFilter string, batchSize int, handleBatch func([]CommitRecord), stats *LogParseStats) error {
+	output, err := gitCommand(repoPath, append([]string{"log", "--format=%H"}, run.gitLogLimits(logRange, fileFilter)...)...).Output()
+	if err != nil {
+		return fmt.Errorf("git log failed: %w", err)
+	}
+	hashes := strings.Fields(string(output))
+
+	var missing []string
+	store.mutex.Lock()
+	for _, hash := range hashes {
+		if _, ok := store.offsets[hash]; ok {
+			continue
+		}
+		if _, ok := store.commits[hash]; !ok {
+			missing = append(missing, hash)
+		}
+	}
+	store.mutex.Unlock()
+
+	if len(missing) > 0 {
+		args := []string{"log", "--no-walk=unsorted", "--stdin", gitLogFormat, gitLogDateFormat, "--numstat"}
+		args = append(args, pathspecArgs(fileFilter)...)
+		cmd := gitCommand(repoPath, args...)
+		cmd.Stdin = strings.NewReader(strings.Join(missing, "\n") + "\n")
+		var spillErr error
+		err := run.streamGitLog(cmd, batchSize, func(commits []CommitRecord) {
+			store.mutex.Lock()
+			defer store.mutex.Unlock()
+			for _, commit := range commits {
+				if _, ok := store.commits[commit.Hash]; ok {
+					continue
+				}
+				if _, ok := store.offsets[commit.Hash]; ok {
+					continue
+				}
+				// the batch is reused by streamGitLog
+				stored := commit
+				stored.Trailers = append([]string(nil), commit.Trailers...)
+				stored.Files = append([]FileChange(nil), commit.Files...)
+				store.commits[commit.Hash] = &stored
+				store.added = append(store.added, &stored)
+				store.memoryBytes += commitRecordSize(&stored)
+			}
+			if store.memoryBytes > store.memoryBudget {
+				if err := store.spillLocked(); err != nil {
+					spillErr = err
+				}
+			}
+		}, stats)
+		if err != nil {
+			return err
+		}
+		if spillErr != nil {
+			return spillErr
+		}
+	}
+	if len(hashes) > 0 {
+		log.Printf("Parsed %d new commits of range '%s', %d taken from the commit store", len(missing), logRange, len(hashes)-len(missing))
+	}
+
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	batch := make([]CommitRecord, 0, batchSize)
+	var spilled *os.File
+	defer func() {
+		if spilled != nil {
+			spilled.Close()
+		}
+	}()
+	for _, hash := range hashes {
+		store.mutex.Lock()
+		commit, ok := store.commits[hash]
+		offset, stored := store.offsets[hash]
+		store.mutex.Unlock()
+		if !ok && stored {
+			// spilled to the store file, see CommitStore.memoryBudget
+			if spilled == nil {
+				file, err := os.Open(store.path)
+				if err != nil {
+					return fmt.Errorf("failed to read commit store %s: %w", store.path, err)
+				}
+				spilled = file
+			}
+			var err error
+			if commit, err = readStoredCommit(spilled, offset); err != nil {
+				return err
+			}
+			ok = true
+		}
+		if !ok {
+			continue
+		}
+		batch = append(batch, *commit)
+		if len(batch) == batchSize {
+			handleBatch(batch)
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		handleBatch(batch)
+	}
+	return nil
+}
@@ -0,0 +1,334 @@
+package gogitstats
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+type CompareSide struct {
+	Name          string
+	Path          string
+	Branch        string
+	CommitCount   int
+	UniqueCount   int
+	Contributions map[string]*UserContribution // contributions of all commits
+	Unique        map[string]*UserContribution // contributions of commits missing in the other repository
+}
+
+type ContributionDifference struct {
+	Email       string
+	BaseCommits int
+	HeadCommits int
+	Delta       int
+}
+
+type CompareReport struct {
+	Base           *CompareSide
+	Head           *CompareSide
+	CommonCount    int
+	LastCommon     string
+	LastCommonDate string
+	Differences    []*ContributionDifference
+
+	run *analysisRun
+}
+
+// Compare compares the contributions of two repositories (e.g., a fork and its upstream) and reports how far they diverged,
+// the report is rendered with the theme and the language of the analyzer.
+//
+// Parameters:
+//   - basePath: The path to the base repository, e.g. upstream (directory or URL).
+//   - baseBranch: The branch of the base repository.
+//   - headPath: The path to the repository compared with the base, e.g. a fork (directory or URL).
+//   - headBranch: The branch of the compared repository.
+//
+// Returns:
+//   - The comparison of both repositories.
+//   - An error if a repository is not available or its history could not be read.
+func (analyzer *Analyzer) Compare(basePath string, baseBranch string, headPath string, headBranch string) (*CompareReport, error) {
+	base, err := newCompareSide(basePath, baseBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := newCompareSide(headPath, headBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Comparing '%s' (%s) with '%s' (%s)", head.Name, head.Branch, base.Name, base.Branch)
+
+	// the comparison does not apply any configuration and covers the whole history
+	run := analyzer.newRun()
+	run.config = &Config{}
+
+	return run.compareRepositories(base, head)
+}
+
+// HTML generates the HTML report of the comparison.
+func (report *CompareReport) HTML() (string, error) {
+	return report.run.generateHTMLCompareReport(report)
+}
+
+// WriteCompare writes the HTML report of the comparison as given by Options.Output and Options.Overwrite, named
+// compare_<base>_<head> by default.
+//
+// Returns:
+//   - The name of the written report file.
+//   - An error if the report could not be generated or written.
+func (analyzer *Analyzer) WriteCompare(report *CompareReport) (string, error) {
+	content, err := report.HTML()
+	if err != nil {
+		return "", fmt.Errorf("error generating HTML report: %w", err)
+	}
+
+	filename, err := analyzer.outputPath("compare_"+report.Base.Name+"_"+report.Head.Name, "html", time.Now())
+	if err != nil {
+		return "", err
+	}
+	if err := writeOutput(filename, content); err != nil {
+		return "", fmt.Errorf("error writing HTML report to file: %w", err)
+	}
+
+	log.Printf("HTML report generated: %s\n", filename)
+	return filename, nil
+}
+
+func newCompareSide(repoPath string, branch string) (*CompareSide, error) {
+	localPath, err := PrepareRepository(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompareSide{
+		Name:   RepositoryName(localPath),
+		Path:   localPath,
+		Branch: branch,
+	}, nil
+}
+
+// compareRepositories compares the history of both sides by commit hash.
+//
+// Commits are considered common if the same commit (hash) is reachable in both repositories, so the
+// repositories do not need to share any remotes or objects.
+func (run *analysisRun) compareRepositories(base *CompareSide, head *CompareSide) (*CompareReport, error) {
+	baseCommits, err := listCommitHashes(base.Path, base.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	headCommits, err := listCommitHashes(head.Path, head.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CompareReport{Base: base, Head: head, run: run}
+
+	baseSet := make(map[string]bool, len(baseCommits))
+	for _, hash := range baseCommits {
+		baseSet[hash] = true
+	}
+	headSet := make(map[string]bool, len(headCommits))
+	for _, hash := range headCommits {
+		headSet[hash] = true
+		if baseSet[hash] {
+			report.CommonCount++
+			if report.LastCommon == "" {
+				report.LastCommon = hash
+			}
+		}
+	}
+
+	if err := run.aggregateCompareSide(base, headSet); err != nil {
+		return nil, err
+	}
+	if err := run.aggregateCompareSide(head, baseSet); err != nil {
+		return nil, err
+	}
+
+	if report.LastCommon != "" {
+		cmdDate := gitCommand(head.Path, "show", "-s", "--format=%ad", gitLogDateFormat, report.LastCommon)
+		if output, err := cmdDate.Output(); err == nil {
+			report.LastCommonDate = strings.TrimSpace(string(output))
+		}
+	}
+
+	emails := make(map[string]bool)
+	for email := range base.Contributions {
+		emails[email] = true
+	}
+	for email := range head.Contributions {
+		emails[email] = true
+	}
+	for email := range emails {
+		difference := &ContributionDifference{Email: email}
+		if contribution, ok := base.Contributions[email]; ok {
+			difference.BaseCommits = contribution.CommitCount
+		}
+		if contribution, ok := head.Contributions[email]; ok {
+			difference.HeadCommits = contribution.CommitCount
+		}
+		difference.Delta = difference.HeadCommits - difference.BaseCommits
+		report.Differences = append(report.Differences, difference)
+	}
+	sort.Slice(report.Differences, func(i, j int) bool {
+		a, b := report.Differences[i].Delta, report.Differences[j].Delta
+		if a < 0 {
+			a = -a
+		}
+		if b < 0 {
+			b = -b
+		}
+		if a != b {
+			return a > b
+		}
+		return report.Differences[i].Email < report.Differences[j].Email
+	})
+
+	return report, nil
+}
+
+// aggregateCompareSide aggregates all commits of a side and separately those missing in the other side.
+func (run *analysisRun) aggregateCompareSide(side *CompareSide, otherCommits map[string]bool) error {
+	all := newBranchReport(side.Branch)
+	unique := newBranchReport(side.Branch)
+
+	cmdLog := gitCommand(side.Path, run.gitLogArgs(side.Branch, "")...)
+
+	var missing []CommitRecord
+	err := run.streamGitLog(cmdLog, run.batchSize, func(commits []CommitRecord) {
+		run.aggregateCommits(all, commits, "")
+		missing = missing[:0]
+		for _, commit := range commits {
+			if !otherCommits[commit.Hash] {
+				missing = append(missing, commit)
+			}
+		}
+		run.aggregateCommits(unique, missing, "")
+		side.CommitCount += len(commits)
+		side.UniqueCount += len(missing)
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("git log of '%s' failed: %w", side.Name, err)
+	}
+
+	side.Contributions = all.Contributions
+	side.Unique = unique.Contributions
+	return nil
+}
+
+// listCommitHashes lists the hashes of all commits reachable from revision, newest first.
+func listCommitHashes(repoPath string, revision string) ([]string, error) {
+	cmd := gitCommand(repoPath, "rev-list", revision)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-list for '%s' in %s failed: %w, output: %s", revision, repoPath, err, output)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+func (run *analysisRun) generateHTMLCompareReport(report *CompareReport) (string, error) {
+	tmpl := `
+<!DOCTYPE html>
+<html lang="en" data-bs-theme="dark">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>Git Comparison Report: {{.Head.Name}} vs. {{.Base.Name}}</title>
+{{bootstrapStyles}}
+<style>
+	.fixed-width {
+		width: 150px;
+	}
+</style>
+</head>
+<body>
+
+<div class="container mt-4">
+
+<h4> Base repository: <span class="badge text-bg-success">{{.Base.Name}}</span> <span class="badge text-bg-warning">{{.Base.Branch}}</span></h4>
+<h4> Compared repository: <span class="badge text-bg-success">{{.Head.Name}}</span> <span class="badge text-bg-warning">{{.Head.Branch}}</span></h4>
+
+<h4>Divergence</h4>
+<table class="table table-dark table-striped">
+	<tbody>
+		<tr><td class="fixed-width">Common commits</td><td>{{.CommonCount}}</td></tr>
+		<tr><td>Last common commit</td><td>{{if .LastCommon}}<code>{{printf "%.10s" .LastCommon}}</code> ({{.LastCommonDate}}){{else}}none{{end}}</td></tr>
+		<tr><td>Commits only in {{.Head.Name}} (ahead)</td><td>{{.Head.UniqueCount}}</td></tr>
+		<tr><td>Commits only in {{.Base.Name}} (behind)</td><td>{{.Base.UniqueCount}}</td></tr>
+	</tbody>
+</table>
+
+{{range $side := (sides .Head .Base)}}
+<h4>Contributions only in {{$side.Name}}</h4>
+<table class="table table-dark table-striped">
+	<thead>
+		<tr>
+			<th class="fixed-width">Email</th>
+			<th class="fixed-width">Commit Count</th>
+			<th>Lines Added</th>
+			<th>Lines Removed</th>
+			<th>Lines Edited</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range sortContributions $side.Unique}}
+		<tr>
+			<td>{{.Email}}</td>
+			<td>{{.CommitCount}}</td>
+			<td>{{.LinesAdded}}</td>
+			<td>{{.LinesRemoved}}</td>
+			<td>{{.LinesEdited}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+
+<h4>Contribution differences</h4>
+<table class="table table-dark table-striped">
+	<thead>
+		<tr>
+			<th class="fixed-width">Email</th>
+			<th class="fixed-width">Commits in {{.Base.Name}}</th>
+			<th class="fixed-width">Commits in {{.Head.Name}}</th>
+			<th>Difference</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Differences}}
+		<tr>
+			<td>{{.Email}}</td>
+			<td>{{.BaseCommits}}</td>
+			<td>{{.HeadCommits}}</td>
+			<td>{{if gt .Delta 0}}+{{end}}{{.Delta}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</div>
+</body>
+</html>
+`
+	funcs := run.reportTemplateFuncs()
+	funcs["sides"] = func(sides ...*CompareSide) []*CompareSide {
+		return sides
+	}
+
+	t, err := template.New("compare").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, report); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
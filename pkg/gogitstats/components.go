@@ -0,0 +1,120 @@
+package gogitstats
+
+import (
+	"sort"
+	"strings"
+)
+
+// defaultComponentTrailer is the commit trailer naming the component of a commit.
+const defaultComponentTrailer = "Component"
+
+type ComponentRule struct {
+	Pattern   string `yaml:"pattern" json:"pattern"`
+	Component string `yaml:"component" json:"component"`
+}
+
+// ComponentConfig describes how commits are attributed to components (or teams).
+type ComponentConfig struct {
+	// Trailer is the commit trailer naming the components of a commit (default "Component")
+	Trailer string `yaml:"trailer" json:"trailer"`
+	// Paths attribute the changed files of commits without trailer, the first matching rule wins
+	Paths []ComponentRule `yaml:"paths" json:"paths"`
+}
+
+type ComponentReport struct {
+	Name         string
+	CommitCount  int
+	LinesEdited  int
+	Contributors map[string]*CategoryContribution
+}
+
+// trailerKey returns the name of the component trailer.
+func (components *ComponentConfig) trailerKey() string {
+	if components.Trailer == "" {
+		return defaultComponentTrailer
+	}
+	return components.Trailer
+}
+
+// commitComponents returns the components of a commit together with the number of lines edited in them.
+//
+// Components named by trailers take precedence and are credited with all lines of the commit.
+// Otherwise, the changed files are attributed by the path rules.
+func (components *ComponentConfig) commitComponents(commit CommitRecord) map[string]int {
+	linesByComponent := make(map[string]int)
+
+	for _, value := range commit.trailerValues(components.trailerKey()) {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				linesByComponent[name] = 0
+			}
+		}
+	}
+
+	if len(linesByComponent) > 0 {
+		linesEdited := 0
+		for _, change := range commit.Files {
+			linesEdited += change.Added + change.Removed
+		}
+		for name := range linesByComponent {
+			linesByComponent[name] = linesEdited
+		}
+		return linesByComponent
+	}
+
+	for _, change := range commit.Files {
+		for _, rule := range components.Paths {
+			if matchPathPattern(rule.Pattern, change.Path) {
+				linesByComponent[rule.Component] += change.Added + change.Removed
+				break
+			}
+		}
+	}
+	return linesByComponent
+}
+
+// addComponentCommit accounts a commit for every component it is attributed to.
+func addComponentCommit(report *BranchReport, components *ComponentConfig, commit CommitRecord, period string) {
+	if components == nil {
+		return
+	}
+
+	for name, linesEdited := range components.commitComponents(commit) {
+		component, ok := report.Components[name]
+		if !ok {
+			component = &ComponentReport{Name: name, Contributors: make(map[string]*CategoryContribution)}
+			report.Components[name] = component
+		}
+		component.CommitCount++
+		component.LinesEdited += linesEdited
+
+		contribution, ok := component.Contributors[commit.Email]
+		if !ok {
+			contribution = &CategoryContribution{Email: commit.Email, Timeline: make(map[string]int)}
+			component.Contributors[commit.Email] = contribution
+		}
+		contribution.CommitCount++
+		contribution.LinesEdited += linesEdited
+		if period != "" {
+			contribution.Timeline[period]++
+		}
+		if commit.Date > contribution.LastChange {
+			contribution.LastChange = commit.Date
+		}
+	}
+}
+
+// sortComponents orders components by commit count, descending.
+func sortComponents(components map[string]*ComponentReport) []*ComponentReport {
+	sorted := make([]*ComponentReport, 0, len(components))
+	for _, c := range components {
+		sorted = append(sorted, c)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].CommitCount != sorted[j].CommitCount {
+			return sorted[i].CommitCount > sorted[j].CommitCount
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
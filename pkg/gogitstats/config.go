@@ -0,0 +1,352 @@
+package gogitstats
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type PathRule struct {
+	Pattern string `yaml:"pattern" json:"pattern"`
+	Role    string `yaml:"role" json:"role"`
+}
+
+// WeightRule scales the line counts of files matching the pattern (e.g., 0 for generated code).
+type WeightRule struct {
+	Pattern string  `yaml:"pattern" json:"pattern"`
+	Weight  float64 `yaml:"weight" json:"weight"`
+}
+
+// REPOSITORY_CONFIG_FILE is the configuration shipped inside an analyzed repository.
+const REPOSITORY_CONFIG_FILE = ".gogitstats.yaml"
+
+// minExcludedRevLength is the minimum length of abbreviated hashes of excluded commits.
+const minExcludedRevLength = 7
+
+type Config struct {
+	// Filter and MainBranch are defaults of the options `--filter` and `--mainbranch`
+	Filter          string `yaml:"filter" json:"-"`
+	MainBranch      string `yaml:"mainBranch" json:"-"`
+	ReleaseBranches string `yaml:"releaseBranches" json:"-"`
+	// Aliases map further emails of an author (e.g., private or old addresses) to one email
+	Aliases map[string]string `yaml:"aliases" json:"aliases"`
+	// ExcludeRevs lists commits ignored by the analysis (e.g., mass reformatting), full or abbreviated hashes
+	ExcludeRevs   []string      `yaml:"excludeRevs" json:"excludeRevs"`
+	Roles         []PathRule    `yaml:"roles" json:"roles"`
+	TicketPolicy  *TicketPolicy `yaml:"ticketPolicy" json:"ticketPolicy"`
+	SecurityPaths []string      `yaml:"securityPaths" json:"securityPaths"`
+	// ProtectedPaths lists paths whose changes are listed one by one as audit trail (e.g., migrations/)
+	ProtectedPaths []string `yaml:"protectedPaths" json:"protectedPaths"`
+	// CommitURL links the commits of the reports, "{hash}" is replaced by the commit hash (derived from the remote
+	// origin if not set)
+	CommitURL string `yaml:"commitURL" json:"commitURL"`
+	// BranchNaming lists the patterns the names of local branches must match
+	BranchNaming *BranchNamingPolicy `yaml:"branchNaming" json:"branchNaming"`
+	// DependencyManifests replaces the default list of dependency manifests, an empty list disables the report
+	DependencyManifests []string `yaml:"dependencyManifests" json:"dependencyManifests"`
+	// CIPaths replaces the default list of CI/pipeline files, an empty list disables the report
+	CIPaths []string `yaml:"ciPaths" json:"ciPaths"`
+	// Weights scale the line counts of matching files before any totals are computed, the first matching rule wins
+	Weights []WeightRule `yaml:"weights" json:"weights"`
+	// Components attribute commits to components by trailers or paths
+	Components *ComponentConfig `yaml:"components" json:"components"`
+	// Identities resolve commit emails to employee records for department rollups
+	Identities *IdentityConfig `yaml:"identities" json:"-"`
+	// Access restricts the reports served by `--watch`, it is never taken from the configuration shipped in a repository
+	Access *AccessConfig `yaml:"access" json:"-"`
+	// Sections lists the report sections to include (all if not set), overridden by `--sections`
+	Sections []string `yaml:"sections" json:"-"`
+	// Profiles only affect the generated reports, hence they are not part of the analysis cache key
+	Profiles map[string]*OutputProfile `yaml:"profiles" json:"-"`
+}
+
+// LoadConfig reads the YAML (or JSON) configuration file located at configPath.
+//
+// Returns:
+//   - The parsed configuration.
+//   - An error if the file could not be read, parsed or contains invalid rules.
+func LoadConfig(configPath string) (*Config, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", configPath, err)
+	}
+	return parseConfig(content, configPath)
+}
+
+// parseConfig parses the YAML (or JSON) configuration read from configPath, which names it in errors.
+func parseConfig(content []byte, configPath string) (*Config, error) {
+	config := &Config{}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+
+	if len(config.Aliases) > 0 {
+		aliases := make(map[string]string, len(config.Aliases))
+		for alias, email := range config.Aliases {
+			if alias == "" || email == "" {
+				return nil, fmt.Errorf("invalid alias in %s: both the alias and the email must be set", configPath)
+			}
+			aliases[strings.ToLower(alias)] = email
+		}
+		config.Aliases = aliases
+	}
+
+	for i, rev := range config.ExcludeRevs {
+		rev = strings.ToLower(strings.TrimSpace(rev))
+		if len(rev) < minExcludedRevLength || strings.Trim(rev, "0123456789abcdef") != "" {
+			return nil, fmt.Errorf("invalid excluded revision '%s' in %s: expected a commit hash of at least %d characters", rev, configPath, minExcludedRevLength)
+		}
+		config.ExcludeRevs[i] = rev
+	}
+
+	for _, rule := range config.Roles {
+		if rule.Pattern == "" || rule.Role == "" {
+			return nil, fmt.Errorf("invalid role rule in %s: both 'pattern' and 'role' must be set", configPath)
+		}
+		if _, err := path.Match(strings.TrimSuffix(rule.Pattern, "/"), ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s' in %s: %w", rule.Pattern, configPath, err)
+		}
+	}
+
+	for _, rule := range config.Weights {
+		if rule.Pattern == "" || rule.Weight < 0 {
+			return nil, fmt.Errorf("invalid weight rule in %s: 'pattern' must be set and 'weight' must not be negative", configPath)
+		}
+		if _, err := path.Match(strings.TrimSuffix(rule.Pattern, "/"), ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s' in %s: %w", rule.Pattern, configPath, err)
+		}
+	}
+
+	if config.Components != nil {
+		for _, rule := range config.Components.Paths {
+			if rule.Pattern == "" || rule.Component == "" {
+				return nil, fmt.Errorf("invalid component rule in %s: both 'pattern' and 'component' must be set", configPath)
+			}
+			if _, err := path.Match(strings.TrimSuffix(rule.Pattern, "/"), ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern '%s' in %s: %w", rule.Pattern, configPath, err)
+			}
+		}
+	}
+
+	if config.Identities != nil {
+		if err := config.Identities.validate(); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", configPath, err)
+		}
+	}
+
+	if config.Access != nil {
+		if err := config.Access.validate(config); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", configPath, err)
+		}
+	}
+
+	if config.TicketPolicy != nil {
+		if err := config.TicketPolicy.compile(); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", configPath, err)
+		}
+	}
+
+	if config.BranchNaming != nil {
+		if err := config.BranchNaming.compile(); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", configPath, err)
+		}
+	}
+
+	if config.CommitURL != "" && !strings.Contains(config.CommitURL, COMMIT_URL_PLACEHOLDER) {
+		return nil, fmt.Errorf("invalid commit URL in %s: expected the placeholder %s for the commit hash", configPath, COMMIT_URL_PLACEHOLDER)
+	}
+
+	return config, nil
+}
+
+// loadRepositoryConfig reads the configuration shipped in the working tree of the repository located at repoPath,
+// or in the commit HEAD points to if the repository is bare.
+//
+// Returns:
+//   - The parsed configuration, nil if the repository has no configuration file.
+//   - An error if the file could not be read, parsed or contains invalid rules.
+func loadRepositoryConfig(repoPath string) (*Config, error) {
+	if isBareRepository(repoPath) {
+		content, err := readHeadFile(repoPath, REPOSITORY_CONFIG_FILE)
+		if content == nil || err != nil {
+			return nil, err
+		}
+		return parseConfig(content, "HEAD:"+REPOSITORY_CONFIG_FILE)
+	}
+
+	configPath := filepath.Join(repoPath, REPOSITORY_CONFIG_FILE)
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return LoadConfig(configPath)
+}
+
+// withDefaults returns a copy of the configuration, whose unset settings are taken from defaults
+// (e.g., the configuration shipped in the repository). Aliases of both are combined.
+func (config *Config) withDefaults(defaults *Config) *Config {
+	merged := *config
+	if defaults == nil {
+		return &merged
+	}
+
+	if merged.Filter == "" {
+		merged.Filter = defaults.Filter
+	}
+	if merged.MainBranch == "" {
+		merged.MainBranch = defaults.MainBranch
+	}
+	if merged.ReleaseBranches == "" {
+		merged.ReleaseBranches = defaults.ReleaseBranches
+	}
+	if len(defaults.Aliases) > 0 {
+		merged.Aliases = make(map[string]string)
+		for alias, email := range defaults.Aliases {
+			merged.Aliases[alias] = email
+		}
+		for alias, email := range config.Aliases {
+			merged.Aliases[alias] = email
+		}
+	}
+	if merged.ExcludeRevs == nil {
+		merged.ExcludeRevs = defaults.ExcludeRevs
+	}
+	if merged.Roles == nil {
+		merged.Roles = defaults.Roles
+	}
+	if merged.TicketPolicy == nil {
+		merged.TicketPolicy = defaults.TicketPolicy
+	}
+	if merged.SecurityPaths == nil {
+		merged.SecurityPaths = defaults.SecurityPaths
+	}
+	if merged.ProtectedPaths == nil {
+		merged.ProtectedPaths = defaults.ProtectedPaths
+	}
+	if merged.CommitURL == "" {
+		merged.CommitURL = defaults.CommitURL
+	}
+	if merged.BranchNaming == nil {
+		merged.BranchNaming = defaults.BranchNaming
+	}
+	if merged.DependencyManifests == nil {
+		merged.DependencyManifests = defaults.DependencyManifests
+	}
+	if merged.CIPaths == nil {
+		merged.CIPaths = defaults.CIPaths
+	}
+	if merged.Weights == nil {
+		merged.Weights = defaults.Weights
+	}
+	if merged.Components == nil {
+		merged.Components = defaults.Components
+	}
+	if merged.Identities == nil {
+		merged.Identities = defaults.Identities
+	}
+	return &merged
+}
+
+// canonicalEmail returns the email an author is reported with, resolving configured aliases.
+func (config *Config) canonicalEmail(email string) string {
+	if canonical, ok := config.Aliases[strings.ToLower(email)]; ok {
+		return canonical
+	}
+	return email
+}
+
+// excludesCommit reports whether the commit with the given hash is ignored by the analysis.
+func (config *Config) excludesCommit(hash string) bool {
+	for _, rev := range config.ExcludeRevs {
+		if strings.HasPrefix(hash, rev) {
+			return true
+		}
+	}
+	return false
+}
+
+// roleNames returns the distinct role names in the order they are configured.
+func (config *Config) roleNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, rule := range config.Roles {
+		if !seen[rule.Role] {
+			seen[rule.Role] = true
+			names = append(names, rule.Role)
+		}
+	}
+	return names
+}
+
+// roleForPath returns the role of the first rule matching filePath or an empty string.
+func (config *Config) roleForPath(filePath string) string {
+	for _, rule := range config.Roles {
+		if matchPathPattern(rule.Pattern, filePath) {
+			return rule.Role
+		}
+	}
+	return ""
+}
+
+// weightCommit returns the commit with the line counts of its files scaled by the first matching
+// weight rule. Files without a matching rule keep their line counts (weight 1).
+func (config *Config) weightCommit(commit CommitRecord) CommitRecord {
+	if len(config.Weights) == 0 {
+		return commit
+	}
+
+	files := make([]FileChange, len(commit.Files))
+	for i, change := range commit.Files {
+		for _, rule := range config.Weights {
+			if matchPathPattern(rule.Pattern, change.Path) {
+				change.Added = int(math.Round(float64(change.Added) * rule.Weight))
+				change.Removed = int(math.Round(float64(change.Removed) * rule.Weight))
+				break
+			}
+		}
+		files[i] = change
+	}
+	commit.Files = files
+	return commit
+}
+
+// matchPathPattern reports whether filePath (slash separated, relative to the repository root)
+// matches the given pattern.
+//
+// Patterns follow a simplified .gitignore logic:
+//   - "infra/" matches everything below a directory named infra.
+//   - "*.sql" (no slash) is matched against the file name only.
+//   - "db/*.sql" or ".github/workflows" are matched against the whole path, where a
+//     matching directory includes all files below it.
+func matchPathPattern(pattern string, filePath string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		dir := strings.TrimSuffix(pattern, "/")
+		for current := path.Dir(filePath); current != "." && current != "/"; current = path.Dir(current) {
+			if matched, _ := path.Match(dir, current); matched {
+				return true
+			}
+			if !strings.Contains(dir, "/") {
+				if matched, _ := path.Match(dir, path.Base(current)); matched {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(filePath))
+		return matched
+	}
+
+	for current := filePath; current != "." && current != "/"; current = path.Dir(current) {
+		if matched, _ := path.Match(pattern, current); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package gogitstats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const confluenceStorageTemplate = `
+<h2>{{t "Git Contribution Report: %s" .RepoName}}</h2>
+{{if not .HidePaths}}<p>{{t "Applied file filter:"}} <code>{{.FileFilter}}</code></p>{{end}}
+{{range $branchName, $branchReport := .BranchReports}}
+<h3>{{t "Branch:"}} {{$branchName}}</h3>
+<table>
+<tbody>
+<tr>
+{{if index $.Columns "email"}}<th>{{t "Email"}}</th>{{end}}
+{{if index $.Columns "commits"}}<th>{{t "Commit Count"}}</th>{{end}}
+{{if index $.Columns "timeline"}}<th>{{t "Contribution Timeline"}}</th>{{end}}
+{{if index $.Columns "added"}}<th>{{t "Lines Added"}}</th>{{end}}
+{{if index $.Columns "removed"}}<th>{{t "Lines Removed"}}</th>{{end}}
+{{if index $.Columns "edited"}}<th>{{t "Lines Edited"}}</th>{{end}}
+{{if index $.Columns "filter"}}<th>{{t "File Filter"}}</th>{{end}}
+{{if and $.RoleNames (index $.Columns "roles")}}<th>{{t "Roles"}}</th>{{end}}
+</tr>
+{{range topContributions .Contributions}}
+<tr>
+{{if index $.Columns "email"}}<td>{{if .Others}}{{t "%d others" .Others}}{{else}}{{email .Email}}{{end}}</td>{{end}}
+{{if index $.Columns "commits"}}<td>{{.CommitCount}}</td>{{end}}
+{{if index $.Columns "timeline"}}<td>{{range $yearWeek, $count := .ContributionTimeline}}{{$yearWeek}}: {{$count}}<br />{{end}}</td>{{end}}
+{{if index $.Columns "added"}}<td>{{.LinesAdded}}</td>{{end}}
+{{if index $.Columns "removed"}}<td>{{.LinesRemoved}}</td>{{end}}
+{{if index $.Columns "edited"}}<td>{{.LinesEdited}}</td>{{end}}
+{{if index $.Columns "filter"}}<td>{{.FileFilter}}</td>{{end}}
+{{if and $.RoleNames (index $.Columns "roles")}}<td>{{$contribution := .}}{{range $role := $.RoleNames}}{{with index $contribution.Roles $role}}{{$role}}: {{percent . $contribution.LinesEdited}}%<br />{{end}}{{end}}</td>{{end}}
+</tr>
+{{end}}
+</tbody>
+</table>
+{{end}}
+`
+
+type confluencePage struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	Version struct {
+		Number int `json:"number"`
+	} `json:"version"`
+	Body *confluenceBody `json:"body,omitempty"`
+}
+
+type confluenceBody struct {
+	Storage struct {
+		Value          string `json:"value"`
+		Representation string `json:"representation"`
+	} `json:"storage"`
+}
+
+// generateConfluenceStorage renders the report in the Confluence storage format (XHTML without scripts and styles).
+func (run *analysisRun) generateConfluenceStorage(branchReports map[string]*BranchReport, repoName string, fileFilter string) (string, error) {
+	t, err := template.New("confluence").Funcs(run.reportTemplateFuncs()).Parse(confluenceStorageTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, run.newReportData(branchReports, repoName, fileFilter)); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// PublishToConfluence replaces the content of an existing Confluence page with the given storage-format HTML.
+//
+// The credentials are taken from the environment:
+//   - CONFLUENCE_USER and CONFLUENCE_TOKEN for basic authentication (Confluence Cloud, API token).
+//   - CONFLUENCE_TOKEN only for bearer authentication (Confluence Server/Data Center, personal access token).
+//
+// Parameters:
+//   - baseURL: The base URL of Confluence (e.g., https://example.atlassian.net/wiki).
+//   - pageID: The ID of the page to update.
+//   - content: The page content in the storage format.
+//
+// Returns:
+//   - An error if the page could not be read or updated.
+func PublishToConfluence(baseURL string, pageID string, content string) error {
+	client := &http.Client{Timeout: 60 * time.Second}
+	pageURL := fmt.Sprintf("%s/rest/api/content/%s", strings.TrimSuffix(baseURL, "/"), pageID)
+
+	req, err := newConfluenceRequest(http.MethodGet, pageURL+"?expand=version", nil)
+	if err != nil {
+		return err
+	}
+
+	var page confluencePage
+	if err := doJSONRequest(client, req, &page); err != nil {
+		return fmt.Errorf("failed to read page %s: %w", pageID, err)
+	}
+
+	update := confluencePage{ID: pageID, Type: "page", Title: page.Title, Body: &confluenceBody{}}
+	update.Version.Number = page.Version.Number + 1
+	update.Body.Storage.Value = content
+	update.Body.Storage.Representation = "storage"
+
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("failed to encode page %s: %w", pageID, err)
+	}
+
+	req, err = newConfluenceRequest(http.MethodPut, pageURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	if err := doJSONRequest(client, req, nil); err != nil {
+		return fmt.Errorf("failed to update page %s: %w", pageID, err)
+	}
+
+	log.Printf("Confluence page '%s' updated to version %d", page.Title, update.Version.Number)
+	return nil
+}
+
+func newConfluenceRequest(method string, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", url, err)
+	}
+
+	token := os.Getenv("CONFLUENCE_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("environment variable CONFLUENCE_TOKEN is not set")
+	}
+
+	if user := os.Getenv("CONFLUENCE_USER"); user != "" {
+		req.SetBasicAuth(user, token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
@@ -0,0 +1,228 @@
+package gogitstats
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxContentionFiles limits the number of files listed as contention hot zones.
+const maxContentionFiles = 20
+
+// fileEdit is a commit changing a file.
+type fileEdit struct {
+	Hash      string
+	Time      time.Time
+	Email     string
+	CoAuthors []string // emails named by Co-authored-by trailers
+}
+
+// FileContention describes the window in which most distinct authors edited a file.
+type FileContention struct {
+	Path        string
+	Authors     []string
+	EditCount   int
+	WindowStart string
+	WindowEnd   string
+}
+
+type ContentionReport struct {
+	WindowDays int
+	MinAuthors int
+	Files      []*FileContention
+}
+
+// assessContention reports the files edited by many distinct authors within a short window.
+// The report covers the commits of all local branches and is attached to the main branch report.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - branchReports: The branch reports.
+//   - fileFilter: The pathspec limiting the files, ignored if empty.
+func (run *analysisRun) assessContention(repoPath string, branchReports map[string]*BranchReport, fileFilter string) {
+	report, ok := branchReports[run.mainBranch]
+	if !ok {
+		return
+	}
+
+	contention, err := run.measureContention(repoPath, fileFilter, run.contentionWindow, run.contentionMinAuthors)
+	if err != nil {
+		log.Printf("Measuring file contention failed: %v", err)
+		report.Contention = nil
+		return
+	}
+	report.Contention = contention
+}
+
+// measureContention finds contention hot zones, i.e., files which at least minAuthors different authors
+// edited within windowDays. Such files are likely sources of merge conflicts.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - fileFilter: The pathspec limiting the files, ignored if empty.
+//   - windowDays: The length of the window in days.
+//   - minAuthors: The minimum number of distinct authors within the window.
+//
+// Returns:
+//   - The hot zones ordered by number of authors and edits, descending.
+//   - An error if git failed.
+func (run *analysisRun) measureContention(repoPath string, fileFilter string, windowDays int, minAuthors int) (*ContentionReport, error) {
+	edits, err := run.listFileEdits(repoPath, fileFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ContentionReport{WindowDays: windowDays, MinAuthors: minAuthors}
+	window := time.Duration(windowDays) * 24 * time.Hour
+	for filePath, fileEdits := range edits {
+		if contention := peakContention(fileEdits, window); contention != nil && len(contention.Authors) >= minAuthors {
+			contention.Path = filePath
+			report.Files = append(report.Files, contention)
+		}
+	}
+
+	sort.Slice(report.Files, func(i, j int) bool {
+		a, b := report.Files[i], report.Files[j]
+		if len(a.Authors) != len(b.Authors) {
+			return len(a.Authors) > len(b.Authors)
+		}
+		if a.EditCount != b.EditCount {
+			return a.EditCount > b.EditCount
+		}
+		return a.Path < b.Path
+	})
+	if len(report.Files) > maxContentionFiles {
+		report.Files = report.Files[:maxContentionFiles]
+	}
+
+	return report, nil
+}
+
+// peakContention slides a window over the edits of a file and returns the window with the most distinct authors.
+func peakContention(edits []fileEdit, window time.Duration) *FileContention {
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Time.Before(edits[j].Time)
+	})
+
+	var peak *FileContention
+	authors := make(map[string]int)
+	start := 0
+	for end, edit := range edits {
+		authors[edit.Email]++
+		for edit.Time.Sub(edits[start].Time) > window {
+			if authors[edits[start].Email]--; authors[edits[start].Email] == 0 {
+				delete(authors, edits[start].Email)
+			}
+			start++
+		}
+
+		if peak == nil || len(authors) > len(peak.Authors) || (len(authors) == len(peak.Authors) && end-start+1 > peak.EditCount) {
+			peak = &FileContention{
+				EditCount:   end - start + 1,
+				WindowStart: edits[start].Time.Format("2006-01-02"),
+				WindowEnd:   edit.Time.Format("2006-01-02"),
+			}
+			for email := range authors {
+				peak.Authors = append(peak.Authors, email)
+			}
+			sort.Strings(peak.Authors)
+		}
+	}
+	return peak
+}
+
+// listFileEdits lists the edits of every file by the non-merge commits of all local branches, renamed files are
+// followed, so their edits are listed by their current path (see renamedPaths).
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - fileFilter: The pathspec limiting the files, ignored if empty.
+//
+// Returns:
+//   - The edits by file path, newest first.
+//   - An error if git failed.
+func (run *analysisRun) listFileEdits(repoPath string, fileFilter string) (map[string][]fileEdit, error) {
+	args := []string{"log", "--branches", "--no-merges", "--name-status",
+		"--format=%x1e%H%x1f%ae%x1f%at%x1f%(trailers:key=Co-authored-by,valueonly,separator=%x1d)"}
+	args = append(args, run.gitDateRange...)
+	args = append(args, pathspecArgs(fileFilter)...)
+	cmd := gitCommand(repoPath, args...)
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git log output: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start git log: %w", err)
+	}
+
+	edits := make(map[string][]fileEdit)
+	renames := make(renamedPaths)
+	var current *fileEdit
+	scanner := bufio.NewScanner(output)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if header, ok := strings.CutPrefix(line, "\x1e"); ok {
+			current = nil
+			fields := strings.SplitN(header, "\x1f", 4)
+			if len(fields) != 4 {
+				continue
+			}
+			seconds, err := strconv.ParseInt(fields[2], 10, 64)
+			if err != nil {
+				continue
+			}
+			if run.config.excludesCommit(fields[0]) {
+				continue
+			}
+			current = &fileEdit{Hash: fields[0], Email: run.config.canonicalEmail(fields[1]), Time: time.Unix(seconds, 0).UTC()}
+			for _, coAuthor := range strings.Split(fields[3], "\x1d") {
+				if email := trailerEmail(coAuthor); email != "" {
+					current.CoAuthors = append(current.CoAuthors, run.config.canonicalEmail(email))
+				}
+			}
+			continue
+		}
+		if change, ok := parseNameStatusLine(line); ok && current != nil {
+			filePath := renames.follow(change)
+			edits[filePath] = append(edits[filePath], *current)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_ = cmd.Wait()
+		return nil, fmt.Errorf("failed to read git log output: %w", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+
+	return edits, nil
+}
+
+// parseNameStatusLine parses a line of `git log --name-status`, e.g. "M\tpath" or "R087\told\tnew" for a rename.
+func parseNameStatusLine(line string) (FileChange, bool) {
+	parts := strings.Split(line, "\t")
+	switch {
+	case len(parts) == 2:
+		return FileChange{Path: parts[1]}, true
+	case len(parts) == 3 && strings.HasPrefix(parts[0], "R"):
+		return FileChange{Path: parts[2], OldPath: parts[1]}, true
+	case len(parts) == 3:
+		// copies keep the source, the edit is counted for the copy
+		return FileChange{Path: parts[2]}, true
+	}
+	return FileChange{}, false
+}
+
+// trailerEmail extracts the email of a trailer value like "Jane Doe <jane@example.com>".
+func trailerEmail(value string) string {
+	start, end := strings.LastIndex(value, "<"), strings.LastIndex(value, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return strings.TrimSpace(value[start+1 : end])
+}
@@ -0,0 +1,121 @@
+package gogitstats
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CommitFunnel counts the commits of a branch read from git and the commits removed by each stage of the
+// analysis, so branches without contributions can be explained, see diagnoseEmptyBranch.
+type CommitFunnel struct {
+	Read         int // commits read from git log, limited by the date range and the file filter
+	Excluded     int // commits ignored by excludeRevs of the configuration
+	OtherAuthors int // commits of authors not selected, see authorSelected
+	Imports      int // initial imports excluded, see Options.Imports
+}
+
+// checkBranchSelection warns of a missing main branch and of branch patterns matching no local branch, which
+// would otherwise silently change or empty the report.
+func (run *analysisRun) checkBranchSelection(branchNames []string) {
+	if !slices.Contains(branchNames, run.mainBranch) {
+		log.Printf("Warning: main branch '%s' does not exist, branches are analyzed in full instead of since their merge-base (set the main branch with --mainbranch); local branches: %s",
+			run.mainBranch, strings.Join(branchNames, ", "))
+	}
+	if run.mainBranchOnly {
+		return
+	}
+	for _, pattern := range run.branchPatterns {
+		matched := slices.ContainsFunc(branchNames, func(branchName string) bool {
+			ok, _ := path.Match(pattern, branchName)
+			return ok
+		})
+		if !matched {
+			log.Printf("Warning: branch pattern '%s' matches no local branch", pattern)
+		}
+	}
+}
+
+// diagnoseEmptyBranch explains which stage of the analysis removed all commits of a branch without contributions:
+// the branch has no commits of its own, none in the date range, none touching the filtered files, or the commits
+// were removed by the configuration, the author selection or the exclusion of initial imports.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - report: The branch report without contributions.
+//   - fileFilter: The pathspec limiting the commits and files, ignored if empty.
+//
+// Returns:
+//   - The reason the branch has no contributions.
+func (run *analysisRun) diagnoseEmptyBranch(repoPath string, report *BranchReport, fileFilter string) string {
+	funnel := report.Funnel
+	if funnel.Read > 0 {
+		var stages []string
+		if funnel.Excluded > 0 {
+			stages = append(stages, fmt.Sprintf("%d by excludeRevs of the configuration", funnel.Excluded))
+		}
+		if funnel.OtherAuthors > 0 {
+			stages = append(stages, fmt.Sprintf("%d by the author selection (--author, --exclude-author, --exclude-bots)", funnel.OtherAuthors))
+		}
+		if funnel.Imports > 0 {
+			stages = append(stages, fmt.Sprintf("%d as initial imports (--imports %s)", funnel.Imports, IMPORTS_EXCLUDE))
+		}
+		return fmt.Sprintf("all %d commits were removed: %s", funnel.Read, strings.Join(stages, ", "))
+	}
+
+	logRange := run.branchRevision(report.BranchName)
+	if report.BranchName != run.mainBranch {
+		if mergeBase, err := gitCommand(repoPath, "merge-base", run.branchRevision(run.mainBranch), logRange).Output(); err == nil {
+			logRange = strings.TrimSpace(string(mergeBase)) + ".." + logRange
+		}
+	}
+
+	total, err := countCommits(repoPath, []string{logRange})
+	if err != nil {
+		return fmt.Sprintf("no commits were read (%v)", err)
+	}
+	if total == 0 {
+		if report.BranchName == run.mainBranch {
+			return "the branch has no commits"
+		}
+		return "the branch has no commits of its own since its merge-base with the main branch"
+	}
+	limits := append([]string{logRange}, run.gitDateRange...)
+	if len(run.gitDateRange) > 0 {
+		if count, err := countCommits(repoPath, limits); err == nil && count == 0 {
+			var period []string
+			if run.since != "" {
+				period = append(period, "--since "+run.since)
+			}
+			if run.until != "" {
+				period = append(period, "--until "+run.until)
+			}
+			return fmt.Sprintf("none of its %d commits is in the analyzed period (%s)", total, strings.Join(period, " "))
+		}
+	}
+	if run.excludeMerges {
+		if count, err := countCommits(repoPath, append(limits, "--no-merges")); err == nil && count == 0 {
+			return "all of its commits are merge commits, which are excluded (--no-merges)"
+		}
+	}
+	if run.uniqueCommits && report.BranchName != run.mainBranch {
+		return "all of its commits are on other analyzed branches as well (--unique-commits)"
+	}
+	if fileFilter != "" {
+		return fmt.Sprintf("none of its commits touches files matching the filter '%s'", fileFilter)
+	}
+	return "no commits were read from git log"
+}
+
+// countCommits counts the commits listed by git rev-list with the given arguments.
+func countCommits(repoPath string, args []string) (int, error) {
+	cmd := gitCommand(repoPath, append([]string{"rev-list", "--count"}, args...)...)
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("git rev-list failed: %w", err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
@@ -0,0 +1,100 @@
+package gogitstats
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DiscoverRepositories walks the directory tree below root and finds all git repositories,
+// including repositories nested in other repositories, bare repositories (e.g., mirrors) and directories
+// reached by symbolic links.
+//
+// Directories are identified by their resolved path, so symbolic link cycles terminate and
+// repositories linked from several places are analyzed once.
+//
+// Parameters:
+//   - root: The directory to search.
+//
+// Returns:
+//   - The paths of the repositories in the order they were found.
+//   - An error if root is not a directory.
+func DiscoverRepositories(root string) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access directory %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	var repositories []string
+	found := make(map[string]bool) // resolved paths of the repositories
+
+	var walk func(dir string, followLinks bool, visited map[string]bool)
+	walk = func(dir string, followLinks bool, visited map[string]bool) {
+		realPath, err := filepath.EvalSymlinks(dir)
+		if err != nil || visited[realPath] {
+			return
+		}
+		visited[realPath] = true
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			log.Printf("Skipping directory %s: %v", dir, err)
+			return
+		}
+
+		// the internals of bare repositories are not searched
+		if isBareRepositoryDirectory(dir) {
+			if !found[realPath] {
+				found[realPath] = true
+				repositories = append(repositories, dir)
+			}
+			return
+		}
+
+		for _, entry := range entries {
+			// .git is a directory in repositories and a file in worktrees and submodules
+			if entry.Name() == ".git" && !found[realPath] {
+				found[realPath] = true
+				repositories = append(repositories, dir)
+				break
+			}
+		}
+
+		for _, entry := range entries {
+			if entry.Name() == ".git" || entry.Name() == REPOSITORIES_DIRECTORY {
+				continue
+			}
+			child := filepath.Join(dir, entry.Name())
+			isDir := entry.IsDir()
+			if entry.Type()&fs.ModeSymlink != 0 && followLinks {
+				target, err := os.Stat(child)
+				isDir = err == nil && target.IsDir()
+			}
+			if isDir {
+				walk(child, followLinks, visited)
+			}
+		}
+	}
+
+	// repositories are named by their real location, only those reachable by links alone are found by the second walk
+	walk(root, false, make(map[string]bool))
+	walk(root, true, make(map[string]bool))
+
+	return repositories, nil
+}
+
+// DiscoveredRepositoryName names a discovered repository after its path relative to root, so equally
+// named repositories in different directories produce different reports.
+func DiscoveredRepositoryName(root string, repoPath string) string {
+	relative, err := filepath.Rel(root, repoPath)
+	if err != nil || relative == "." {
+		return RepositoryName(repoPath)
+	}
+	return sanitizeDirectoryName(strings.TrimSuffix(filepath.ToSlash(relative), ".git"))
+}
@@ -0,0 +1,236 @@
+package gogitstats
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const DEPLOY_MARKER_TAGS = "tags"
+const DEPLOY_MARKER_MERGES = "merges"
+
+type DeliveryPeriod struct {
+	Period         string
+	Deployments    int
+	MergedChanges  int
+	MedianLeadTime float64 // days from the first commit of a merged branch to its merge
+	leadTimes      []float64
+}
+
+// ReleaseTagger is a creator of annotated tags, i.e., of releases.
+type ReleaseTagger struct {
+	Email       string
+	TagCount    int
+	Timeline    map[string]int // Period: tags created
+	LastTag     string
+	LastTagDate string
+}
+
+// DeliveryMetrics are lightweight DORA-style change metrics of the main branch.
+type DeliveryMetrics struct {
+	DeployMarker string
+	Periods      []*DeliveryPeriod
+	Taggers      []*ReleaseTagger
+}
+
+// assessDeliveryMetrics attaches the delivery metrics to the report of the main branch.
+func (run *analysisRun) assessDeliveryMetrics(repoPath string, branchReports map[string]*BranchReport) {
+	report, ok := branchReports[run.mainBranch]
+	if !ok {
+		return
+	}
+
+	metrics, err := run.measureDeliveryMetrics(repoPath, run.deployMarker)
+	if err != nil {
+		log.Printf("Measuring delivery metrics of branch '%s' failed: %v", run.mainBranch, err)
+		report.Delivery = nil
+		return
+	}
+	report.Delivery = metrics
+}
+
+// measureDeliveryMetrics computes change frequency and lead time per period.
+//
+// Deployments are counted by deploy marker: tags merged into the main branch (by tag date) or
+// merges into the main branch. The lead time of a change is the time from the first commit of a
+// merged branch to the merge commit on the main branch (first parent), so squash merges and
+// rebased branches without merge commits are not covered.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - deployMarker: Either DEPLOY_MARKER_TAGS or DEPLOY_MARKER_MERGES.
+//
+// Returns:
+//   - The metrics, nil if the main branch has neither deployments nor merged changes.
+//   - An error if git failed.
+func (run *analysisRun) measureDeliveryMetrics(repoPath string, deployMarker string) (*DeliveryMetrics, error) {
+	periods := make(map[string]*DeliveryPeriod)
+	periodOf := func(date time.Time) *DeliveryPeriod {
+		key, ok := run.timelinePeriod(date.Format("2006-01-02"))
+		if !ok {
+			return nil
+		}
+		period, ok := periods[key]
+		if !ok {
+			period = &DeliveryPeriod{Period: key}
+			periods[key] = period
+		}
+		return period
+	}
+
+	mergesArgs := append([]string{"log", "--merges", "--first-parent", "--format=%H%x1f%P%x1f%cI"}, run.gitDateRange...)
+	cmdMerges := gitCommand(repoPath, append(mergesArgs, run.branchRevision(run.mainBranch))...)
+	output, err := cmdMerges.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w, output: %s", err, output)
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Split(line, "\x1f")
+		if len(parts) != 3 {
+			continue
+		}
+		parents := strings.Fields(parts[1])
+		mergedAt, err := time.Parse(time.RFC3339, parts[2])
+		if err != nil || len(parents) < 2 {
+			continue
+		}
+
+		period := periodOf(mergedAt)
+		if period == nil {
+			continue
+		}
+		if deployMarker == DEPLOY_MARKER_MERGES {
+			period.Deployments++
+		}
+
+		firstCommit, err := earliestAuthorDate(repoPath, fmt.Sprintf("%s..%s", parents[0], parents[1]))
+		if err != nil {
+			return nil, err
+		}
+		if !firstCommit.IsZero() {
+			period.MergedChanges++
+			period.leadTimes = append(period.leadTimes, mergedAt.Sub(firstCommit).Hours()/24)
+		}
+	}
+
+	if deployMarker == DEPLOY_MARKER_TAGS {
+		cmdTags := gitCommand(repoPath, "for-each-ref", "--merged", run.branchRevision(run.mainBranch), "--format=%(creatordate:iso-strict)", "refs/tags")
+		output, err := cmdTags.CombinedOutput()
+		if err != nil {
+			return nil, fmt.Errorf("git for-each-ref failed: %w, output: %s", err, output)
+		}
+		for _, line := range strings.Fields(string(output)) {
+			taggedAt, err := time.Parse(time.RFC3339, line)
+			if err != nil {
+				continue
+			}
+			if period := periodOf(taggedAt); period != nil {
+				period.Deployments++
+			}
+		}
+	}
+
+	taggers, err := run.listReleaseTaggers(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(periods) == 0 && len(taggers) == 0 {
+		return nil, nil
+	}
+
+	metrics := &DeliveryMetrics{DeployMarker: deployMarker, Taggers: taggers}
+	keys := make([]string, 0, len(periods))
+	for key := range periods {
+		keys = append(keys, key)
+	}
+	for _, key := range sortPeriods(keys) {
+		period := periods[key]
+		period.MedianLeadTime = median(period.leadTimes)
+		metrics.Periods = append(metrics.Periods, period)
+	}
+	return metrics, nil
+}
+
+// listReleaseTaggers credits the creators of annotated tags (on any branch), since release engineering
+// is not visible in commit statistics. Lightweight tags have no tagger and are skipped.
+//
+// Returns:
+//   - The taggers ordered by number of tags, descending.
+//   - An error if git failed.
+func (run *analysisRun) listReleaseTaggers(repoPath string) ([]*ReleaseTagger, error) {
+	cmdTags := gitCommand(repoPath, "for-each-ref", "--sort=taggerdate",
+		"--format=%(objecttype)%1f%(taggeremail)%1f%(taggerdate:iso-strict)%1f%(refname:short)", "refs/tags")
+	output, err := cmdTags.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref failed: %w, output: %s", err, output)
+	}
+
+	byEmail := make(map[string]*ReleaseTagger)
+	var taggers []*ReleaseTagger
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Split(line, "\x1f")
+		if len(parts) != 4 || parts[0] != "tag" {
+			continue
+		}
+		taggedAt, err := time.Parse(time.RFC3339, parts[2])
+		if err != nil {
+			continue
+		}
+
+		email := run.config.canonicalEmail(strings.Trim(parts[1], "<>"))
+		tagger, ok := byEmail[email]
+		if !ok {
+			tagger = &ReleaseTagger{Email: email, Timeline: make(map[string]int)}
+			byEmail[email] = tagger
+			taggers = append(taggers, tagger)
+		}
+		tagger.TagCount++
+		if period, ok := run.timelinePeriod(taggedAt.Format("2006-01-02")); ok {
+			tagger.Timeline[period]++
+		}
+		// tags are sorted by date, the last one is the latest
+		tagger.LastTag = parts[3]
+		tagger.LastTagDate = taggedAt.Format("2006-01-02")
+	}
+
+	sort.SliceStable(taggers, func(i, j int) bool {
+		return taggers[i].TagCount > taggers[j].TagCount
+	})
+	return taggers, nil
+}
+
+// earliestAuthorDate returns the earliest author date of the commits in the given range.
+func earliestAuthorDate(repoPath string, logRange string) (time.Time, error) {
+	cmdLog := gitCommand(repoPath, "log", "--format=%aI", logRange)
+	output, err := cmdLog.CombinedOutput()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("git log failed: %w, output: %s", err, output)
+	}
+
+	var earliest time.Time
+	for _, line := range strings.Fields(string(output)) {
+		authoredAt, err := time.Parse(time.RFC3339, line)
+		if err == nil && (earliest.IsZero() || authoredAt.Before(earliest)) {
+			earliest = authoredAt
+		}
+	}
+	return earliest, nil
+}
+
+// median returns the median of the values, 0 if there are none.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
@@ -0,0 +1,130 @@
+package gogitstats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Files of an API dump directory, see Options.APIDump. Each file holds the responses of the corresponding API
+// as exported on a host with internet access, paginated responses may be concatenated (e.g., `gh api --paginate`).
+const DUMP_GITHUB_PULLS = "github-pulls.json"
+const DUMP_GITLAB_MERGE_REQUESTS = "gitlab-merge-requests.json"
+const DUMP_JIRA_ISSUES = "jira-issues.json"
+
+// dumpHostingClient serves merged changes from an API dump instead of the API of the hosting platform.
+type dumpHostingClient struct {
+	hosting string
+	changes []*MergedChange
+}
+
+func (dump *dumpHostingClient) provider() string {
+	return dump.hosting
+}
+
+func (dump *dumpHostingClient) mergedChanges(targetBranch string) ([]*MergedChange, error) {
+	var changes []*MergedChange
+	for _, change := range dump.changes {
+		if change.TargetBranch == targetBranch {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func (dump *dumpHostingClient) changeCommits(change *MergedChange) ([]*ChangeCommit, error) {
+	return nil, fmt.Errorf("commits of merged changes are not part of API dumps")
+}
+
+// loadGitHubDump reads the pull requests (as returned by `GET /repos/{owner}/{repo}/pulls?state=closed`) from the dump directory.
+func loadGitHubDump(dumpDirectory string) (*dumpHostingClient, error) {
+	dump := &dumpHostingClient{hosting: HOSTING_GITHUB}
+	err := decodeDumpFile(filepath.Join(dumpDirectory, DUMP_GITHUB_PULLS), func(content json.RawMessage) error {
+		var pulls []githubPullRequest
+		if err := json.Unmarshal(content, &pulls); err != nil {
+			return err
+		}
+		for _, pull := range pulls {
+			if change := pull.mergedChange(); change != nil {
+				dump.changes = append(dump.changes, change)
+			}
+		}
+		return nil
+	})
+	return dump, err
+}
+
+// loadGitLabDump reads the merge requests (as returned by `GET /projects/{id}/merge_requests?state=merged`) from the dump directory.
+func loadGitLabDump(dumpDirectory string) (*dumpHostingClient, error) {
+	dump := &dumpHostingClient{hosting: HOSTING_GITLAB}
+	err := decodeDumpFile(filepath.Join(dumpDirectory, DUMP_GITLAB_MERGE_REQUESTS), func(content json.RawMessage) error {
+		var mergeRequests []gitlabMergeRequest
+		if err := json.Unmarshal(content, &mergeRequests); err != nil {
+			return err
+		}
+		for _, mergeRequest := range mergeRequests {
+			if change := mergeRequest.mergedChange(); change != nil {
+				dump.changes = append(dump.changes, change)
+			}
+		}
+		return nil
+	})
+	return dump, err
+}
+
+// loadJiraDump reads the issues from the dump directory into an offline Jira client, issues missing in the
+// dump are reported as not found. The dump holds results of `GET /rest/api/2/search` or arrays of issues.
+func loadJiraDump(dumpDirectory string, baseURL string, epicField string) (*jiraClient, error) {
+	jira := newJiraClient(baseURL, epicField)
+	jira.offline = true
+	err := decodeDumpFile(filepath.Join(dumpDirectory, DUMP_JIRA_ISSUES), func(content json.RawMessage) error {
+		var issues []json.RawMessage
+		if strings.HasPrefix(strings.TrimSpace(string(content)), "{") {
+			var search struct {
+				Issues []json.RawMessage `json:"issues"`
+			}
+			if err := json.Unmarshal(content, &search); err != nil {
+				return err
+			}
+			issues = search.Issues
+		} else if err := json.Unmarshal(content, &issues); err != nil {
+			return err
+		}
+
+		for _, body := range issues {
+			issue, err := jira.decodeIssue(body)
+			if err != nil {
+				return err
+			}
+			jira.issues[issue.Key] = issue
+		}
+		return nil
+	})
+	return jira, err
+}
+
+// decodeDumpFile passes each JSON value of the file to decode, so pages written one after another are supported.
+func decodeDumpFile(dumpPath string, decode func(content json.RawMessage) error) error {
+	file, err := os.Open(dumpPath)
+	if err != nil {
+		return fmt.Errorf("failed to open API dump: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	for {
+		var content json.RawMessage
+		if err := decoder.Decode(&content); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read API dump %s: %w", dumpPath, err)
+		}
+		if err := decode(content); err != nil {
+			return fmt.Errorf("failed to decode API dump %s: %w", dumpPath, err)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package gogitstats
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+const REPORT_FORMAT_HTML = "html"
+const REPORT_FORMAT_JSON = "json"
+const REPORT_FORMAT_CSV = "csv"
+const REPORT_FORMAT_MARKDOWN = "markdown"
+
+// REPORT_FORMATS lists the output formats, which can be selected with `--format`.
+var REPORT_FORMATS = []string{REPORT_FORMAT_HTML, REPORT_FORMAT_JSON, REPORT_FORMAT_CSV, REPORT_FORMAT_MARKDOWN}
+
+// emailPattern matches emails in exported strings (e.g., "Jane <jane@example.com>").
+var emailPattern = regexp.MustCompile(`[^\s<>"(),]+@[^\s<>"(),]+`)
+
+// hiddenPathFields are removed from exports by output profiles hiding paths.
+var hiddenPathFields = []string{"FileFilter", "Path", "Paths", "Patterns", "FocusAreas", "TouchedFiles"}
+
+// generateJSONReport serializes the report data (branch reports, contributions, timelines and
+// repository-level results) as JSON.
+//
+// The output profile applies to the export as well: emails are replaced by pseudonyms everywhere
+// and fields holding paths are removed.
+//
+// Parameters:
+//   - branchReports: The branch reports.
+//   - repoName: The name of the repository.
+//   - fileFilter: The applied file filter.
+//
+// Returns:
+//   - The JSON document.
+//   - An error if the data could not be encoded.
+func (run *analysisRun) generateJSONReport(branchReports map[string]*BranchReport, repoName string, fileFilter string) (string, error) {
+	return run.encodeJSONReport(run.newReportData(branchReports, repoName, fileFilter))
+}
+
+// encodeJSONReport serializes the report data as JSON, applying the output profile.
+func (run *analysisRun) encodeJSONReport(data ReportData) (string, error) {
+	content, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode report: %w", err)
+	}
+
+	var document any
+	if err := json.Unmarshal(content, &document); err != nil {
+		return "", fmt.Errorf("failed to encode report: %w", err)
+	}
+	if run.profile.AnonymizeEmails || run.profile.HidePaths {
+		document = run.applyOutputProfile(document)
+	}
+
+	content, err = json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode report: %w", err)
+	}
+	return string(content), nil
+}
+
+// applyOutputProfile walks a decoded JSON document and removes or pseudonymizes what the output profile hides.
+func (run *analysisRun) applyOutputProfile(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(value))
+		for key, item := range value {
+			if run.profile.HidePaths && slices.Contains(hiddenPathFields, key) {
+				continue
+			}
+			result[run.anonymizeEmails(key)] = run.applyOutputProfile(item)
+		}
+		return result
+	case []any:
+		for i, item := range value {
+			value[i] = run.applyOutputProfile(item)
+		}
+		return value
+	case string:
+		return run.anonymizeEmails(value)
+	default:
+		return value
+	}
+}
+
+// anonymizeEmails replaces all emails in text by their pseudonyms, if the output profile anonymizes emails.
+func (run *analysisRun) anonymizeEmails(text string) string {
+	if !run.profile.AnonymizeEmails {
+		return text
+	}
+	return emailPattern.ReplaceAllStringFunc(text, run.profile.displayEmail)
+}
+
+// generateCSVReport writes one row per branch and contributor with the selected columns of the contribution
+// tables. The timeline is flattened into one column per period, so the file can be used in spreadsheets directly.
+//
+// Parameters:
+//   - branchReports: The branch reports.
+//   - fileFilter: The applied file filter.
+//
+// Returns:
+//   - The CSV document.
+//   - An error if the data could not be encoded.
+func (run *analysisRun) generateCSVReport(branchReports map[string]*BranchReport, fileFilter string) (string, error) {
+	return run.encodeCSVReport(run.newReportData(branchReports, "", fileFilter))
+}
+
+// encodeCSVReport serializes the contributions of the branch reports of the report data as CSV.
+func (run *analysisRun) encodeCSVReport(data ReportData) (string, error) {
+	branchReports := data.BranchReports
+	columns := data.Columns
+
+	branchNames := make([]string, 0, len(branchReports))
+	periodSet := make(map[string]bool)
+	for branchName, report := range branchReports {
+		branchNames = append(branchNames, branchName)
+		for _, contribution := range report.Contributions {
+			for period := range contribution.ContributionTimeline {
+				periodSet[period] = true
+			}
+		}
+	}
+	sort.Strings(branchNames)
+	var periods []string
+	if columns["timeline"] {
+		for period := range periodSet {
+			periods = append(periods, period)
+		}
+		periods = run.periodRange(sortPeriods(periods))
+	}
+
+	header := []string{"Branch"}
+	if columns["email"] {
+		header = append(header, "Email")
+	}
+	if columns["commits"] {
+		header = append(header, "Commit Count")
+	}
+	if columns["added"] {
+		header = append(header, "Lines Added")
+	}
+	if columns["removed"] {
+		header = append(header, "Lines Removed")
+	}
+	if columns["edited"] {
+		header = append(header, "Lines Edited")
+	}
+	if columns["files-per-commit"] {
+		header = append(header, "Files per Commit")
+	}
+	if columns["distinct-files"] {
+		header = append(header, "Distinct Files")
+	}
+	if columns["filter"] && !data.HidePaths {
+		header = append(header, "File Filter")
+	}
+	if columns["roles"] {
+		for _, role := range data.RoleNames {
+			header = append(header, "Role: "+role)
+		}
+	}
+	if columns["without-ticket"] && data.TicketPolicy != nil {
+		header = append(header, "Commits Without Ticket")
+	}
+	header = append(header, periods...)
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(header); err != nil {
+		return "", err
+	}
+
+	for _, branchName := range branchNames {
+		contributions := make([]*UserContribution, 0, len(branchReports[branchName].Contributions))
+		for _, contribution := range branchReports[branchName].Contributions {
+			contributions = append(contributions, contribution)
+		}
+		sort.Slice(contributions, func(i, j int) bool {
+			if contributions[i].CommitCount != contributions[j].CommitCount {
+				return contributions[i].CommitCount > contributions[j].CommitCount
+			}
+			return contributions[i].Email < contributions[j].Email
+		})
+
+		for _, contribution := range contributions {
+			row := []string{branchName}
+			if columns["email"] {
+				row = append(row, run.profile.displayEmail(contribution.Email))
+			}
+			if columns["commits"] {
+				row = append(row, strconv.Itoa(contribution.CommitCount))
+			}
+			if columns["added"] {
+				row = append(row, strconv.Itoa(contribution.LinesAdded))
+			}
+			if columns["removed"] {
+				row = append(row, strconv.Itoa(contribution.LinesRemoved))
+			}
+			if columns["edited"] {
+				row = append(row, strconv.Itoa(contribution.LinesEdited))
+			}
+			if columns["files-per-commit"] {
+				row = append(row, filesPerCommit(contribution))
+			}
+			if columns["distinct-files"] {
+				row = append(row, strconv.Itoa(distinctFiles(contribution)))
+			}
+			if columns["filter"] && !data.HidePaths {
+				row = append(row, contribution.FileFilter)
+			}
+			if columns["roles"] {
+				for _, role := range data.RoleNames {
+					row = append(row, strconv.Itoa(contribution.Roles[role]))
+				}
+			}
+			if columns["without-ticket"] && data.TicketPolicy != nil {
+				row = append(row, strconv.Itoa(contribution.CommitsWithoutTicket))
+			}
+			for _, period := range periods {
+				row = append(row, strconv.Itoa(contribution.ContributionTimeline[period]))
+			}
+			if err := writer.Write(row); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to encode report: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,308 @@
+package gogitstats
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"html/template"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var defaultChurnWindow int = 90    // days
+var defaultYoungFileDays int = 180 // days
+
+// maxQuadrantFiles limits the files listed as the hottest of each quadrant.
+const maxQuadrantFiles = 10
+
+// dimensions of the churn by file age chart in pixels.
+const fileAgeLabelWidth = 110
+const fileAgeCellWidth = 72
+const fileAgeCellHeight = 26
+
+// chartBucket is a row or a column of the churn by file age chart, holding the values up to Max.
+type chartBucket struct {
+	Max   int
+	Label string
+}
+
+// fileAgeBuckets are the rows of the chart (age in days), the buckets up to defaultYoungFileDays are young files.
+var fileAgeBuckets = []chartBucket{{30, "< 1 month"}, {90, "1-3 months"}, {180, "3-6 months"}, {365, "6-12 months"}, {730, "1-2 years"}, {math.MaxInt, "> 2 years"}}
+
+// fileChurnBuckets are the columns of the chart (lines edited within the churn window).
+var fileChurnBuckets = []chartBucket{{0, "0"}, {10, "1-10"}, {100, "11-100"}, {1000, "101-1000"}, {math.MaxInt, "> 1000"}}
+
+// FileAge is a file of the main branch with its age and recent churn.
+type FileAge struct {
+	Path    string
+	Added   string // 2006-01-02, the first commit of the file under its current path
+	AgeDays int
+	Churn   int // lines edited within the churn window
+}
+
+// FileQuadrant holds the files of a quadrant of file age (young or old) and recent churn (hot or stable).
+type FileQuadrant struct {
+	Name    string
+	Advice  string
+	Files   int
+	Churn   int
+	Hottest []*FileAge // files with the most churn, at most maxQuadrantFiles
+}
+
+// FileAgeChurn correlates the age of the files of the main branch with their recent churn: young and hot files
+// are still in development and worth tests, old and hot files keep changing and are candidates for refactoring.
+type FileAgeChurn struct {
+	End        string // 2006-01-02, the latest commit of the main branch
+	WindowDays int
+	YoungDays  int
+	HotChurn   int     // files with at least this churn are hot, the median churn of the files changed recently
+	Cells      [][]int // files by age bucket and churn bucket, see fileAgeBuckets and fileChurnBuckets
+	MaxCell    int
+	Quadrants  []*FileQuadrant // young and hot, old and hot, young and stable, old and stable
+}
+
+// assessFileAgeChurn builds the chart of churn by file age of the main branch, which is attached to the main
+// branch report.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - branchReports: The branch reports.
+//   - fileFilter: The pathspec limiting the files, ignored if empty.
+func (run *analysisRun) assessFileAgeChurn(repoPath string, branchReports map[string]*BranchReport, fileFilter string) {
+	report, ok := branchReports[run.mainBranch]
+	if !ok {
+		return
+	}
+
+	files, end, err := run.listFileAges(repoPath, fileFilter, defaultChurnWindow)
+	if err != nil {
+		log.Printf("Measuring churn by file age failed: %v", err)
+		report.FileAge = nil
+		return
+	}
+	report.FileAge = buildFileAgeChurn(files, end, defaultChurnWindow, defaultYoungFileDays)
+}
+
+// listFileAges determines the age and the recent churn of the files of the main branch. Ages are taken from the
+// full history of the main branch (regardless of --since), renamed files are followed, so they are as old as the
+// first path they have been added with (see renamedPaths).
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - fileFilter: The pathspec limiting the files, ignored if empty.
+//   - windowDays: The days before the latest commit whose lines edited are the recent churn.
+//
+// Returns:
+//   - The files existing on the main branch.
+//   - The date of the latest commit of the main branch.
+//   - An error if git failed.
+func (run *analysisRun) listFileAges(repoPath string, fileFilter string, windowDays int) ([]*FileAge, time.Time, error) {
+	tree, err := gitCommand(repoPath, "ls-tree", "-r", "-z", "--name-only", run.branchRevision(run.mainBranch)).Output()
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("git ls-tree failed: %w", err)
+	}
+	existing := make(map[string]bool)
+	for _, path := range strings.Split(string(tree), "\x00") {
+		if path != "" {
+			existing[path] = true
+		}
+	}
+
+	args := []string{"log", "--no-merges", "--numstat", "--format=%x1e%H%x1f%ct", run.branchRevision(run.mainBranch)}
+	args = append(args, pathspecArgs(fileFilter)...)
+	cmd := gitCommand(repoPath, args...)
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to open git log output: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to start git log: %w", err)
+	}
+
+	// commits are listed newest first, so the first commit is the latest and the last commit of a file added it
+	var end, windowStart, commitTime time.Time
+	excluded := false
+	files := make(map[string]*FileAge)
+	added := make(map[string]time.Time)
+	renames := make(renamedPaths)
+	scanner := bufio.NewScanner(output)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if header, ok := strings.CutPrefix(line, "\x1e"); ok {
+			hash, seconds, _ := strings.Cut(header, "\x1f")
+			timestamp, err := strconv.ParseInt(seconds, 10, 64)
+			if err != nil {
+				continue
+			}
+			commitTime = time.Unix(timestamp, 0).UTC()
+			excluded = run.config.excludesCommit(hash)
+			if end.IsZero() {
+				end = commitTime
+				windowStart = end.AddDate(0, 0, -windowDays)
+			}
+			continue
+		}
+		change, ok := parseNumstatLine(line)
+		if !ok || end.IsZero() {
+			continue
+		}
+		filePath := renames.follow(change)
+		if !existing[filePath] {
+			continue
+		}
+		file, ok := files[filePath]
+		if !ok {
+			file = &FileAge{Path: filePath}
+			files[filePath] = file
+		}
+		added[filePath] = commitTime
+		if !excluded && commitTime.After(windowStart) {
+			file.Churn += change.Added + change.Removed
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		_ = cmd.Wait()
+		return nil, time.Time{}, fmt.Errorf("failed to read git log output: %w", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("git log failed: %w", err)
+	}
+
+	var ages []*FileAge
+	for path, file := range files {
+		file.Added = added[path].Format("2006-01-02")
+		file.AgeDays = int(end.Sub(added[path]).Hours() / 24)
+		ages = append(ages, file)
+	}
+	return ages, end, nil
+}
+
+// buildFileAgeChurn counts the files per age and churn bucket and sorts them into quadrants.
+//
+// Parameters:
+//   - files: The files with their age and recent churn, see listFileAges.
+//   - end: The date of the latest commit.
+//   - windowDays: The days of the churn window.
+//   - youngDays: The age up to which files are young.
+//
+// Returns:
+//   - The chart, nil if there are no files.
+func buildFileAgeChurn(files []*FileAge, end time.Time, windowDays int, youngDays int) *FileAgeChurn {
+	if len(files) == 0 {
+		return nil
+	}
+
+	var churns []int
+	for _, file := range files {
+		if file.Churn > 0 {
+			churns = append(churns, file.Churn)
+		}
+	}
+	hotChurn := 1
+	if len(churns) > 0 {
+		sort.Ints(churns)
+		hotChurn = max(churns[len(churns)/2], 1)
+	}
+
+	chart := &FileAgeChurn{
+		End:        end.Format("2006-01-02"),
+		WindowDays: windowDays,
+		YoungDays:  youngDays,
+		HotChurn:   hotChurn,
+		Cells:      make([][]int, len(fileAgeBuckets)),
+		Quadrants: []*FileQuadrant{
+			{Name: "Young and hot", Advice: "New code under active development, invest in tests while it settles"},
+			{Name: "Old and hot", Advice: "Old code which keeps changing, candidates for refactoring"},
+			{Name: "Young and stable", Advice: "New code which settled quickly"},
+			{Name: "Old and stable", Advice: "Mature code, low priority for refactoring and new tests"},
+		},
+	}
+	for i := range chart.Cells {
+		chart.Cells[i] = make([]int, len(fileChurnBuckets))
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Churn != files[j].Churn {
+			return files[i].Churn > files[j].Churn
+		}
+		return files[i].Path < files[j].Path
+	})
+	for _, file := range files {
+		row, column := bucketIndex(fileAgeBuckets, file.AgeDays), bucketIndex(fileChurnBuckets, file.Churn)
+		chart.Cells[row][column]++
+		chart.MaxCell = max(chart.MaxCell, chart.Cells[row][column])
+
+		quadrant := 0
+		if file.AgeDays > youngDays {
+			quadrant = 1
+		}
+		if file.Churn < hotChurn {
+			quadrant += 2
+		}
+		chart.Quadrants[quadrant].Files++
+		chart.Quadrants[quadrant].Churn += file.Churn
+		if quadrant < 2 && len(chart.Quadrants[quadrant].Hottest) < maxQuadrantFiles {
+			chart.Quadrants[quadrant].Hottest = append(chart.Quadrants[quadrant].Hottest, file)
+		}
+	}
+	return chart
+}
+
+// bucketIndex returns the index of the first bucket holding the value.
+func bucketIndex(buckets []chartBucket, value int) int {
+	for i, bucket := range buckets {
+		if value <= bucket.Max {
+			return i
+		}
+	}
+	return len(buckets) - 1
+}
+
+// fileAgeChart renders the churn by file age as inline SVG: a row per age bucket (youngest first) and a column per
+// churn bucket, each cell colored and labeled by its number of files. A dashed line separates young from old files.
+func (run *analysisRun) fileAgeChart(chart *FileAgeChurn) template.HTML {
+	if chart == nil || chart.MaxCell == 0 {
+		return ""
+	}
+
+	width := fileAgeLabelWidth + len(fileChurnBuckets)*fileAgeCellWidth
+	height := (len(fileAgeBuckets) + 1) * fileAgeCellHeight
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg class="file-age-chart" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="%s">`,
+		width, height, width, height, html.EscapeString(run.translate("Churn by file age")))
+	for column, bucket := range fileChurnBuckets {
+		fmt.Fprintf(&svg, `<text x="%d" y="%d" text-anchor="middle" font-size="12" style="fill: var(--bs-body-color)">%s</text>`,
+			fileAgeLabelWidth+column*fileAgeCellWidth+fileAgeCellWidth/2, fileAgeCellHeight-8, html.EscapeString(bucket.Label))
+	}
+	for row, bucket := range fileAgeBuckets {
+		y := (row + 1) * fileAgeCellHeight
+		fmt.Fprintf(&svg, `<text x="0" y="%d" font-size="12" style="fill: var(--bs-body-color)">%s</text>`,
+			y+fileAgeCellHeight-8, html.EscapeString(run.translate(bucket.Label)))
+		for column, count := range chart.Cells[row] {
+			level := 0
+			if count > 0 {
+				level = min((count*4+chart.MaxCell-1)/chart.MaxCell, 4)
+			}
+			x := fileAgeLabelWidth + column*fileAgeCellWidth
+			fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="currentColor" fill-opacity="%.2f"><title>%s, %s: %d</title></rect>`,
+				x+1, y+1, fileAgeCellWidth-2, fileAgeCellHeight-2, HEATMAP_OPACITIES[level],
+				html.EscapeString(run.translate(bucket.Label)), html.EscapeString(fileChurnBuckets[column].Label), count)
+			if count > 0 {
+				fmt.Fprintf(&svg, `<text x="%d" y="%d" text-anchor="middle" font-size="12" style="fill: var(--bs-body-color)">%d</text>`,
+					x+fileAgeCellWidth/2, y+fileAgeCellHeight-8, count)
+			}
+		}
+		if bucket.Max == chart.YoungDays {
+			fmt.Fprintf(&svg, `<line x1="0" y1="%d" x2="%d" y2="%d" stroke="currentColor" stroke-dasharray="4 3"/>`,
+				y+fileAgeCellHeight, width, y+fileAgeCellHeight)
+		}
+	}
+	svg.WriteString(`</svg>`)
+
+	return template.HTML(svg.String())
+}
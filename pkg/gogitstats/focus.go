@@ -0,0 +1,108 @@
+package gogitstats
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// sparklineWidth and sparklineHeight are the dimensions of trend charts in pixels.
+const sparklineWidth = 120
+const sparklineHeight = 24
+
+type FocusPoint struct {
+	Period string
+	Areas  int
+	Files  int
+}
+
+// focusArea returns the area of the repository a changed file belongs to.
+//
+// With components configured, files matching a path rule belong to that component. All other files
+// belong to the directory formed by their first focusDepth directories (e.g., "src/billing"),
+// files in the repository root to ".".
+func (run *analysisRun) focusArea(components *ComponentConfig, filePath string) string {
+	if components != nil {
+		for _, rule := range components.Paths {
+			if matchPathPattern(rule.Pattern, filePath) {
+				return "component:" + rule.Component
+			}
+		}
+	}
+
+	directories := strings.Split(filePath, "/")
+	directories = directories[:len(directories)-1]
+	if len(directories) == 0 {
+		return "."
+	}
+	if len(directories) > run.focusDepth {
+		directories = directories[:run.focusDepth]
+	}
+	return strings.Join(directories, "/")
+}
+
+// addFocusAreas records the areas touched by a commit in the period of the commit.
+func (run *analysisRun) addFocusAreas(contribution *UserContribution, commit CommitRecord, period string) {
+	if period == "" || len(commit.Files) == 0 {
+		return
+	}
+
+	areas, ok := contribution.FocusAreas[period]
+	if !ok {
+		areas = make(map[string]bool)
+		contribution.FocusAreas[period] = areas
+	}
+	for _, change := range commit.Files {
+		areas[run.focusArea(run.config.Components, change.Path)] = true
+	}
+}
+
+// focusTrend returns the number of distinct areas and files a contributor touched in every active period, oldest first.
+//
+// Parameters:
+//   - contribution: The contribution of the author.
+//
+// Returns:
+//   - The number of areas and files per period; the more areas, the more the author switched context.
+func focusTrend(contribution *UserContribution) []FocusPoint {
+	periods := make([]string, 0, len(contribution.FocusAreas))
+	for period := range contribution.FocusAreas {
+		periods = append(periods, period)
+	}
+
+	var trend []FocusPoint
+	for _, period := range sortPeriods(periods) {
+		trend = append(trend, FocusPoint{Period: period, Areas: len(contribution.FocusAreas[period]), Files: len(contribution.TouchedFiles[period])})
+	}
+	return trend
+}
+
+// sparkline renders a series of values as a small inline SVG line chart.
+func sparkline(points []FocusPoint) template.HTML {
+	if len(points) == 0 {
+		return ""
+	}
+
+	maxValue := 1
+	for _, point := range points {
+		maxValue = max(maxValue, point.Areas)
+	}
+
+	var coordinates []string
+	step := 0.0
+	if len(points) > 1 {
+		step = float64(sparklineWidth-2) / float64(len(points)-1)
+	}
+	for i, point := range points {
+		x := 1 + step*float64(i)
+		y := float64(sparklineHeight-1) - float64(point.Areas)*float64(sparklineHeight-2)/float64(maxValue)
+		coordinates = append(coordinates, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+	if len(coordinates) == 1 {
+		coordinates = append(coordinates, fmt.Sprintf("%d,%s", sparklineWidth-1, strings.Split(coordinates[0], ",")[1]))
+	}
+
+	return template.HTML(fmt.Sprintf(`<svg width="%d" height="%d" viewBox="0 0 %d %d" aria-hidden="true">`+
+		`<polyline fill="none" stroke="currentColor" stroke-width="1.5" points="%s"/></svg>`,
+		sparklineWidth, sparklineHeight, sparklineWidth, sparklineHeight, strings.Join(coordinates, " ")))
+}
@@ -0,0 +1,110 @@
+package gogitstats
+
+import (
+	"math"
+)
+
+// forecastSmoothingLevel and forecastSmoothingTrend are the smoothing factors of the level and the trend
+// (Holt's linear exponential smoothing).
+const forecastSmoothingLevel = 0.5
+const forecastSmoothingTrend = 0.3
+
+// forecastMinHistory is the minimum number of periods required for a forecast.
+const forecastMinHistory = 3
+
+// ForecastValue is a forecast with its 95% prediction interval.
+type ForecastValue struct {
+	Value float64
+	Low   float64
+	High  float64
+}
+
+type ForecastPeriod struct {
+	Period      string
+	Commits     ForecastValue
+	LinesEdited ForecastValue
+}
+
+// ActivityForecast extrapolates the activity (commits and churn) of a branch.
+type ActivityForecast struct {
+	HistoryPeriods int
+	Periods        []*ForecastPeriod
+}
+
+// forecastBranchActivity extrapolates commits and lines edited of every branch for the next periods.
+func (run *analysisRun) forecastBranchActivity(branchReports map[string]*BranchReport, periods int) {
+	for _, report := range branchReports {
+		report.Forecast = nil
+		if periods > 0 {
+			report.Forecast = run.forecastActivity(report, periods)
+		}
+	}
+}
+
+// forecastActivity extrapolates the activity of a branch for the given number of periods.
+//
+// The history covers all periods from the first to the last period with commits (periods without
+// commits count as zero). Returns nil if the history is shorter than forecastMinHistory periods.
+func (run *analysisRun) forecastActivity(report *BranchReport, periods int) *ActivityForecast {
+	commitsByPeriod := make(map[string]int)
+	for _, contribution := range report.Contributions {
+		for period, count := range contribution.ContributionTimeline {
+			commitsByPeriod[period] += count
+		}
+	}
+
+	history := run.periodRange(sortedPeriods(commitsByPeriod))
+	if len(history) < forecastMinHistory {
+		return nil
+	}
+
+	commits := make([]float64, len(history))
+	linesEdited := make([]float64, len(history))
+	for i, period := range history {
+		commits[i] = float64(commitsByPeriod[period])
+		linesEdited[i] = float64(report.PeriodChurn[period])
+	}
+
+	commitsForecast := smoothForecast(commits, periods)
+	linesForecast := smoothForecast(linesEdited, periods)
+
+	forecast := &ActivityForecast{HistoryPeriods: len(history)}
+	for i, period := range run.followingPeriods(history[len(history)-1], periods) {
+		forecast.Periods = append(forecast.Periods, &ForecastPeriod{
+			Period:      period,
+			Commits:     commitsForecast[i],
+			LinesEdited: linesForecast[i],
+		})
+	}
+	return forecast
+}
+
+// smoothForecast forecasts the next values of a series with Holt's linear exponential smoothing.
+//
+// The prediction interval is derived from the standard deviation of the one-step-ahead errors
+// and widens with the forecast horizon. Values are never negative.
+func smoothForecast(series []float64, horizon int) []ForecastValue {
+	level, trend := series[0], series[1]-series[0]
+	var squaredErrors float64
+	for _, value := range series[1:] {
+		predicted := level + trend
+		squaredErrors += (value - predicted) * (value - predicted)
+
+		previousLevel := level
+		level = forecastSmoothingLevel*value + (1-forecastSmoothingLevel)*(level+trend)
+		trend = forecastSmoothingTrend*(level-previousLevel) + (1-forecastSmoothingTrend)*trend
+	}
+	deviation := math.Sqrt(squaredErrors / float64(len(series)-1))
+
+	forecast := make([]ForecastValue, horizon)
+	for h := 1; h <= horizon; h++ {
+		value := level + float64(h)*trend
+		spread := 1.96 * deviation * math.Sqrt(float64(h))
+		forecast[h-1] = ForecastValue{
+			Value: math.Max(0, value),
+			Low:   math.Max(0, value-spread),
+			High:  math.Max(0, value+spread),
+		}
+	}
+	return forecast
+}
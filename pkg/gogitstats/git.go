@@ -0,0 +1,114 @@
+package gogitstats
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// gitConfigOverrides are passed to every git invocation, so results do not depend on the platform
+// or on the configuration of the repository.
+//
+//   - --no-pager: output is never piped into a pager.
+//   - core.longpaths: checkouts of paths longer than 260 characters do not fail on Windows.
+//   - core.quotepath: non-ASCII paths are printed as UTF-8 instead of octal escapes.
+//   - i18n.logOutputEncoding: commit messages are printed as UTF-8 regardless of the console code page.
+//   - core.hooksPath: hooks (e.g., post-checkout) are not run.
+//   - color.ui, log.showSignature: no escape sequences or signature details mixed into parsed output.
+var gitConfigOverrides = []string{
+	"--no-pager",
+	"-c", "core.longpaths=true",
+	"-c", "core.quotepath=false",
+	"-c", "i18n.logOutputEncoding=UTF-8",
+	"-c", "core.hooksPath=" + os.DevNull,
+	"-c", "color.ui=never",
+	"-c", "log.showSignature=false",
+}
+
+// gitPassthroughVariables are the GIT_* environment variables kept for git invocations.
+// They only affect authentication and tracing, all other variables (e.g., GIT_DIR, GIT_WORK_TREE,
+// GIT_CONFIG_PARAMETERS) could point git to another repository or alter its output.
+var gitPassthroughVariables = []string{
+	"GIT_ASKPASS", "GIT_SSH", "GIT_SSH_COMMAND", "GIT_SSH_VARIANT", "GIT_TERMINAL_PROMPT",
+	"GIT_TRACE", "GIT_TRACE2", "GIT_TRACE_PERFORMANCE",
+}
+
+// gitCommand prepares a git command running in the repository located at repoPath.
+//
+// The command runs in an isolated environment: the system and global git configuration
+// (e.g., log.date, diff.renames, aliases) as well as hooks are ignored, so analysis results
+// do not differ between machines.
+//
+// Parameters:
+//   - repoPath: The working directory of the command, the current directory if empty.
+//   - args: The git subcommand and its arguments.
+//
+// Returns:
+//   - The prepared command.
+func gitCommand(repoPath string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", append(append([]string{}, gitConfigOverrides...), args...)...)
+	cmd.Dir = repoPath
+	cmd.Env = gitEnvironment(true)
+	return cmd
+}
+
+// gitRemoteCommand prepares a git command accessing a remote repository (e.g., clone).
+//
+// Unlike gitCommand, the global configuration is kept, since it usually holds credential helpers,
+// proxies and URL rewrites needed to reach the remote. Access tokens of the environment are injected
+// into HTTPS URLs of the host of remoteURL, see remoteTokenEnvironment.
+func gitRemoteCommand(repoPath string, remoteURL string, args ...string) *exec.Cmd {
+	cmd := gitCommand(repoPath, args...)
+	cmd.Env = append(gitEnvironment(false), remoteTokenEnvironment(remoteURL)...)
+	return cmd
+}
+
+// gitVerifyCommand prepares a git command verifying signatures (e.g., verify-tag).
+//
+// Like gitRemoteCommand, the global configuration is kept, since it holds the trust settings
+// (e.g., gpg.program, gpg.ssh.allowedSignersFile) needed to verify signatures.
+func gitVerifyCommand(repoPath string, args ...string) *exec.Cmd {
+	cmd := gitCommand(repoPath, args...)
+	cmd.Env = gitEnvironment(false)
+	return cmd
+}
+
+// gitEnvironment returns the environment of the current process without GIT_* variables,
+// which could change which repository git operates on or how it behaves. The locale is set to C,
+// so messages git prints (and which are matched, e.g., "already exists") are not translated.
+//
+// Parameters:
+//   - isolateConfig: Whether the system and global git configuration are ignored
+//     (GIT_CONFIG_GLOBAL requires git 2.32 or newer, older versions still read it).
+func gitEnvironment(isolateConfig bool) []string {
+	var env []string
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		name = strings.ToUpper(name) // environment variables are case-insensitive on Windows
+		if strings.HasPrefix(name, "GIT_") && !slices.Contains(gitPassthroughVariables, name) {
+			continue
+		}
+		if name == "LANG" || name == "LANGUAGE" || strings.HasPrefix(name, "LC_") {
+			continue
+		}
+		env = append(env, variable)
+	}
+	env = append(env, "LC_ALL=C")
+
+	if isolateConfig {
+		env = append(env, "GIT_CONFIG_NOSYSTEM=1", "GIT_CONFIG_GLOBAL="+os.DevNull)
+	}
+	return env
+}
+
+// sanitizeDirectoryName replaces characters, which are not allowed in file names on Windows.
+func sanitizeDirectoryName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 32 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	return strings.TrimRight(name, ". ")
+}
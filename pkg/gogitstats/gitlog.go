@@ -0,0 +1,385 @@
+package gogitstats
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLogLineSize is the upper bound for a single line of `git log` output kept in memory.
+const maxLogLineSize = 1024 * 1024
+
+// gitLogFormat is the format of commit headers parsed by streamGitLog: a record separator
+// followed by the fields separated by unit separators, which do not occur in emails or file paths.
+// Trailers (e.g., "Component: billing") are separated by group separators.
+const gitLogFormat = "--pretty=format:%x1e%ae%x1f%ad%x1f%H%x1f%(trailers:only,unfold,separator=%x1d)%x1f%s"
+
+// gitLogDateFormat is the date format of commit headers, independent of the log.date setting.
+const gitLogDateFormat = "--date=short"
+
+// dateLimitPattern matches the limits of the analyzed period given with `--since` and `--until`:
+// dates (e.g., 2024-01-01) or values relative to now (e.g., 6.months).
+var dateLimitPattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}([ T]\d{2}:\d{2}(:\d{2})?)?|\d+\.(second|minute|hour|day|week|month|year)s?(\.ago)?)$`)
+
+// dayLimitPattern matches limits given as a day without a time of day, which git would complete with the current
+// time of day, and relativeLimitPattern matches limits relative to now, see dateLimitPattern.
+var dayLimitPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+var relativeLimitPattern = regexp.MustCompile(`^\d+\.`)
+
+type FileChange struct {
+	Path    string
+	OldPath string // set if the file has been renamed by the commit
+	Added   int
+	Removed int
+	Binary  bool
+}
+
+type CommitRecord struct {
+	Hash     string
+	Email    string
+	Date     string
+	Subject  string
+	Trailers []string // "Key: value" lines of the trailer block
+	Files    []FileChange
+	// SkippedLines counts the numstat lines of the commit which could not be parsed, the commit is counted without them
+	SkippedLines int
+}
+
+// LogParseStats describes how complete the statistics of a branch are: the git log output which could not be
+// parsed and was skipped, and a failure of git log.
+type LogParseStats struct {
+	SkippedCommits int    // commits whose header could not be parsed, they are not counted
+	SkippedLines   int    // numstat and other lines which could not be parsed
+	LogError       string // the failure of git log, only the commits read until then are counted
+}
+
+// incomplete reports whether git log output has been skipped or git log failed.
+func (stats LogParseStats) incomplete() bool {
+	return stats.SkippedCommits > 0 || stats.SkippedLines > 0 || stats.LogError != ""
+}
+
+// gitLogArgs composes the arguments of a `git log` command parsed by streamGitLog.
+//
+// Parameters:
+//   - logRange: The revision range, e.g. a branch or "<merge-base>..<branch>".
+//   - fileFilter: The pathspec limiting the commits and files, ignored if empty.
+//
+// Returns:
+//   - The arguments following `git`.
+func (run *analysisRun) gitLogArgs(logRange string, fileFilter string) []string {
+	return append([]string{"log", gitLogFormat, gitLogDateFormat, "--numstat"}, run.gitLogLimits(logRange, fileFilter)...)
+}
+
+// gitLogLimits composes the arguments of a `git log` command selecting the commits of the analysis: the
+// revision range, the period, the pathspec and whether merges are excluded.
+func (run *analysisRun) gitLogLimits(logRange string, fileFilter string) []string {
+	var args []string
+	if run.excludeMerges {
+		args = append(args, "--no-merges")
+	}
+	args = append(args, run.gitDateRange...)
+	// a range may list several revisions separated by spaces, which ref names cannot contain
+	args = append(args, strings.Fields(logRange)...)
+	args = append(args, pathspecArgs(fileFilter)...)
+	return args
+}
+
+// resolveDateRange resolves the limits of the analyzed period to timestamps, so all git commands of an analysis
+// cover the same period even if relative values (e.g., 6.months) are given. A day without a time of day starts
+// the period at its midnight and ends it at the midnight ending the day, so the period covers whole days.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - since: The start of the period (see dateLimitPattern), ignored if empty.
+//   - until: The end of the period, ignored if empty.
+//
+// Returns:
+//   - The arguments limiting `git log` to the period (e.g., --max-age=1704067200), nil if no limit is given.
+//   - An error if git failed.
+func resolveDateRange(repoPath string, since string, until string) ([]string, error) {
+	var args []string
+	if since != "" {
+		if dayLimitPattern.MatchString(since) {
+			since += " 00:00:00"
+		}
+		args = append(args, "--since="+since)
+	}
+	if until != "" {
+		if dayLimitPattern.MatchString(until) {
+			until += " 23:59:59"
+		}
+		args = append(args, "--until="+until)
+	}
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	cmd := gitCommand(repoPath, append([]string{"rev-parse"}, args...)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-parse for the period failed: %w, output: %s", err, output)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// dateRangeKey describes the analyzed period for cache keys by the limits as given rather than by their resolved
+// timestamps (see resolveDateRange), which differ by the second for relative limits (e.g., 6.months). Relative
+// limits are keyed by the current day, so cached results cover the period of the day they were produced.
+func (run *analysisRun) dateRangeKey() string {
+	key := run.since + ".." + run.until
+	if relativeLimitPattern.MatchString(run.since) || relativeLimitPattern.MatchString(run.until) {
+		key += "@" + time.Now().Format("2006-01-02")
+	}
+	return key
+}
+
+// resolveRevRange resolves a revision range to commit hashes, so all git commands of an analysis cover the same
+// commits even if tags or branches of the range move.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - revRange: The revision range (e.g., v1.2.0..v1.3.0, main~20..main or a single revision), ignored if empty.
+//
+// Returns:
+//   - The revisions of the range (e.g., <hash> ^<hash>), nil if no range is given.
+//   - An error if the range names an unknown revision.
+func resolveRevRange(repoPath string, revRange string) ([]string, error) {
+	if revRange == "" {
+		return nil, nil
+	}
+	// the separator makes git reject revisions which do not exist instead of taking them for paths
+	cmd := gitCommand(repoPath, append(append([]string{"rev-parse", "--revs-only"}, strings.Fields(revRange)...), "--")...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-parse for the revision range '%s' failed: %w, output: %s", revRange, err, strings.TrimSpace(string(output)))
+	}
+	revisions := slices.DeleteFunc(strings.Fields(string(output)), func(revision string) bool {
+		return revision == "--"
+	})
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("revision range '%s' names no revision", revRange)
+	}
+	return revisions, nil
+}
+
+// analyzedRevisions returns the revisions whose commits are analyzed, as a log range: the revision range if given,
+// or else the analyzed branches.
+func (run *analysisRun) analyzedRevisions(branchReports map[string]*BranchReport) string {
+	if run.gitRevRange != nil {
+		return strings.Join(run.gitRevRange, " ")
+	}
+	branchNames := make([]string, 0, len(branchReports))
+	for branchName := range branchReports {
+		branchNames = append(branchNames, run.branchRevision(branchName))
+	}
+	return strings.Join(branchNames, " ")
+}
+
+// streamGitLog runs a prepared `git log` command and parses its output while it is being produced.
+//
+// Parsed commits are collected into a batch of at most batchSize records. Every time the batch is
+// full it is handed over to handleBatch and the underlying memory is reused for the next commits,
+// so memory usage stays flat regardless of the size of the history.
+//
+// Parameters:
+//   - cmd: The prepared `git log` command (with gitLogFormat and --numstat output).
+//   - batchSize: The maximum number of commits kept in memory at once.
+//   - handleBatch: The function aggregating a batch of commits. The batch must not be retained.
+//   - stats: Counts the skipped commit headers and the skipped lines outside of commits, ignored if nil. Skipped
+//     numstat lines are counted by the commits (see CommitRecord.SkippedLines).
+//
+// Returns:
+//   - An error if the command could not be run or its output could not be read, or an unparsable line in strict mode.
+func (run *analysisRun) streamGitLog(cmd *exec.Cmd, batchSize int, handleBatch func([]CommitRecord), stats *LogParseStats) error {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open git log output: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start git log: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+
+	batch := make([]CommitRecord, 0, batchSize)
+	var current *CommitRecord
+
+	// skip counts an unparsable line, or fails in strict mode
+	skip := func(line string, header bool) error {
+		if err := run.unparsableLogLine(line); err != nil {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+			return err
+		}
+		switch {
+		case header:
+			current = nil // the numstat lines of the skipped commit follow
+			if stats != nil {
+				stats.SkippedCommits++
+			}
+		case current != nil:
+			current.SkippedLines++
+		case stats != nil:
+			stats.SkippedLines++
+		}
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\x1e") {
+			parts := strings.SplitN(line[1:], "\x1f", 5)
+			if len(parts) < 3 {
+				if err := skip(line, true); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if len(batch) == batchSize {
+				handleBatch(batch)
+				batch = batch[:0]
+			}
+
+			// reuse the slot (and its file slice) left over from the previous batch
+			batch = batch[:len(batch)+1]
+			current = &batch[len(batch)-1]
+			current.Email = parts[0]
+			current.Date = parts[1]
+			current.Hash = parts[2]
+			current.Subject = ""
+			current.SkippedLines = 0
+			current.Trailers = current.Trailers[:0]
+			if len(parts) == 5 {
+				if parts[3] != "" {
+					current.Trailers = append(current.Trailers, strings.Split(parts[3], "\x1d")...)
+				}
+				current.Subject = parts[4]
+			}
+			current.Files = current.Files[:0]
+		} else if strings.Contains(line, "\t") && current != nil {
+			change, ok := parseNumstatLine(line)
+			if !ok {
+				if err := skip(line, false); err != nil {
+					return err
+				}
+				continue
+			}
+			current.Files = append(current.Files, change)
+		} else if line != "" {
+			if err := skip(line, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("failed to read git log output: %w", err)
+	}
+
+	if len(batch) > 0 {
+		handleBatch(batch)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("%w, stderr: %s", err, stderr.String())
+	}
+
+	return nil
+}
+
+// parseNumstatLine parses a line of `git log --numstat` output: lines added, lines removed (both "-" for binary
+// files) and the path.
+func parseNumstatLine(line string) (FileChange, bool) {
+	parts := strings.Split(line, "\t")
+	if len(parts) != 3 {
+		return FileChange{}, false
+	}
+	change := FileChange{}
+	change.OldPath, change.Path = splitRenamePath(parts[2])
+	if parts[0] == "-" || parts[1] == "-" {
+		change.Binary = true
+		return change, true
+	}
+	var addedErr, removedErr error
+	change.Added, addedErr = strconv.Atoi(parts[0])
+	change.Removed, removedErr = strconv.Atoi(parts[1])
+	return change, addedErr == nil && removedErr == nil
+}
+
+// trailerValues returns the values of all trailers of the commit with the given key (case-insensitive).
+func (commit CommitRecord) trailerValues(key string) []string {
+	var values []string
+	for _, trailer := range commit.Trailers {
+		name, value, found := strings.Cut(trailer, ":")
+		if found && strings.EqualFold(strings.TrimSpace(name), key) {
+			values = append(values, strings.TrimSpace(value))
+		}
+	}
+	return values
+}
+
+// splitRenamePath splits a numstat path into the path before and after a rename.
+//
+// Renames are reported by git either as "old => new" or in the compact form "dir/{old => new}/file".
+// For paths without a rename the old path is empty.
+func splitRenamePath(numstatPath string) (string, string) {
+	if !strings.Contains(numstatPath, " => ") {
+		return "", numstatPath
+	}
+
+	start := strings.Index(numstatPath, "{")
+	end := strings.LastIndex(numstatPath, "}")
+	if start < 0 || end < start {
+		parts := strings.SplitN(numstatPath, " => ", 2)
+		return parts[0], parts[1]
+	}
+
+	prefix, suffix := numstatPath[:start], numstatPath[end+1:]
+	parts := strings.SplitN(numstatPath[start+1:end], " => ", 2)
+	if len(parts) != 2 {
+		return "", numstatPath
+	}
+
+	join := func(middle string) string {
+		joined := prefix + middle + suffix
+		return strings.ReplaceAll(joined, "//", "/")
+	}
+	return join(parts[0]), join(parts[1])
+}
+
+// renamedPaths maps the paths of renamed files to the paths they have been renamed to later, so the history of a
+// file is followed across renames (also along chains of renames) instead of being split by its paths.
+//
+// Changes must be followed in the order git log lists the commits, newest first: once a rename is seen, the
+// changes of earlier commits to the old path are taken for the current path of the file.
+type renamedPaths map[string]string
+
+// follow returns the current path of the changed file and records its rename, if any.
+func (renames renamedPaths) follow(change FileChange) string {
+	filePath := change.Path
+	if renamed, ok := renames[filePath]; ok {
+		filePath = renamed
+	}
+	if change.OldPath != "" {
+		renames[change.OldPath] = filePath
+	}
+	return filePath
+}
@@ -0,0 +1,2021 @@
+package gogitstats
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"maps"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// REPORT_COLUMNS lists the columns of the contribution tables, which can be selected with `--columns`.
+var REPORT_COLUMNS = []string{"email", "commits", "timeline", "added", "removed", "edited", "files-per-commit", "distinct-files", "filter", "roles", "without-ticket"}
+
+// CONTRIBUTION_SORT_KEYS lists the orders of the contribution tables, which can be selected with `--sort-by`.
+var CONTRIBUTION_SORT_KEYS = []string{"commits", "lines-added", "lines-edited", "email"}
+
+// OTHERS_CONTRIBUTOR is the email of the row summing up the contributors beyond the top contributors, see Options.Top.
+const OTHERS_CONTRIBUTOR = "others"
+
+// REPORT_SECTIONS lists the optional sections of the report, which can be selected with `--sections`.
+var REPORT_SECTIONS = []string{"summary", "totals", "branch-health", "naming", "protected-paths", "delivery", "signatures", "reviews", "overlap", "contention", "pairing", "timelines", "backports", "imports", "compliance", "categories", "forecast", "components", "focus", "initiatives", "departments", "teams", "local-activity", "leaderboard", "heatmap", "file-age", "themes", "paths"}
+
+const REPOSITORIES_DIRECTORY = ".repositories"
+
+type UserContribution struct {
+	Email                string
+	CommitCount          int
+	ContributionTimeline map[string]int // Year-Week: count
+	LinesAdded           int
+	LinesRemoved         int
+	LinesEdited          int
+	FileFilter           string
+	Roles                map[string]int // Role: lines edited
+	CommitsWithoutTicket int
+	FocusAreas           map[string]map[string]bool // Period: directories or components touched
+	FilesTouched         int                        // Sum of the files touched by each commit
+	TouchedFiles         map[string]map[string]bool // Period: files touched
+	Terms                map[string]map[string]int  // Period: term of commit subjects: commits, see subjectTerms
+	Others               int                        // contributors summed up in this row, see topContributions
+}
+
+type BranchReport struct {
+	BranchName    string
+	Contributions map[string]*UserContribution
+	Tickets       map[string]*TicketContribution
+	Compliance    map[string]*PeriodCompliance // Period: ticket reference compliance
+	Categories    map[string]*CategoryReport   // Category key: changes in the paths of the category
+	Components    map[string]*ComponentReport  // Component name: commits attributed by trailer or path
+	PeriodChurn   map[string]int               // Period: lines edited
+	Paths         map[string]*PathContribution // Directory or file: changes by author, see Options.PathBreakdown
+	renamedPaths  renamedPaths                 // followed by the path breakdown, see addPathContributions
+	Initiatives   []*InitiativeWork            // not cached
+	Divergence    *BranchDivergence
+	Backports     *BackportCoverage
+	Imports       []*ImportCommit // Initial imports and vendoring, see Options.Imports
+	Funnel        CommitFunnel    // Commits read and removed by the analysis, see diagnoseEmptyBranch
+	Parsing       LogParseStats   // Git log output skipped while parsing, see LogParseStats
+	// repository-level results are set for the main branch only and exported as part of ReportData
+	Delivery      *DeliveryMetrics     `json:"-"`
+	Reviews       *ReviewLatency       `json:"-"`
+	Contention    *ContentionReport    `json:"-"`
+	Pairing       *PairingReport       `json:"-"`
+	Signatures    *SignatureReport     `json:"-"`
+	LocalActivity *LocalActivity       `json:"-"`
+	Leaderboard   *Leaderboard         `json:"-"`
+	Heatmap       *Heatmap             `json:"-"`
+	FileAge       *FileAgeChurn        `json:"-"`
+	Totals        *RepositoryTotals    `json:"-"`
+	Naming        *BranchNamingReport  `json:"-"`
+	Protected     *ProtectedPathReport `json:"-"`
+	Departments   []*DepartmentRollup  `json:"-"`
+	Forecast      *ActivityForecast    // not cached
+}
+
+type ReportData struct {
+	RepoName      string
+	MainBranch    string
+	GroupBy       string
+	Language      string
+	Theme         string
+	TableTheme    string
+	Columns       map[string]bool
+	Sections      map[string]bool
+	ShowLines     bool
+	HidePaths     bool
+	PathBreakdown string
+	Weighted      bool
+	ImportsMode   string
+	Since         string
+	Until         string
+	RevRange      string
+	FileFilter    string
+	RoleNames     []string
+	TicketPolicy  *TicketPolicy
+	Categories    []PathCategory
+	Overlap       *OverlapMatrix
+	AtRisk        []*BranchReport
+	Delivery      *DeliveryMetrics
+	Reviews       *ReviewLatency
+	Contention    *ContentionReport
+	Pairing       *PairingReport
+	Signatures    *SignatureReport
+	LocalActivity *LocalActivity
+	Leaderboard   *Leaderboard
+	Heatmap       *Heatmap
+	FileAge       *FileAgeChurn
+	Totals        *RepositoryTotals
+	Naming        *BranchNamingReport
+	Protected     *ProtectedPathReport
+	Themes        *CommitThemes
+	Departments   []*DepartmentRollup
+	Teams         []*TeamRollup
+	Summary       *ExecutiveSummary
+	BranchReports map[string]*BranchReport
+}
+
+// parseReportSections parses and validates a comma-separated list of report sections.
+func parseReportSections(value string) ([]string, error) {
+	var sections []string
+	for _, section := range strings.Split(value, ",") {
+		section = strings.ToLower(strings.TrimSpace(section))
+		if section == "" {
+			continue
+		}
+		if !slices.Contains(REPORT_SECTIONS, section) {
+			return nil, fmt.Errorf("unknown section '%s', expected any of: %s", section, strings.Join(REPORT_SECTIONS, ", "))
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+// parseReportColumns parses and validates a comma-separated list of report columns.
+func parseReportColumns(value string) ([]string, error) {
+	var columns []string
+	for _, column := range strings.Split(value, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column == "" {
+			continue
+		}
+		if !slices.Contains(REPORT_COLUMNS, column) {
+			return nil, fmt.Errorf("unknown column '%s', expected any of: %s", column, strings.Join(REPORT_COLUMNS, ", "))
+		}
+		columns = append(columns, column)
+	}
+
+	if len(columns) == 0 {
+		return nil, errors.New("at least one column must be selected")
+	}
+	return columns, nil
+}
+
+// PrepareRepository makes the repository given by a directory or URL available locally.
+//
+// Repositories given by URL are cloned into the repositories directory, their remote branches are analyzed
+// by their remote-tracking refs without checking them out (see listBranchRefs).
+//
+// Returns:
+//   - The local path to the repository.
+//   - An error if the repository could not be cloned or does not exist.
+func PrepareRepository(repoPath string) (string, error) {
+	return PrepareRepositoryWith(repoPath, CloneOptions{})
+}
+
+// PrepareRepositoryWith prepares the repository like PrepareRepository, a repository given by URL is cloned
+// with the given options.
+func PrepareRepositoryWith(repoPath string, options CloneOptions) (string, error) {
+	if IsRepositoryURL(repoPath) {
+		log.Println("URL found. Cloning repository: ", repoPath)
+		newRepoPath, err := cloneRepository(repoPath, REPOSITORIES_DIRECTORY, options)
+		if err != nil {
+			return "", fmt.Errorf("error cloning repository: %w", err)
+		}
+
+		// the remote branches of the clone are analyzed by their remote-tracking refs, see listBranchRefs
+		repoPath = newRepoPath
+	}
+
+	if _, err := os.Stat(repoPath); os.IsNotExist(err) {
+		return "", fmt.Errorf("repository path does not exist: %s", repoPath)
+	}
+
+	// an absolute path names the repository even if given as "." or with a trailing separator
+	absPath, err := filepath.Abs(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve repository path %s: %w", repoPath, err)
+	}
+
+	return absPath, nil
+}
+
+// IsRepositoryURL reports whether the repository is given by URL instead of a local directory, including the
+// scp-like syntax of SSH remotes (e.g., git@github.com:owner/repo.git).
+func IsRepositoryURL(repoPath string) bool {
+	if _, ok := scpLikeURL(repoPath); ok {
+		return true
+	}
+	u, err := url.Parse(repoPath)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https" || u.Scheme == "git" || u.Scheme == "ssh")
+}
+
+// scpLikeURL converts a remote given by the scp-like syntax [user@]host:path to an ssh:// URL. Like git, it takes
+// a remote for a local path if it has a scheme, a path separator before the first colon (e.g., ./dir:name or
+// /dir:name) or a single letter before it (a Windows drive, e.g., C:\repo).
+//
+// Returns:
+//   - The ssh:// URL of the remote.
+//   - false if the remote does not use the scp-like syntax.
+func scpLikeURL(remote string) (string, bool) {
+	if strings.Contains(remote, "://") {
+		return "", false
+	}
+	hostPart, repoPath, ok := strings.Cut(remote, ":")
+	if !ok || repoPath == "" || strings.ContainsAny(hostPart, `/\`) || len(hostPart) < 2 {
+		return "", false
+	}
+	return "ssh://" + hostPart + "/" + strings.TrimPrefix(repoPath, "/"), true
+}
+
+// IsGitInstalled checks if Git is installed and accessible in the system's PATH.
+//
+// It uses exec.LookPath to search for the "git" executable.
+//
+// Returns:
+//   - nil if Git is found.
+//   - An error if Git is not installed or not found in the PATH.
+func IsGitInstalled() error {
+	_, err := exec.LookPath("git")
+	if err != nil {
+		return errors.New("git is not installed or not found in PATH")
+	}
+	return nil
+}
+
+// CloneRepository clones a Git repository from the given URL to the specified destination directory.
+//
+// It first checks if the destination directory exists. If not, it creates it.
+// Then, it derives the repository name from the URL and constructs the local repository path.
+// If the local repository does not exist, it executes the "git clone" command.
+// If the local repository already exists, it skips the cloning process.
+//
+// Parameters:
+//   - repoURL: The URL of the Git repository to clone.
+//   - destDir: The destination directory where the repository should be cloned.
+//
+// Returns:
+//   - The local path to the cloned repository.
+//   - An error, if any, occurred during the cloning process.
+func CloneRepository(repoURL, destDir string) (string, error) {
+	return cloneRepository(repoURL, destDir, CloneOptions{})
+}
+
+// cloneRepository clones the repository like CloneRepository with the given options.
+func cloneRepository(repoURL string, destDir string, options CloneOptions) (string, error) {
+	if _, err := os.Stat(destDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(destDir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create directory %s: %w", destDir, err)
+		}
+	}
+
+	// sparse, shallow and single-branch clones lack parts of the repository, they are not reused for other analyses
+	namedURL := repoURL
+	if sshURL, ok := scpLikeURL(repoURL); ok {
+		// e.g., git@host:repo.git is named repo.git like ssh://git@host/repo.git
+		namedURL = sshURL
+	}
+	repoName := sanitizeDirectoryName(path.Base(strings.TrimRight(strings.ReplaceAll(namedURL, "\\", "/"), "/")))
+	if suffix := options.cloneSuffix(); suffix != "" {
+		repoName = strings.TrimSuffix(repoName, ".git") + suffix
+	}
+	localRepoPath := filepath.Join(destDir, repoName)
+
+	if _, err := os.Stat(localRepoPath); os.IsNotExist(err) {
+		referenceArgs, err := options.referenceArgs(repoURL)
+		if err != nil {
+			return "", err
+		}
+		authArgs, err := options.authArgs()
+		if err != nil {
+			return "", err
+		}
+		cloneArgs := append(append(options.cloneArgs(), referenceArgs...), authArgs...)
+		if options.Subdir != "" {
+			if err := cloneSparseRepository(repoURL, localRepoPath, options.Subdir, cloneArgs); err != nil {
+				return "", err
+			}
+		} else {
+			cmd := gitRemoteCommand("", repoURL, append(append([]string{"clone"}, cloneArgs...), repoURL, localRepoPath)...)
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				return "", remoteCommandError("clone repository", err, output)
+			}
+		}
+		log.Printf("Repository cloned to: %s", localRepoPath)
+		if options.Depth > 0 {
+			log.Printf("Shallow clone of the last %d commits of each branch, older history is not analyzed", options.Depth)
+		}
+	} else {
+		log.Printf("Repository already exists at: %s", localRepoPath)
+		if err := options.configureAuth(localRepoPath); err != nil {
+			return "", err
+		}
+		// the modification time of a clone tells when it has been used last, see EnforceCacheSize
+		now := time.Now()
+		if err := os.Chtimes(localRepoPath, now, now); err != nil {
+			log.Printf("Failed to update the modification time of %s: %v", localRepoPath, err)
+		}
+	}
+
+	return localRepoPath, nil
+}
+
+// analyzeGitHistoryByBranch aggregates the contributions of all branches (see listBranches), as many
+// branches as the run has workers are analyzed concurrently.
+func (run *analysisRun) analyzeGitHistoryByBranch(repoPath string, fileFilter string) (map[string]*BranchReport, error) {
+	branchNames, err := run.listBranches(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	branchReports := make(map[string]*BranchReport)
+
+	// a revision range is analyzed in place of the main branch, which need not exist
+	selectedBranches := []string{run.mainBranch}
+	if run.gitRevRange == nil {
+		run.checkBranchSelection(branchNames)
+		selectedBranches = nil
+		for _, branchName := range branchNames {
+			if run.branchSelected(branchName) {
+				selectedBranches = append(selectedBranches, branchName)
+			}
+		}
+	}
+
+	var cachePath string
+	var cache, updatedCache *AnalysisCache
+	var commitStore *CommitStore
+	var mainTip string
+	optionsKey := run.analysisOptionsKey(fileFilter)
+
+	if run.useCache {
+		cachePath, err = cacheFilePath(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		cache = loadAnalysisCache(cachePath)
+		updatedCache = newAnalysisCache()
+		mainTip, _ = resolveRevision(repoPath, run.branchRevision(run.mainBranch))
+
+		storePath, err := commitStoreFilePath(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		commitStore = openCommitStore(storePath, fileFilter, run.storeMemoryBudget)
+	} else if run.spillCommits {
+		// without the cache, commits beyond the memory budget spill to a temporary store
+		var storeDirectory string
+		commitStore, storeDirectory, err = openTemporaryCommitStore(fileFilter, run.storeMemoryBudget)
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(storeDirectory)
+	}
+
+	// results are collected under resultsMutex, the analysis of a branch only reads shared state
+	var resultsMutex sync.Mutex
+	var strictErrors []error
+	lastCheckpoint := time.Now()
+	branches := make(chan string)
+	var workers sync.WaitGroup
+	for worker := 0; worker < max(run.workers, 1); worker++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for branchName := range branches {
+				var cached *BranchCacheEntry
+				var cacheEntry *BranchCacheEntry
+				var otherBranches []string
+				if run.uniqueCommits && branchName != run.mainBranch {
+					otherBranches = slices.DeleteFunc(slices.Clone(selectedBranches), func(other string) bool {
+						return other == branchName
+					})
+				}
+				// the unique commits of a branch change with the other branches, they are not reused from the cache
+				if run.useCache && otherBranches == nil {
+					tip, err := resolveRevision(repoPath, run.branchRevision(branchName))
+					if err == nil {
+						cacheEntry = &BranchCacheEntry{Tip: tip, MainTip: mainTip, OptionsKey: optionsKey}
+						if entry, ok := cache.Branches[branchName]; ok && entry.Tip == tip && entry.MainTip == mainTip {
+							cached = entry
+						}
+					}
+				}
+
+				report, err := run.analyzeBranch(repoPath, branchName, fileFilter, optionsKey, cached, cacheEntry, commitStore, otherBranches)
+
+				resultsMutex.Lock()
+				if err != nil {
+					strictErrors = append(strictErrors, err)
+					resultsMutex.Unlock()
+					continue
+				}
+				if cacheEntry != nil {
+					updatedCache.Branches[branchName] = cacheEntry
+				}
+				branchReports[branchName] = report
+				if run.resume && time.Since(lastCheckpoint) >= resumeCheckpointInterval {
+					checkpointAnalysisCache(cachePath, cache, updatedCache, commitStore)
+					lastCheckpoint = time.Now()
+				}
+				resultsMutex.Unlock()
+			}
+		}()
+	}
+
+	for _, branchName := range selectedBranches {
+		branches <- branchName
+	}
+	close(branches)
+	workers.Wait()
+	if len(strictErrors) > 0 {
+		return nil, errors.Join(strictErrors...)
+	}
+
+	if run.useCache {
+		if err := saveAnalysisCache(cachePath, updatedCache); err != nil {
+			log.Printf("Failed to save analysis cache: %v", err)
+		}
+		if err := commitStore.save(); err != nil {
+			log.Printf("Failed to save commit store: %v", err)
+		}
+	}
+
+	// Remove empty branch reports, explaining why they are empty
+	for _, branchName := range slices.Sorted(maps.Keys(branchReports)) {
+		if report := branchReports[branchName]; len(report.Contributions) == 0 {
+			log.Printf("Branch '%s' has no contributions and is left out of the report: %s", branchName, run.diagnoseEmptyBranch(repoPath, report, fileFilter))
+			delete(branchReports, branchName)
+		}
+	}
+	if len(branchReports) == 0 {
+		log.Printf("Warning: no branch has contributions, the report is empty")
+	}
+
+	return branchReports, nil
+}
+
+// analyzeBranch aggregates the contributions of a branch since its merge-base with the main branch.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - branchName: The name of the branch.
+//   - fileFilter: The pathspec limiting the commits and files, ignored if empty.
+//   - optionsKey: The key of the analysis options, see analysisOptionsKey.
+//   - cached: The cache entry of the unchanged branch from a previous run, nil if there is none.
+//   - cacheEntry: The cache entry updated with the results, nil if the cache is not used.
+//   - commitStore: The parsed commits of previous runs, nil if the cache is not used.
+//   - otherBranches: The branches whose commits are left out (see Options.UniqueCommits), nil to analyze all
+//     commits of the branch.
+//
+// Returns:
+//   - The branch report.
+//   - An error if the merge-base or git log of the branch failed in strict mode (see Options.Strict).
+func (run *analysisRun) analyzeBranch(repoPath string, branchName string, fileFilter string, optionsKey string, cached *BranchCacheEntry, cacheEntry *BranchCacheEntry, commitStore *CommitStore, otherBranches []string) (*BranchReport, error) {
+	// results of a failed merge-base are not trusted in strict mode
+	mergeBaseFailed := cached != nil && cached.MergeBase == "" && branchName != run.mainBranch
+	if cached != nil && cached.OptionsKey == optionsKey && cached.Report != nil && !(run.strict && mergeBaseFailed) {
+		log.Printf("Branch '%s' is unchanged since the last run, reusing cached results", branchName)
+		cacheEntry.MergeBase = cached.MergeBase
+		cacheEntry.Report = cached.Report
+		return cached.Report, nil
+	}
+
+	revision := run.branchRevision(branchName)
+	logRange := revision
+
+	// Get merge base to get stats from the branch only
+	if branchName == run.mainBranch && run.gitRevRange != nil {
+		logRange = strings.Join(run.gitRevRange, " ")
+	} else if branchName != run.mainBranch {
+		if cached != nil && cached.MergeBase != "" {
+			logRange = fmt.Sprintf("%s..%s", cached.MergeBase, revision)
+			cacheEntry.MergeBase = cached.MergeBase
+		} else {
+			cmdMergeBase := gitCommand(repoPath, "merge-base", run.branchRevision(run.mainBranch), revision)
+			outputMergeBase, err := cmdMergeBase.CombinedOutput()
+			if err != nil {
+				if run.strict {
+					return nil, fmt.Errorf("git merge-base for branch '%s' failed: %w, output: %s", branchName, err, strings.TrimSpace(string(outputMergeBase)))
+				}
+				log.Printf("command 'git merge-base' for branch '%s' failed: %v; message: %s", branchName, err, outputMergeBase)
+				log.Printf("using default 'git log' range: %s", logRange)
+			} else {
+				mergeBase := strings.TrimSpace(string(outputMergeBase))
+				logRange = fmt.Sprintf("%s..%s", mergeBase, revision)
+				if cacheEntry != nil {
+					cacheEntry.MergeBase = mergeBase
+				}
+			}
+		}
+	}
+
+	logRange = run.uniqueLogRange(logRange, otherBranches)
+
+	if fileFilter != "" {
+		log.Printf("Applying for branch '%s' filter: %s", branchName, fileFilter)
+	}
+	report := newBranchReport(branchName)
+	handleBatch := func(commits []CommitRecord) {
+		run.aggregateCommits(report, commits, fileFilter)
+	}
+	var err error
+	if commitStore != nil {
+		err = commitStore.streamCommits(run, repoPath, logRange, fileFilter, run.batchSize, handleBatch, &report.Parsing)
+	} else {
+		err = run.streamGitLog(gitCommand(repoPath, run.gitLogArgs(logRange, fileFilter)...), run.batchSize, handleBatch, &report.Parsing)
+	}
+	if err != nil {
+		if run.strict {
+			return nil, fmt.Errorf("git log for branch %s failed: %w", branchName, err)
+		}
+		// commits parsed before the failure are reported, but not cached
+		log.Printf("git log for branch %s failed: %v", branchName, err)
+		report.Parsing.LogError = err.Error()
+		return report, nil
+	}
+	if parsing := report.Parsing; parsing.incomplete() {
+		log.Printf("Statistics of branch '%s' are incomplete: %d commits and %d lines of git log output could not be parsed and were skipped", branchName, parsing.SkippedCommits, parsing.SkippedLines)
+	}
+
+	if cacheEntry != nil {
+		cacheEntry.Report = report
+	}
+	return report, nil
+}
+
+func newBranchReport(branchName string) *BranchReport {
+	return &BranchReport{
+		BranchName:    branchName,
+		Contributions: make(map[string]*UserContribution),
+		Tickets:       make(map[string]*TicketContribution),
+		Compliance:    make(map[string]*PeriodCompliance),
+		Categories:    make(map[string]*CategoryReport),
+		Components:    make(map[string]*ComponentReport),
+		PeriodChurn:   make(map[string]int),
+		Paths:         make(map[string]*PathContribution),
+		renamedPaths:  make(renamedPaths),
+	}
+}
+
+// aggregateCommits adds a batch of parsed commits to the contributions of the given branch report.
+func (run *analysisRun) aggregateCommits(report *BranchReport, commits []CommitRecord, fileFilter string) {
+	categories := run.config.pathCategories()
+
+	for _, commit := range commits {
+		report.Parsing.SkippedLines += commit.SkippedLines
+	}
+	commits = run.reconcileSquashMerges(commits)
+	report.Funnel.Read += len(commits)
+	for _, commit := range commits {
+		if run.config.excludesCommit(commit.Hash) {
+			report.Funnel.Excluded++
+			continue
+		}
+		commit = run.config.weightCommit(commit)
+		commit.Email = run.config.canonicalEmail(commit.Email)
+		if !run.authorSelected(commit.Email) {
+			report.Funnel.OtherAuthors++
+			continue
+		}
+		if run.isInitialImport(commit) {
+			addImportCommit(report, commit)
+			if run.importsMode == IMPORTS_EXCLUDE {
+				report.Funnel.Imports++
+				continue
+			}
+			if run.importsMode == IMPORTS_SEPARATE {
+				commit.Email = IMPORTS_AUTHOR
+			}
+		}
+		if _, ok := report.Contributions[commit.Email]; !ok {
+			report.Contributions[commit.Email] = &UserContribution{
+				Email:                commit.Email,
+				ContributionTimeline: make(map[string]int),
+				FileFilter:           fileFilter,
+				Roles:                make(map[string]int),
+				FocusAreas:           make(map[string]map[string]bool),
+				TouchedFiles:         make(map[string]map[string]bool),
+			}
+		}
+		contribution := report.Contributions[commit.Email]
+		contribution.CommitCount++
+
+		period, hasPeriod := run.timelinePeriod(commit.Date)
+		if hasPeriod {
+			contribution.ContributionTimeline[period]++
+		}
+
+		if policy := run.config.TicketPolicy; policy != nil {
+			compliance, ok := report.Compliance[period]
+			if !ok {
+				compliance = &PeriodCompliance{Period: period}
+				report.Compliance[period] = compliance
+			}
+			compliance.CommitCount++
+			if !policy.referencesTicket(commit.Subject) {
+				compliance.WithoutTicket++
+				contribution.CommitsWithoutTicket++
+			}
+		}
+
+		linesEdited := 0
+		for _, change := range commit.Files {
+			if change.Binary {
+				continue
+			}
+			contribution.LinesAdded += change.Added
+			contribution.LinesRemoved += change.Removed
+			contribution.LinesEdited += change.Added + change.Removed
+			linesEdited += change.Added + change.Removed
+
+			if role := run.config.roleForPath(change.Path); role != "" {
+				contribution.Roles[role] += change.Added + change.Removed
+			}
+		}
+
+		if hasPeriod {
+			report.PeriodChurn[period] += linesEdited
+			run.addFocusAreas(contribution, commit, period)
+			addSubjectTerms(contribution, commit, period)
+		}
+		addTouchedFiles(contribution, commit, period)
+
+		addCategoryCommit(report, categories, commit, period)
+		addComponentCommit(report, run.config.Components, commit, period)
+		if run.pathBreakdown != "" {
+			run.addPathContributions(report, commit)
+		}
+
+		for _, key := range extractTicketKeys(commit.Subject) {
+			ticket, ok := report.Tickets[key]
+			if !ok {
+				ticket = &TicketContribution{Key: key, Contributors: make(map[string]int)}
+				report.Tickets[key] = ticket
+			}
+			ticket.CommitCount++
+			ticket.LinesEdited += linesEdited
+			ticket.Contributors[commit.Email]++
+		}
+	}
+}
+
+func (run *analysisRun) generateHTMLReportByBranch(branchReports map[string]*BranchReport, repoName string, fileFilter string) (string, error) {
+	return run.generateHTMLReport(run.newReportData(branchReports, repoName, fileFilter))
+}
+
+// generateHTMLReport renders the report data as HTML page.
+func (run *analysisRun) generateHTMLReport(data ReportData) (string, error) {
+	tmpl := `
+<!DOCTYPE html>
+<html lang="{{.Language}}" data-bs-theme="{{.Theme}}">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>{{t "Git Contribution Report: %s" .RepoName}}</title>
+{{bootstrapStyles}}
+{{bootstrapScripts}}
+<style>
+	.fixed-width {
+		width: 150px;
+	}
+	.timeline-chart {
+		max-width: 100%;
+		height: auto;
+	}
+	:focus-visible {
+		outline: 3px solid #fd7e14;
+		outline-offset: 2px;
+	}
+	@media print {
+		[data-bs-theme=dark] {
+			--bs-body-bg: #fff;
+			--bs-body-color: #000;
+			--bs-emphasis-color: #000;
+			--bs-border-color: #dee2e6;
+		}
+		body {
+			background: #fff !important;
+			color: #000 !important;
+			font-size: 10pt;
+		}
+		.container {
+			max-width: 100% !important;
+		}
+		.table-dark {
+			--bs-table-color: #000;
+			--bs-table-bg: #fff;
+			--bs-table-border-color: #dee2e6;
+			--bs-table-striped-bg: #f2f2f2;
+			--bs-table-striped-color: #000;
+		}
+		.badge {
+			color: #000 !important;
+			background: none !important;
+			border: 1px solid #000;
+		}
+		.no-print {
+			display: none !important;
+		}
+		thead {
+			display: table-header-group;
+		}
+		tr, h2, h3 {
+			break-inside: avoid;
+			break-after: avoid;
+		}
+		section {
+			break-before: auto;
+		}
+	}
+</style>
+</head>
+<body>
+
+<main class="container mt-4">
+
+<header>
+<h1 class="h4"> {{t "Repository name:"}} <span class="badge text-bg-success">{{.RepoName}}</span></h1>
+{{if not .HidePaths}}<p class="h4"> {{t "Applied file filter:"}} <span class="badge text-bg-info">{{.FileFilter}}</span></p>{{end}}
+{{if or .Since .Until}}<p class="h4"> {{t "Period:"}} <span class="badge text-bg-info">{{if .Since}}{{t "since"}} {{.Since}}{{end}} {{if .Until}}{{t "until"}} {{.Until}}{{end}}</span></p>{{end}}
+{{if .RevRange}}<p class="h4"> {{t "Revision range:"}} <span class="badge text-bg-info">{{.RevRange}}</span></p>{{end}}
+{{if and .Weighted .ShowLines}}<p>{{t "Line counts are weighted by path as configured."}}</p>{{end}}
+
+<div class="d-flex justify-content-end align-items-center gap-2 mb-3 no-print">
+	<span id="reportSearchStatus" class="text-body-secondary" aria-live="polite"></span>
+	<input id="reportSearch" type="search" class="form-control w-auto" placeholder="{{t "Search contributors, files, branches"}}" aria-label="{{t "Search contributors, files, branches"}}">
+	<button id="themeToggle" type="button" class="btn {{if eq .Theme "dark"}}btn-outline-light{{else}}btn-outline-dark{{end}}" aria-pressed="{{if eq .Theme "dark"}}true{{else}}false{{end}}" aria-label="{{t "Toggle dark theme"}}">{{if eq .Theme "dark"}}{{t "Light Theme"}}{{else}}{{t "Dark Theme"}}{{end}}</button>
+</div>
+</header>
+
+{{if index .Sections "summary"}}{{with .Summary}}
+<section aria-labelledby="executive-summary">
+<h2 class="h4" id="executive-summary">{{t "Executive summary"}}</h2>
+<div class="row row-cols-2 row-cols-md-4 g-3 mb-3">
+	<div class="col"><div class="border rounded p-2"><div class="fs-4">{{.BranchCount}}</div>{{t "Branches"}}</div></div>
+	<div class="col"><div class="border rounded p-2"><div class="fs-4">{{.ContributorCount}}</div>{{t "Contributors"}}</div></div>
+	<div class="col"><div class="border rounded p-2"><div class="fs-4">{{.CommitCount}}</div>{{t "Commits"}}</div></div>
+	{{if $.ShowLines}}<div class="col"><div class="border rounded p-2"><div class="fs-4">{{.LinesEdited}}</div>{{t "Lines Edited"}}</div></div>{{end}}
+</div>
+<ul>
+	{{if .BranchesAtRisk}}<li>{{t "%d long-lived branches at risk" .BranchesAtRisk}}</li>{{end}}
+	{{if .MissingBackports}}<li>{{t "%d mainline fixes missing on release branches" .MissingBackports}}</li>{{end}}
+	{{if and .TicketPolicyActive .WithoutTicket}}<li>{{t "%d commits without ticket reference" .WithoutTicket}}</li>{{end}}
+	{{if .UnverifiedTags}}<li>{{t "%d releases without valid signature" .UnverifiedTags}}</li>{{end}}
+	{{if .NamingViolations}}<li>{{t "%d branches violating the naming convention" .NamingViolations}}</li>{{end}}
+	{{if .ReviewsAvailable}}<li>{{t "Median review-to-merge latency: %.1f hours" .ReviewMedianHours}}</li>{{end}}
+	{{if not (or .BranchesAtRisk .MissingBackports (and .TicketPolicyActive .WithoutTicket) .UnverifiedTags .NamingViolations)}}<li>{{t "No risks flagged"}}</li>{{end}}
+</ul>
+{{if .TopMovers}}
+<table class="table {{$.TableTheme}} table-striped">
+	<caption>{{t "Top movers: commits in %s compared to %s" .CurrentPeriod .PreviousPeriod}}</caption>
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Email"}}</th>
+			<th scope="col" class="fixed-width">{{.PreviousPeriod}}</th>
+			<th scope="col" class="fixed-width">{{.CurrentPeriod}}</th>
+			<th scope="col">{{t "Difference"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .TopMovers}}
+		<tr>
+			<td>{{email .Email}}</td>
+			<td>{{.Previous}}</td>
+			<td>{{.Current}}</td>
+			<td>{{if gt .Delta 0}}+{{end}}{{.Delta}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+</section>
+{{end}}{{end}}
+
+{{if index .Sections "totals"}}{{with .Totals}}
+<section aria-labelledby="repository-totals">
+<h2 class="h4" id="repository-totals">{{t "Repository totals"}}</h2>
+<p>{{t "Contributions to the %d analyzed branches with each commit counted once: %d commits, while the branch tables count %d commits." .Branches .CommitCount .BranchCommits}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			{{if index $.Columns "email"}}<th scope="col" class="fixed-width">{{t "Email"}}</th>{{end}}
+			{{if index $.Columns "commits"}}<th scope="col" class="fixed-width">{{t "Commit Count"}}</th>{{end}}
+			{{if index $.Columns "added"}}<th scope="col" class="fixed-width">{{t "Lines Added"}}</th>{{end}}
+			{{if index $.Columns "removed"}}<th scope="col" class="fixed-width">{{t "Lines Removed"}}</th>{{end}}
+			{{if index $.Columns "edited"}}<th scope="col" class="fixed-width">{{t "Lines Edited"}}</th>{{end}}
+		</tr>
+	</thead>
+	<tbody>
+		{{range topContributions .Contributions}}
+		<tr>
+			{{if index $.Columns "email"}}<td>{{if .Others}}{{t "%d others" .Others}}{{else}}{{email .Email}}{{end}}</td>{{end}}
+			{{if index $.Columns "commits"}}<td>{{.CommitCount}}</td>{{end}}
+			{{if index $.Columns "added"}}<td>{{.LinesAdded}}</td>{{end}}
+			{{if index $.Columns "removed"}}<td>{{.LinesRemoved}}</td>{{end}}
+			{{if index $.Columns "edited"}}<td>{{.LinesEdited}}</td>{{end}}
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</section>
+{{end}}{{end}}
+
+{{if and .AtRisk (index .Sections "branch-health")}}
+<section aria-labelledby="branches-at-risk">
+<h2 class="h4" id="branches-at-risk">{{t "Long-lived branches at risk"}}</h2>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Branch"}}</th>
+			<th scope="col" class="fixed-width">{{t "Commits Ahead"}}</th>
+			<th scope="col" class="fixed-width">{{t "Merge-Base Date"}}</th>
+			<th scope="col">{{t "Days Since Merge-Base"}}</th>
+			<th scope="col">{{t "Unmerged Work By"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .AtRisk}}
+		<tr>
+			<td>{{.BranchName}}</td>
+			<td>{{.Divergence.CommitsAhead}}</td>
+			<td>{{.Divergence.MergeBaseDate}}</td>
+			<td>{{.Divergence.DaysSinceMergeBase}}</td>
+			<td>{{range .Divergence.UnmergedAuthors}}{{email .Email}}: {{.CommitCount}}<br>{{end}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</section>
+{{end}}
+
+{{if index .Sections "naming"}}{{with .Naming}}
+<section aria-labelledby="branch-naming">
+<h2 class="h4" id="branch-naming">{{t "Branch naming"}}</h2>
+<p>{{t "%d of %d checked branches match none of the naming patterns:" (len .Violations) .Checked}} {{range $i, $pattern := .Patterns}}{{if $i}}, {{end}}<code>{{$pattern}}</code>{{end}}</p>
+{{if .Violations}}
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Branch"}}</th>
+			<th scope="col" class="fixed-width">{{t "Last commit"}}</th>
+			<th scope="col">{{t "Authors"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Violations}}
+		<tr>
+			<td>{{.Branch}}</td>
+			<td>{{.LastCommit}}</td>
+			<td>{{range .Authors}}{{email .Email}}{{if .CommitCount}} ({{.CommitCount}}){{end}}<br>{{end}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+</section>
+{{end}}{{end}}
+
+{{if index .Sections "protected-paths"}}{{with .Protected}}
+<section aria-labelledby="protected-paths">
+<h2 class="h4" id="protected-paths">{{t "Protected path changes"}}</h2>
+<p>{{t "%d commits by %d authors changed protected paths." (len .Changes) .Authors}}{{if not $.HidePaths}} {{range $i, $pattern := .Patterns}}{{if $i}}, {{end}}<code>{{$pattern}}</code>{{end}}{{end}}</p>
+{{if .Changes}}
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Date"}}</th>
+			<th scope="col" class="fixed-width">{{t "Commit"}}</th>
+			<th scope="col" class="fixed-width">{{t "Email"}}</th>
+			<th scope="col">{{t "Subject"}}</th>
+			{{if not $.HidePaths}}<th scope="col">{{t "Paths"}}</th>{{end}}
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Changes}}
+		<tr>
+			<td>{{.Date}}</td>
+			<td>{{if .URL}}<a href="{{.URL}}"><code>{{printf "%.10s" .Hash}}</code></a>{{else}}<code>{{printf "%.10s" .Hash}}</code>{{end}}</td>
+			<td>{{email .Email}}</td>
+			<td>{{.Subject}}</td>
+			{{if not $.HidePaths}}<td>{{range .Paths}}{{.}}<br>{{end}}</td>{{end}}
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+</section>
+{{end}}{{end}}
+
+{{if index .Sections "delivery"}}{{with .Delivery}}
+<section aria-labelledby="delivery-metrics">
+<h2 class="h4" id="delivery-metrics">{{t "Delivery metrics"}}</h2>
+<p>{{if eq .DeployMarker "tags"}}{{t "Deployments are counted by tags on the main branch, the lead time is the median time from the first commit of a merged branch to its merge."}}{{else}}{{t "Deployments are counted by merges on the main branch, the lead time is the median time from the first commit of a merged branch to its merge."}}{{end}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Period"}}</th>
+			<th scope="col" class="fixed-width">{{t "Deployments"}}</th>
+			<th scope="col" class="fixed-width">{{t "Merged Changes"}}</th>
+			<th scope="col">{{t "Median Lead Time (days)"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Periods}}
+		<tr>
+			<td>{{.Period}}</td>
+			<td>{{.Deployments}}</td>
+			<td>{{.MergedChanges}}</td>
+			<td>{{if .MergedChanges}}{{printf "%.1f" .MedianLeadTime}}{{end}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{if .Taggers}}
+<h3 class="h5">{{t "Releases by tagger"}}</h3>
+<p>{{t "Annotated tags on any branch by their creator."}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Email"}}</th>
+			<th scope="col" class="fixed-width">{{t "Tags"}}</th>
+			{{if index $.Sections "timelines"}}<th scope="col" class="fixed-width">{{t "Timeline"}}</th>{{end}}
+			<th scope="col">{{t "Latest tag"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Taggers}}
+		<tr>
+			<td>{{email .Email}}</td>
+			<td>{{.TagCount}}</td>
+			{{if index $.Sections "timelines"}}<td>{{$timeline := .Timeline}}{{range sortedPeriods $timeline}}{{.}}: {{index $timeline .}}<br>{{end}}</td>{{end}}
+			<td>{{.LastTag}} ({{.LastTagDate}})</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+</section>
+{{end}}{{end}}
+
+{{if index .Sections "signatures"}}{{with .Signatures}}
+<section aria-labelledby="tag-signatures">
+<h2 class="h4" id="tag-signatures">{{t "Release signatures"}}</h2>
+<p>{{t "%d of %d annotated tags are signed, %d signatures are valid." .SignedCount (len .Tags) .ValidCount}}</p>
+{{if .Identities}}
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col">{{t "Signing identity"}}</th>
+			<th scope="col" class="fixed-width">{{t "Tags"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Identities}}
+		<tr>
+			<td>{{.Identity}}</td>
+			<td>{{.TagCount}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Tag"}}</th>
+			<th scope="col" class="fixed-width">{{t "Date"}}</th>
+			<th scope="col" class="fixed-width">{{t "Tagger"}}</th>
+			<th scope="col" class="fixed-width">{{t "Signature"}}</th>
+			<th scope="col">{{t "Signing identity"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Tags}}
+		<tr>
+			<td>{{.Name}}</td>
+			<td>{{.Date}}</td>
+			<td>{{email .Tagger}}</td>
+			<td>{{if eq .Status "valid"}}<span class="badge text-bg-success">{{t .Status}}</span>{{else if eq .Status "unsigned"}}<span class="badge text-bg-secondary">{{t .Status}}</span>{{else}}<span class="badge text-bg-danger">{{t .Status}}</span>{{end}}</td>
+			<td>{{.Identity}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</section>
+{{end}}{{end}}
+
+{{if index .Sections "reviews"}}{{with .Reviews}}
+<section aria-labelledby="review-latency">
+<h2 class="h4" id="review-latency">{{t "Review-to-merge latency"}}</h2>
+<p>{{if eq .Provider "GitLab"}}{{t "Median time from opening to merging of %d merge requests on %s: %.1f hours" .MergedCount .Provider .MedianHours}}{{else}}{{t "Median time from opening to merging of %d pull requests on %s: %.1f hours" .MergedCount .Provider .MedianHours}}{{end}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Author"}}</th>
+			<th scope="col" class="fixed-width">{{t "Merged"}}</th>
+			<th scope="col">{{t "Median Hours to Merge"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Contributors}}
+		<tr>
+			<td>{{email .Author}}</td>
+			<td>{{.MergedCount}}</td>
+			<td>{{printf "%.1f" .MedianHours}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Period"}}</th>
+			<th scope="col" class="fixed-width">{{t "Merged"}}</th>
+			<th scope="col">{{t "Median Hours to Merge"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Periods}}
+		<tr>
+			<td>{{.Period}}</td>
+			<td>{{.MergedCount}}</td>
+			<td>{{printf "%.1f" .MedianHours}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</section>
+{{end}}{{end}}
+
+{{if and .Departments (index .Sections "departments")}}
+<section aria-labelledby="departments">
+<h2 class="h4" id="departments">{{t "Contributions by department"}}</h2>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Department"}}</th>
+			<th scope="col" class="fixed-width">{{t "Manager"}}</th>
+			<th scope="col" class="fixed-width">{{t "Commit Count"}}</th>
+			{{if $.ShowLines}}<th scope="col" class="fixed-width">{{t "Lines Edited"}}</th>{{end}}
+			<th scope="col">{{t "Contributors"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Departments}}
+		<tr>
+			<td>{{if eq .Department "Unknown"}}{{t "Unknown"}}{{else}}{{.Department}}{{end}}</td>
+			<td>{{join .Managers ", "}}</td>
+			<td>{{.CommitCount}}</td>
+			{{if $.ShowLines}}<td>{{.LinesEdited}}</td>{{end}}
+			<td>{{range .Contributors}}{{email .}}<br>{{end}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</section>
+{{end}}
+
+{{if and .Teams (index .Sections "teams")}}
+<section aria-labelledby="teams">
+<h2 class="h4" id="teams">{{t "Contributions by team"}}</h2>
+{{$periods := teamPeriods .Teams}}
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Team"}}</th>
+			<th scope="col" class="fixed-width">{{t "Commit Count"}}</th>
+			{{if index $.Columns "timeline"}}<th scope="col" class="fixed-width">{{t "Contribution Timeline"}}</th>{{end}}
+			{{if $.ShowLines}}<th scope="col" class="fixed-width">{{t "Lines Added"}}</th>
+			<th scope="col" class="fixed-width">{{t "Lines Removed"}}</th>
+			<th scope="col" class="fixed-width">{{t "Lines Edited"}}</th>{{end}}
+			<th scope="col">{{t "Contributors"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Teams}}
+		<tr>
+			<td>{{if eq .Team "Unassigned"}}{{t "Unassigned"}}{{else}}{{.Team}}{{end}}</td>
+			<td>{{.CommitCount}}</td>
+			{{if index $.Columns "timeline"}}<td class="text-info">{{timelineChart .Timeline $periods}}</td>{{end}}
+			{{if $.ShowLines}}<td>{{.LinesAdded}}</td>
+			<td>{{.LinesRemoved}}</td>
+			<td>{{.LinesEdited}}</td>{{end}}
+			<td>{{range .Contributors}}{{email .}}<br>{{end}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</section>
+{{end}}
+
+{{if and (gt (len .Overlap.Branches) 1) (index .Sections "overlap")}}
+<section aria-labelledby="contributors-across-branches">
+<h2 class="h4" id="contributors-across-branches">{{t "Contributors across branches"}}</h2>
+<div class="table-responsive">
+<table class="table {{$.TableTheme}} table-striped table-sm">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Email"}}</th>
+			<th scope="col">{{t "Branches"}}</th>
+			{{range .Overlap.Branches}}<th scope="col">{{.}}</th>{{end}}
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Overlap.Rows}}
+		<tr>
+			<td>{{email .Email}}</td>
+			<td>
+				{{.BranchCount}}
+				{{if .Spread}}<span class="badge text-bg-warning">{{t "spread"}}</span>{{else if eq .BranchCount 1}}<span class="badge text-bg-secondary">{{t "specialist"}}</span>{{end}}
+			</td>
+			{{range .Commits}}<td>{{if .}}{{.}}{{end}}</td>{{end}}
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</div>
+</section>
+{{end}}
+
+{{if and (index .Sections "contention") (not .HidePaths)}}{{with .Contention}}{{if .Files}}
+<section aria-labelledby="file-contention">
+<h2 class="h4" id="file-contention">{{t "Contention hot zones"}}</h2>
+<p>{{t "Files edited by at least %d different authors within %d days on any branch, likely sources of merge conflicts." .MinAuthors .WindowDays}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col">{{t "File"}}</th>
+			<th scope="col" class="fixed-width">{{t "Authors"}}</th>
+			<th scope="col" class="fixed-width">{{t "Edits"}}</th>
+			<th scope="col" class="fixed-width">{{t "Window"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Files}}
+		<tr>
+			<td>{{.Path}}</td>
+			<td>{{range .Authors}}{{email .}}<br>{{end}}</td>
+			<td>{{.EditCount}}</td>
+			<td>{{.WindowStart}} &ndash; {{.WindowEnd}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</section>
+{{end}}{{end}}{{end}}
+
+{{if index .Sections "pairing"}}{{with .Pairing}}{{if .Pairings}}
+<section aria-labelledby="pairing">
+<h2 class="h4" id="pairing">{{t "Pairing and hand-offs"}}</h2>
+<p>{{t "Authors committing to the same files within %s of each other. Sequences in both directions indicate pairing or batch work, sequences in one direction indicate hand-offs." .Window}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Authors"}}</th>
+			<th scope="col" class="fixed-width">{{t "Sequences"}}</th>
+			<th scope="col" class="fixed-width">{{t "Pattern"}}</th>
+			<th scope="col" class="fixed-width">{{t "Files"}}</th>
+			<th scope="col" class="fixed-width">{{t "Co-authored commits"}}</th>
+			<th scope="col">{{t "Last sequence"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Pairings}}
+		<tr>
+			<td>{{email .First}} &rarr;<br>{{email .Second}}</td>
+			<td>{{.Sequences}}{{if ne .Forward .Sequences}} ({{t "%d forward" .Forward}}){{end}}</td>
+			<td>{{if eq .Pattern "pairing"}}<span class="badge text-bg-info">{{t "pairing"}}</span>{{else if eq .Pattern "hand-off"}}<span class="badge text-bg-secondary">{{t "hand-off"}}</span>{{else}}<span class="badge text-bg-success">{{t "co-authored"}}</span>{{end}}</td>
+			<td>{{.FileCount}}</td>
+			<td>{{.CoAuthored}}</td>
+			<td>{{.LastCommits}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</section>
+{{end}}{{end}}{{end}}
+
+{{if index .Sections "local-activity"}}{{with .LocalActivity}}
+<section aria-labelledby="local-activity">
+<h2 class="h4" id="local-activity">{{t "Local activity"}}</h2>
+<p>{{t "Work recorded in the reflog of this clone: %d commits, %d amends, %d rebases, %d resets." .Total.Commits .Total.Amends .Total.Rebases .Total.Resets}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Period"}}</th>
+			<th scope="col" class="fixed-width">{{t "Commits"}}</th>
+			<th scope="col" class="fixed-width">{{t "Amends"}}</th>
+			<th scope="col" class="fixed-width">{{t "Rebases"}}</th>
+			<th scope="col" class="fixed-width">{{t "Resets"}}</th>
+			<th scope="col" class="fixed-width">{{t "Cherry-picks"}}</th>
+			<th scope="col">{{t "Checkouts"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Periods}}
+		<tr>
+			<td>{{.Period}}</td>
+			<td>{{.Commits}}</td>
+			<td>{{.Amends}}</td>
+			<td>{{.Rebases}}</td>
+			<td>{{.Resets}}</td>
+			<td>{{.CherryPicks}}</td>
+			<td>{{.Checkouts}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{if .Unpublished}}
+<h3 class="h5">{{t "Unpublished branches"}}</h3>
+<p>{{t "Local branches with commits not contained in any remote-tracking branch."}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Branch"}}</th>
+			<th scope="col">{{t "Unpublished commits"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Unpublished}}
+		<tr>
+			<td>{{.Name}}</td>
+			<td>{{.Commits}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+{{if .Abandoned}}
+<h3 class="h5">{{t "Abandoned branches"}}</h3>
+<p>{{t "Deleted branches whose last commits are not reachable from any branch or tag."}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Branch"}}</th>
+			<th scope="col" class="fixed-width">{{t "Last commit"}}</th>
+			<th scope="col" class="fixed-width">{{t "Unreachable commits"}}</th>
+			<th scope="col">{{t "Left on"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Abandoned}}
+		<tr>
+			<td>{{.Name}}</td>
+			<td><code>{{printf "%.10s" .Tip}}</code></td>
+			<td>{{.Commits}}</td>
+			<td>{{.LeftDate}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+</section>
+{{end}}{{end}}
+
+{{if index .Sections "leaderboard"}}{{with .Leaderboard}}
+<section aria-labelledby="leaderboard">
+<h2 class="h4" id="leaderboard">{{t "Leaderboard"}}</h2>
+{{if .Newcomers}}
+<h3 class="h5">{{t "Welcome to our new contributors"}}</h3>
+<p>{{range .Newcomers}}<span class="badge text-bg-success me-1" title="{{t "First contribution on %s" .Date}}">{{email .Email}}</span>{{end}}</p>
+{{end}}
+<h3 class="h5">{{t "Top contributors by month"}}</h3>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Month"}}</th>
+			<th scope="col" class="fixed-width">{{t "Rank"}}</th>
+			{{if index $.Columns "email"}}<th scope="col" class="fixed-width">{{t "Email"}}</th>{{end}}
+			<th scope="col">{{t "Commits"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range $month := .Months}}{{range .Leaders}}
+		<tr>
+			<td>{{$month.Month}}</td>
+			<td>{{if eq .Rank 1}}&#129351;{{else if eq .Rank 2}}&#129352;{{else}}&#129353;{{end}} {{.Rank}}</td>
+			{{if index $.Columns "email"}}<td>{{email .Email}}</td>{{end}}
+			<td>{{.Commits}}</td>
+		</tr>
+		{{end}}{{end}}
+	</tbody>
+</table>
+{{if .Streaks}}
+<h3 class="h5">{{t "Streak badges"}}</h3>
+<p>{{t "Contributors with commits on consecutive days."}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			{{if index $.Columns "email"}}<th scope="col" class="fixed-width">{{t "Email"}}</th>{{end}}
+			<th scope="col" class="fixed-width">{{t "Badge"}}</th>
+			<th scope="col" class="fixed-width">{{t "Longest streak"}}</th>
+			<th scope="col">{{t "Reached on"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Streaks}}
+		<tr>
+			{{if index $.Columns "email"}}<td>{{email .Email}}</td>{{end}}
+			<td><span class="badge text-bg-warning">&#128293; {{t "%d-day streak" .Badge}}</span></td>
+			<td>{{t "%d days" .LongestStreak}}</td>
+			<td>{{.StreakEnd}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+</section>
+{{end}}{{end}}
+
+{{if index .Sections "heatmap"}}{{with $heatmap := .Heatmap}}
+<section aria-labelledby="heatmap">
+<h2 class="h4" id="heatmap">{{t "Activity calendar"}}</h2>
+<p>{{t "Commits per day on all local branches from %s to %s, the darker the day the more commits." .Start .End}}</p>
+<div class="table-responsive">
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			{{if index $.Columns "email"}}<th scope="col" class="fixed-width">{{t "Email"}}</th>{{end}}
+			<th scope="col" class="fixed-width">{{t "Commits"}}</th>
+			<th scope="col">{{t "Calendar"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Contributors}}
+		<tr>
+			{{if index $.Columns "email"}}<td>{{email .Email}}</td>{{end}}
+			<td>{{.CommitCount}}</td>
+			<td class="text-success">{{heatmapChart $heatmap .}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</div>
+{{if .Omitted}}<p>{{t "%d less active contributors are not shown." .Omitted}}</p>{{end}}
+</section>
+{{end}}{{end}}
+
+{{if index .Sections "file-age"}}{{with .FileAge}}
+<section aria-labelledby="file-age">
+<h2 class="h4" id="file-age">{{t "Churn by file age"}}</h2>
+<p>{{t "Files of the main branch by age (since they were added under their current path) and churn (lines edited within the %d days up to %s). Files up to %d days old are young, files with at least %d lines edited (the median of the changed files) are hot." .WindowDays .End .YoungDays .HotChurn}}</p>
+<div class="table-responsive text-success mb-3">{{fileAgeChart .}}</div>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Quadrant"}}</th>
+			<th scope="col" class="fixed-width">{{t "Files"}}</th>
+			<th scope="col" class="fixed-width">{{t "Lines Edited"}}</th>
+			{{if not $.HidePaths}}<th scope="col">{{t "Hottest files"}}</th>{{end}}
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Quadrants}}
+		<tr>
+			<td>{{t .Name}}<br><small>{{t .Advice}}</small></td>
+			<td>{{.Files}}</td>
+			<td>{{.Churn}}</td>
+			{{if not $.HidePaths}}<td>{{range .Hottest}}{{.Path}} ({{.Churn}}, {{t "added"}} {{.Added}})<br>{{end}}</td>{{end}}
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</section>
+{{end}}{{end}}
+
+{{if index .Sections "themes"}}{{with .Themes}}
+<section aria-labelledby="commit-themes">
+<h2 class="h4" id="commit-themes">{{t "Commit themes"}}</h2>
+<p>{{t "The terms used most in commit subjects, the larger the term the more commits use it."}}</p>
+<p class="text-center lh-sm">{{range .Cloud}}<span class="fs-{{.Size}} me-2" title="{{.Count}}">{{.Term}}</span> {{end}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Period"}}</th>
+			<th scope="col">{{t "Top terms"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Periods}}
+		<tr>
+			<td>{{.Period}}</td>
+			<td>{{range $i, $term := .Terms}}{{if $i}}, {{end}}{{$term.Term}} ({{$term.Count}}){{end}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{if index $.Columns "email"}}
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Email"}}</th>
+			<th scope="col" class="fixed-width">{{t "Commits"}}</th>
+			<th scope="col">{{t "Top terms"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Authors}}
+		<tr>
+			<td>{{email .Email}}</td>
+			<td>{{.CommitCount}}</td>
+			<td>{{range $i, $term := .Terms}}{{if $i}}, {{end}}{{$term.Term}} ({{$term.Count}}){{end}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+</section>
+{{end}}{{end}}
+
+{{range $branchName, $branchReport := .BranchReports}}
+<section aria-labelledby="branch-{{$branchName}}">
+<h2 class="h4" id="branch-{{$branchName}}"> {{t "Branch:"}} <span class="badge text-bg-warning">{{$branchName}}</span></h2>
+{{with .Parsing}}{{if .LogError}}<p class="text-warning-emphasis">{{t "git log failed, only the commits read until then are counted: %s" .LogError}}</p>{{end}}
+{{if or .SkippedCommits .SkippedLines}}<p class="text-warning-emphasis">{{t "Incomplete statistics: %d commits and %d lines of git log output could not be parsed and were skipped." .SkippedCommits .SkippedLines}}</p>{{end}}{{end}}
+{{$periods := branchPeriods $branchReport}}
+{{if index $.Columns "timeline"}}<figure class="text-info">{{branchTimelineChart $branchReport}}<figcaption class="text-body-secondary small">{{t "Commits per period"}}</figcaption></figure>{{end}}
+
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			{{if index $.Columns "email"}}<th scope="col" class="fixed-width">{{t "Email"}}</th>{{end}}
+			{{if index $.Columns "commits"}}<th scope="col" class="fixed-width">{{t "Commit Count"}}</th>{{end}}
+			{{if index $.Columns "timeline"}}<th scope="col" class="fixed-width">{{t "Contribution Timeline"}}</th>{{end}}
+			{{if index $.Columns "added"}}<th scope="col">{{t "Lines Added"}}</th>{{end}}
+			{{if index $.Columns "removed"}}<th scope="col">{{t "Lines Removed"}}</th>{{end}}
+			{{if index $.Columns "edited"}}<th scope="col">{{t "Lines Edited"}}</th>{{end}}
+			{{if index $.Columns "files-per-commit"}}<th scope="col">{{t "Files per Commit"}}</th>{{end}}
+			{{if index $.Columns "distinct-files"}}<th scope="col">{{t "Distinct Files"}}</th>{{end}}
+			{{if index $.Columns "filter"}}<th scope="col">{{t "File Filter"}}</th>{{end}}
+			{{if and $.RoleNames (index $.Columns "roles")}}<th scope="col">{{t "Roles"}}{{if $.ShowLines}} ({{t "lines edited"}}){{end}}</th>{{end}}
+			{{if and $.TicketPolicy (index $.Columns "without-ticket")}}<th scope="col">{{t "Commits Without Ticket"}}</th>{{end}}
+		</tr>
+	</thead>
+	<tbody>
+		{{range topContributions .Contributions}}
+		<tr>
+			{{if index $.Columns "email"}}<td>{{if .Others}}{{t "%d others" .Others}}{{else}}{{email .Email}}{{end}}</td>{{end}}
+			{{if index $.Columns "commits"}}<td>{{.CommitCount}}</td>{{end}}
+			{{if index $.Columns "timeline"}}<td class="text-info">{{timelineChart .ContributionTimeline $periods}}</td>{{end}}
+			{{if index $.Columns "added"}}<td>{{.LinesAdded}}</td>{{end}}
+			{{if index $.Columns "removed"}}<td>{{.LinesRemoved}}</td>{{end}}
+			{{if index $.Columns "edited"}}<td>{{.LinesEdited}}</td>{{end}}
+			{{if index $.Columns "files-per-commit"}}<td>{{filesPerCommit .}}</td>{{end}}
+			{{if index $.Columns "distinct-files"}}<td>{{distinctFiles .}}</td>{{end}}
+			{{if index $.Columns "filter"}}<td>{{.FileFilter}}</td>{{end}}
+			{{if and $.RoleNames (index $.Columns "roles")}}
+			<td>
+				{{$contribution := .}}
+				{{range $role := $.RoleNames}}
+					{{with index $contribution.Roles $role}}{{$role}}: {{if $.ShowLines}}{{.}} ({{percent . $contribution.LinesEdited}}%){{else}}{{percent . $contribution.LinesEdited}}%{{end}}<br>{{end}}
+				{{end}}
+			</td>
+			{{end}}
+			{{if and $.TicketPolicy (index $.Columns "without-ticket")}}<td>{{.CommitsWithoutTicket}} ({{percent .CommitsWithoutTicket .CommitCount}}%)</td>{{end}}
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+
+{{if and .Imports (index $.Sections "imports")}}
+<h3 class="h5">{{t "Initial imports"}}</h3>
+<p>{{t "Commits importing code written elsewhere (pre-history of another system, vendored dependencies)."}}
+{{if eq $.ImportsMode "exclude"}}{{t "They are not counted as contributions."}}{{else if eq $.ImportsMode "separate"}}{{t "They are counted as contributions of '%s'." "imports"}}{{else}}{{t "They are counted as contributions of their authors."}}{{end}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Commit"}}</th>
+			<th scope="col" class="fixed-width">{{t "Date"}}</th>
+			{{if index $.Columns "email"}}<th scope="col" class="fixed-width">{{t "Email"}}</th>{{end}}
+			<th scope="col" class="fixed-width">{{t "Files"}}</th>
+			{{if $.ShowLines}}<th scope="col" class="fixed-width">{{t "Lines Added"}}</th>{{end}}
+			<th scope="col">{{t "Subject"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Imports}}
+		<tr>
+			<td><code>{{printf "%.10s" .Hash}}</code></td>
+			<td>{{.Date}}</td>
+			{{if index $.Columns "email"}}<td>{{email .Email}}</td>{{end}}
+			<td>{{.FileCount}}</td>
+			{{if $.ShowLines}}<td>{{.LinesAdded}}</td>{{end}}
+			<td>{{.Subject}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+
+{{if index $.Sections "backports"}}{{with .Backports}}
+<h3 class="h5">{{t "Backport coverage"}}</h3>
+<p>{{t "%d of %d mainline fixes since the merge-base %.10s have been backported" .BackportedCount (len .Fixes) .MergeBase}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Fix"}}</th>
+			<th scope="col" class="fixed-width">{{t "Date"}}</th>
+			<th scope="col" class="fixed-width">{{t "Email"}}</th>
+			<th scope="col">{{t "Subject"}}</th>
+			<th scope="col">{{t "Backport"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Fixes}}
+		<tr>
+			<td><code>{{printf "%.10s" .Hash}}</code></td>
+			<td>{{.Date}}</td>
+			<td>{{email .Email}}</td>
+			<td>{{.Subject}}</td>
+			<td>
+				{{if .Backported}}
+				<span class="badge text-bg-success">{{t "backported"}}</span> <code>{{printf "%.10s" .BackportHash}}</code> {{t "by %s on %s (matched by %s)" (email .BackportEmail) .BackportDate (t .MatchedBy)}}
+				{{else}}
+				<span class="badge text-bg-danger">{{t "missing"}}</span>
+				{{end}}
+			</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}{{end}}
+
+{{if and $.TicketPolicy (index $.Sections "compliance")}}
+<h3 class="h5">{{t "Commits without ticket reference"}}</h3>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Period"}}</th>
+			<th scope="col" class="fixed-width">{{t "Commit Count"}}</th>
+			<th scope="col">{{t "Commits Without Ticket"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range sortedCompliance .Compliance}}
+		<tr>
+			<td>{{.Period}}</td>
+			<td>{{.CommitCount}}</td>
+			<td>{{.WithoutTicket}} ({{percent .WithoutTicket .CommitCount}}%)</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+
+{{if index $.Sections "categories"}}{{range $category := $.Categories}}
+{{with index $branchReport.Categories $category.Key}}
+<h3 class="h5">{{t $category.Title}}</h3>
+<p>{{t "%d commits" .CommitCount}}{{if $.ShowLines}} {{t "with %d lines edited" .LinesEdited}}{{end}}{{if not $.HidePaths}} {{t "in: %s" (join $category.Patterns ", ")}}{{end}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Email"}}</th>
+			<th scope="col" class="fixed-width">{{t "Commit Count"}}</th>
+			{{if index $.Sections "timelines"}}<th scope="col" class="fixed-width">{{t "Timeline"}}</th>{{end}}
+			{{if $.ShowLines}}<th scope="col">{{t "Lines Edited"}}</th>{{end}}
+			<th scope="col">{{t "Last Change"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{$timeline := .Timeline}}
+		{{range sortCategoryContributions .Contributors}}
+		{{$contributorTimeline := .Timeline}}
+		<tr>
+			<td>{{email .Email}}</td>
+			<td>{{.CommitCount}}</td>
+			{{if index $.Sections "timelines"}}<td>{{range sortedPeriods $contributorTimeline}}{{.}}: {{index $contributorTimeline .}}<br>{{end}}</td>{{end}}
+			{{if $.ShowLines}}<td>{{.LinesEdited}}</td>{{end}}
+			<td>{{.LastChange}}</td>
+		</tr>
+		{{end}}
+		<tr>
+			<td>{{t "All contributors"}}</td>
+			<td>{{.CommitCount}}</td>
+			{{if index $.Sections "timelines"}}<td>{{range sortedPeriods $timeline}}{{.}}: {{index $timeline .}}<br>{{end}}</td>{{end}}
+			{{if $.ShowLines}}<td>{{.LinesEdited}}</td>{{end}}
+			<td></td>
+		</tr>
+	</tbody>
+</table>
+{{if .CommitsListed}}
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Commit"}}</th>
+			<th scope="col" class="fixed-width">{{t "Date"}}</th>
+			<th scope="col" class="fixed-width">{{t "Email"}}</th>
+			<th scope="col">{{t "Subject"}}</th>
+			{{if not $.HidePaths}}<th scope="col">{{t "Paths"}}</th>{{end}}
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Commits}}
+		<tr>
+			<td><code>{{printf "%.10s" .Hash}}</code></td>
+			<td>{{.Date}}</td>
+			<td>{{email .Email}}</td>
+			<td>{{.Subject}}</td>
+			{{if not $.HidePaths}}<td>{{range .Paths}}{{.}}<br>{{end}}</td>{{end}}
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+{{end}}
+{{end}}{{end}}
+
+{{if index $.Sections "forecast"}}{{with .Forecast}}
+<h3 class="h5">{{t "Activity forecast"}}</h3>
+<p>{{t "Extrapolated from %d periods with exponential smoothing, ranges are 95%% prediction intervals." .HistoryPeriods}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Period"}}</th>
+			<th scope="col" class="fixed-width">{{t "Commits"}}</th>
+			{{if $.ShowLines}}<th scope="col">{{t "Lines Edited"}}</th>{{end}}
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Periods}}
+		<tr>
+			<td>{{.Period}}</td>
+			<td>{{printf "%.0f" .Commits.Value}} <span class="text-body-secondary">({{printf "%.0f" .Commits.Low}}&ndash;{{printf "%.0f" .Commits.High}})</span></td>
+			{{if $.ShowLines}}<td>{{printf "%.0f" .LinesEdited.Value}} <span class="text-body-secondary">({{printf "%.0f" .LinesEdited.Low}}&ndash;{{printf "%.0f" .LinesEdited.High}})</span></td>{{end}}
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}{{end}}
+
+{{if and .Components (index $.Sections "components")}}
+<h3 class="h5">{{t "Contributions by component"}}</h3>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Component"}}</th>
+			<th scope="col" class="fixed-width">{{t "Commit Count"}}</th>
+			{{if $.ShowLines}}<th scope="col" class="fixed-width">{{t "Lines Edited"}}</th>{{end}}
+			<th scope="col">{{t "Contributors (commits)"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range sortComponents .Components}}
+		<tr>
+			<td>{{.Name}}</td>
+			<td>{{.CommitCount}}</td>
+			{{if $.ShowLines}}<td>{{.LinesEdited}}</td>{{end}}
+			<td>{{range sortCategoryContributions .Contributors}}{{email .Email}}: {{.CommitCount}}<br>{{end}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+
+{{if and .Paths (index $.Sections "paths") (not $.HidePaths)}}
+<h3 class="h5">{{if eq $.PathBreakdown "file"}}{{t "Contributions by file"}}{{else}}{{t "Contributions by directory"}}{{end}}</h3>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col">{{t "Path"}}</th>
+			<th scope="col" class="fixed-width">{{t "Commit Count"}}</th>
+			{{if $.ShowLines}}<th scope="col" class="fixed-width">{{t "Lines Added"}}</th>
+			<th scope="col" class="fixed-width">{{t "Lines Removed"}}</th>{{end}}
+			<th scope="col">{{if $.ShowLines}}{{t "Contributors (lines edited)"}}{{else}}{{t "Contributors (commits)"}}{{end}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range $path := sortPaths .Paths}}
+		<tr>
+			<td><code>{{$path.Path}}</code></td>
+			<td>{{$path.CommitCount}}</td>
+			{{if $.ShowLines}}<td>{{$path.LinesAdded}}</td>
+			<td>{{$path.LinesRemoved}}</td>{{end}}
+			<td>{{range sortPathAuthors $path.Authors}}{{email .Email}}: {{if $.ShowLines}}{{.LinesEdited}} ({{percent .LinesEdited $path.LinesEdited}}%){{else}}{{.CommitCount}}{{end}}<br>{{end}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+
+{{if index $.Sections "focus"}}
+<h3 class="h5">{{t "Focus by contributor"}}</h3>
+<p>{{t "Distinct areas (directories or components) touched per period, many areas indicate frequent context switching."}} {{t "Distinct files next to the areas tell wide shallow changes from deep focused ones."}}</p>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col" class="fixed-width">{{t "Email"}}</th>
+			<th scope="col" class="fixed-width">{{t "Areas per period"}}</th>
+			<th scope="col" class="fixed-width">{{t "Files per period"}}</th>
+			<th scope="col">{{t "Trend"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range $contribution := sortContributions .Contributions}}{{with focusTrend $contribution}}
+		<tr>
+			<td>{{email $contribution.Email}}</td>
+			<td>{{range .}}{{.Period}}: {{.Areas}}<br>{{end}}</td>
+			<td>{{range .}}{{.Period}}: {{.Files}}<br>{{end}}</td>
+			<td>{{sparkline .}}</td>
+		</tr>
+		{{end}}{{end}}
+	</tbody>
+</table>
+{{end}}
+
+{{if and .Initiatives (index $.Sections "initiatives")}}
+<h3 class="h5">{{t "Work by initiative"}}</h3>
+<table class="table {{$.TableTheme}} table-striped">
+	<thead>
+		<tr>
+			<th scope="col">{{t "Initiative"}}</th>
+			<th scope="col">{{t "Project"}}</th>
+			<th scope="col">{{t "Tickets"}}</th>
+			<th scope="col">{{t "Commit Count"}}</th>
+			{{if $.ShowLines}}<th scope="col">{{t "Lines Edited"}}</th>{{end}}
+			<th scope="col">{{t "Contributors"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Initiatives}}
+		<tr>
+			<td>{{.Name}}</td>
+			<td>{{.Project}}</td>
+			<td>{{join .Tickets ", "}}</td>
+			<td>{{.CommitCount}}</td>
+			{{if $.ShowLines}}<td>{{.LinesEdited}}</td>{{end}}
+			<td>{{range .Contributors}}{{email .}}<br>{{end}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+{{end}}
+</section>
+{{end}}
+</main>
+
+<script>
+const themeToggle = document.getElementById('themeToggle');
+let currentTheme = {{.Theme}};
+
+themeToggle.addEventListener('click', () => {
+	if (currentTheme === 'dark') {
+		document.documentElement.setAttribute('data-bs-theme', 'light');
+		document.querySelectorAll('table').forEach(table => {
+			table.classList.remove('table-dark');
+		});
+		themeToggle.classList.replace('btn-outline-light', 'btn-outline-dark');
+		themeToggle.textContent = {{t "Dark Theme"}};
+		currentTheme = 'light';
+	} else {
+		document.documentElement.setAttribute('data-bs-theme', 'dark');
+		document.querySelectorAll('table').forEach(table => {
+			table.classList.add('table-dark');
+		});
+		themeToggle.classList.replace('btn-outline-dark', 'btn-outline-light');
+		themeToggle.textContent = {{t "Light Theme"}};
+		currentTheme = 'dark';
+	}
+	themeToggle.setAttribute('aria-pressed', currentTheme === 'dark');
+});
+
+const reportSearch = document.getElementById('reportSearch');
+const reportSearchStatus = document.getElementById('reportSearchStatus');
+
+reportSearch.addEventListener('input', () => {
+	const query = reportSearch.value.trim().toLowerCase();
+	let matches = 0;
+	document.querySelectorAll('tbody tr').forEach(row => {
+		row.hidden = query !== '' && !row.textContent.toLowerCase().includes(query);
+		if (!row.hidden) {
+			matches++;
+		}
+	});
+	// hide tables and sections without matching rows, so results of all branches fit on one screen
+	document.querySelectorAll('table').forEach(table => {
+		table.hidden = query !== '' && table.querySelector('tbody tr:not([hidden])') === null;
+	});
+	document.querySelectorAll('main > section').forEach(section => {
+		section.hidden = query !== '' && section.querySelector('table:not([hidden])') === null;
+	});
+	reportSearchStatus.textContent = query === '' ? '' : {{t "%d matching rows"}}.replace('%d', matches);
+});
+
+</script>
+</body>
+</html>
+`
+	t, err := template.New("report").Funcs(run.reportTemplateFuncs()).Parse(tmpl)
+
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = t.Execute(&buf, data)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// newReportData collects everything the report templates need.
+func (run *analysisRun) newReportData(branchReports map[string]*BranchReport, repoName string, fileFilter string) ReportData {
+	columns, sections := run.reportLayout()
+
+	var delivery *DeliveryMetrics
+	var reviews *ReviewLatency
+	var contention *ContentionReport
+	var pairing *PairingReport
+	var signatures *SignatureReport
+	var localActivity *LocalActivity
+	var leaderboard *Leaderboard
+	var heatmap *Heatmap
+	var fileAge *FileAgeChurn
+	var totals *RepositoryTotals
+	var naming *BranchNamingReport
+	var protected *ProtectedPathReport
+	var departments []*DepartmentRollup
+	if mainReport, ok := branchReports[run.mainBranch]; ok {
+		delivery = mainReport.Delivery
+		reviews = mainReport.Reviews
+		contention = mainReport.Contention
+		pairing = mainReport.Pairing
+		signatures = mainReport.Signatures
+		localActivity = mainReport.LocalActivity
+		leaderboard = mainReport.Leaderboard
+		heatmap = mainReport.Heatmap
+		fileAge = mainReport.FileAge
+		totals = mainReport.Totals
+		naming = mainReport.Naming
+		protected = mainReport.Protected
+		departments = mainReport.Departments
+	}
+
+	tableTheme := ""
+	if run.theme == "dark" {
+		tableTheme = "table-dark"
+	}
+
+	return ReportData{
+		RepoName:      repoName,
+		MainBranch:    run.mainBranch,
+		GroupBy:       run.groupBy,
+		Language:      run.language,
+		Theme:         run.theme,
+		TableTheme:    tableTheme,
+		Columns:       columns,
+		Sections:      sections,
+		ShowLines:     columns["added"] || columns["removed"] || columns["edited"],
+		HidePaths:     run.profile.HidePaths,
+		PathBreakdown: run.pathBreakdown,
+		Weighted:      len(run.config.Weights) > 0,
+		ImportsMode:   run.importsMode,
+		Since:         run.since,
+		Until:         run.until,
+		RevRange:      run.revRange,
+		FileFilter:    fileFilter,
+		RoleNames:     run.config.roleNames(),
+		TicketPolicy:  run.config.TicketPolicy,
+		Categories:    run.config.pathCategories(),
+		Overlap:       buildOverlapMatrix(branchReports),
+		AtRisk:        branchesAtRisk(branchReports),
+		Delivery:      delivery,
+		Reviews:       reviews,
+		Contention:    contention,
+		Pairing:       pairing,
+		Signatures:    signatures,
+		LocalActivity: localActivity,
+		Leaderboard:   leaderboard,
+		Heatmap:       heatmap,
+		FileAge:       fileAge,
+		Totals:        totals,
+		Naming:        naming,
+		Protected:     protected,
+		Themes:        reportThemes(branchReports, totals),
+		Departments:   departments,
+		Teams:         rollupTeams(reportContributions(branchReports, totals), run.teams),
+		Summary:       run.buildExecutiveSummary(branchReports),
+		BranchReports: branchReports,
+	}
+}
+
+// reportLayout returns the columns (see REPORT_COLUMNS) and the sections (see REPORT_SECTIONS) shown in the reports.
+func (run *analysisRun) reportLayout() (map[string]bool, map[string]bool) {
+	columns := make(map[string]bool)
+	for _, column := range run.profile.reportColumns(run.columns) {
+		columns[column] = true
+	}
+	sections := make(map[string]bool)
+	for _, section := range run.sections {
+		sections[section] = true
+	}
+	if !sections["timelines"] {
+		columns["timeline"] = false
+	}
+	return columns, sections
+}
+
+// sortContributions orders the contributions of a branch by run.sortBy: counts descending, emails ascending.
+// Ties are ordered by email.
+func (run *analysisRun) sortContributions(contributions map[string]*UserContribution) []*UserContribution {
+	sorted := make([]*UserContribution, 0, len(contributions))
+	for _, c := range contributions {
+		sorted = append(sorted, c)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		var a, b int
+		switch run.sortBy {
+		case "commits":
+			a, b = sorted[i].CommitCount, sorted[j].CommitCount
+		case "lines-edited":
+			a, b = sorted[i].LinesEdited, sorted[j].LinesEdited
+		case "email":
+			// ordered by email below
+		default:
+			a, b = sorted[i].LinesAdded, sorted[j].LinesAdded
+		}
+		if a != b {
+			return a > b
+		}
+		return sorted[i].Email < sorted[j].Email
+	})
+	return sorted
+}
+
+// topContributions orders the contributions of a branch like sortContributions and keeps the first
+// run.top of them, the others are summed up in a last row (see UserContribution.Others).
+func (run *analysisRun) topContributions(contributions map[string]*UserContribution) []*UserContribution {
+	sorted := run.sortContributions(contributions)
+	if run.top <= 0 || len(sorted) <= run.top+1 {
+		return sorted
+	}
+
+	others := &UserContribution{
+		Email:                OTHERS_CONTRIBUTOR,
+		ContributionTimeline: make(map[string]int),
+		Roles:                make(map[string]int),
+		TouchedFiles:         make(map[string]map[string]bool),
+		Others:               len(sorted) - run.top,
+	}
+	for _, contribution := range sorted[run.top:] {
+		others.CommitCount += contribution.CommitCount
+		others.LinesAdded += contribution.LinesAdded
+		others.LinesRemoved += contribution.LinesRemoved
+		others.LinesEdited += contribution.LinesEdited
+		others.FilesTouched += contribution.FilesTouched
+		others.CommitsWithoutTicket += contribution.CommitsWithoutTicket
+		others.FileFilter = contribution.FileFilter
+		for period, count := range contribution.ContributionTimeline {
+			others.ContributionTimeline[period] += count
+		}
+		for role, lines := range contribution.Roles {
+			others.Roles[role] += lines
+		}
+		for period, files := range contribution.TouchedFiles {
+			if others.TouchedFiles[period] == nil {
+				others.TouchedFiles[period] = make(map[string]bool)
+			}
+			for file := range files {
+				others.TouchedFiles[period][file] = true
+			}
+		}
+	}
+	return append(sorted[:run.top:run.top], others)
+}
+
+// reportTemplateFuncs returns the helper functions shared by all report templates.
+func (run *analysisRun) reportTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"sortContributions":         run.sortContributions,
+		"topContributions":          run.topContributions,
+		"join":                      strings.Join,
+		"bootstrapStyles":           run.bootstrapStyles,
+		"bootstrapScripts":          run.bootstrapScripts,
+		"t":                         run.translate,
+		"email":                     run.profile.displayEmail,
+		"sortedCompliance":          sortedCompliance,
+		"sortedPeriods":             sortedPeriods,
+		"sortCategoryContributions": sortCategoryContributions,
+		"sortComponents":            sortComponents,
+		"sortPaths":                 sortPaths,
+		"sortPathAuthors":           sortPathAuthors,
+		"focusTrend":                focusTrend,
+		"filesPerCommit":            filesPerCommit,
+		"distinctFiles":             distinctFiles,
+		"sparkline":                 sparkline,
+		"branchPeriods":             run.branchPeriods,
+		"timelineChart":             run.timelineChart,
+		"teamPeriods":               teamPeriods,
+		"branchTimelineChart":       run.branchTimelineChart,
+		"heatmapChart":              heatmapChart,
+		"fileAgeChart":              run.fileAgeChart,
+		"percent": func(part int, total int) int {
+			if total == 0 {
+				return 0
+			}
+			return part * 100 / total
+		},
+	}
+}
@@ -0,0 +1,140 @@
+package gogitstats
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// heatmapDays is the number of days covered by the activity calendars, ending with the last active day.
+const heatmapDays = 365
+
+// maxHeatmapContributors limits the activity calendars to the most active contributors.
+const maxHeatmapContributors = 20
+
+// dimensions of a day of the activity calendars in pixels.
+const heatmapCellSize = 10
+const heatmapCellGap = 2
+
+// HEATMAP_OPACITIES are the opacities of the levels of activity (no commits, then quartiles of the busiest day).
+var HEATMAP_OPACITIES = []float64{0.08, 0.3, 0.55, 0.8, 1}
+
+// ContributorHeatmap holds the commits of a contributor per day within the period of the activity calendars.
+type ContributorHeatmap struct {
+	Email       string
+	CommitCount int
+	Days        map[string]int // 2006-01-02: commits
+}
+
+// Heatmap is the activity calendar of the most active contributors, a grid of days colored by commits similar
+// to the contribution graphs of GitHub profiles.
+type Heatmap struct {
+	Start        string // 2006-01-02
+	End          string // 2006-01-02, the last active day
+	MaxCount     int    // commits of the busiest day of any contributor
+	Contributors []*ContributorHeatmap
+	Omitted      int // less active contributors beyond maxHeatmapContributors
+}
+
+// assessHeatmap builds the activity calendars of the commits on all local branches, the heatmap is attached to
+// the main branch report.
+func (run *analysisRun) assessHeatmap(repoPath string, branchReports map[string]*BranchReport, fileFilter string) {
+	report, ok := branchReports[run.mainBranch]
+	if !ok {
+		return
+	}
+
+	activeDays, err := run.listActiveDays(repoPath, fileFilter)
+	if err != nil {
+		log.Printf("Building the activity calendars failed: %v", err)
+		report.Heatmap = nil
+		return
+	}
+	report.Heatmap = buildHeatmap(activeDays)
+}
+
+// buildHeatmap limits the active days to the heatmapDays ending with the last active day of any contributor.
+//
+// Parameters:
+//   - activeDays: The commits by email and day, see listActiveDays.
+//
+// Returns:
+//   - The heatmap, nil if there are no commits.
+func buildHeatmap(activeDays map[string]map[string]int) *Heatmap {
+	end := ""
+	for _, days := range activeDays {
+		for day := range days {
+			end = max(end, day)
+		}
+	}
+	endDate, err := time.Parse("2006-01-02", end)
+	if err != nil {
+		return nil
+	}
+
+	heatmap := &Heatmap{End: end, Start: endDate.AddDate(0, 0, 1-heatmapDays).Format("2006-01-02")}
+	for email, days := range activeDays {
+		contributor := &ContributorHeatmap{Email: email, Days: make(map[string]int)}
+		for day, count := range days {
+			if day >= heatmap.Start {
+				contributor.Days[day] = count
+				contributor.CommitCount += count
+				heatmap.MaxCount = max(heatmap.MaxCount, count)
+			}
+		}
+		if contributor.CommitCount > 0 {
+			heatmap.Contributors = append(heatmap.Contributors, contributor)
+		}
+	}
+
+	sort.Slice(heatmap.Contributors, func(i, j int) bool {
+		if heatmap.Contributors[i].CommitCount != heatmap.Contributors[j].CommitCount {
+			return heatmap.Contributors[i].CommitCount > heatmap.Contributors[j].CommitCount
+		}
+		return heatmap.Contributors[i].Email < heatmap.Contributors[j].Email
+	})
+	if len(heatmap.Contributors) > maxHeatmapContributors {
+		heatmap.Omitted = len(heatmap.Contributors) - maxHeatmapContributors
+		heatmap.Contributors = heatmap.Contributors[:maxHeatmapContributors]
+	}
+	return heatmap
+}
+
+// heatmapChart renders the activity calendar of a contributor as inline SVG: a column per week (starting on
+// Sunday) and a row per weekday, each day names its date and commits as tooltip.
+func heatmapChart(heatmap *Heatmap, contributor *ContributorHeatmap) template.HTML {
+	start, errStart := time.Parse("2006-01-02", heatmap.Start)
+	end, errEnd := time.Parse("2006-01-02", heatmap.End)
+	if errStart != nil || errEnd != nil || heatmap.MaxCount == 0 {
+		return ""
+	}
+
+	// the first column starts on the Sunday of the week of the first day
+	gridStart := start.AddDate(0, 0, -int(start.Weekday()))
+	weeks := int(end.Sub(gridStart).Hours()/24)/7 + 1
+	width := weeks*(heatmapCellSize+heatmapCellGap) - heatmapCellGap
+	height := 7*(heatmapCellSize+heatmapCellGap) - heatmapCellGap
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg class="heatmap-chart" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="%s">`,
+		width, height, width, height, html.EscapeString(fmt.Sprintf("%s - %s: %d", heatmap.Start, heatmap.End, contributor.CommitCount)))
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		day := date.Format("2006-01-02")
+		count := contributor.Days[day]
+		level := 0
+		if count > 0 {
+			level = min((count*4+heatmap.MaxCount-1)/heatmap.MaxCount, 4)
+		}
+		week := int(date.Sub(gridStart).Hours()/24) / 7
+		fmt.Fprintf(&svg, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" fill="currentColor" fill-opacity="%.2f"><title>%s: %d</title></rect>`,
+			week*(heatmapCellSize+heatmapCellGap), int(date.Weekday())*(heatmapCellSize+heatmapCellGap), heatmapCellSize, heatmapCellSize,
+			HEATMAP_OPACITIES[level], day, count)
+	}
+	svg.WriteString(`</svg>`)
+
+	return template.HTML(svg.String())
+}
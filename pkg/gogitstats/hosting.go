@@ -0,0 +1,300 @@
+package gogitstats
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxHostingPages limits the number of pages (of 100 entries) fetched from a hosting API per run.
+const maxHostingPages = 20
+
+const HOSTING_GITHUB = "GitHub"
+const HOSTING_GITLAB = "GitLab"
+
+// MergedChange is a merged pull request (GitHub) or merge request (GitLab).
+type MergedChange struct {
+	Number       int
+	Title        string
+	Author       string // login of the author on the hosting platform
+	TargetBranch string
+	OpenedAt     time.Time
+	MergedAt     time.Time
+	MergeCommit  string // commit created on the target branch: merge or squash commit (GitHub), squash commit (GitLab)
+}
+
+// ChangeCommit is a commit of a pull or merge request as pushed by its author, before it was squashed.
+type ChangeCommit struct {
+	Hash    string
+	Email   string
+	Message string
+}
+
+// hostingClient fetches data from the API of the platform hosting the repository.
+type hostingClient interface {
+	// provider returns the name of the hosting platform.
+	provider() string
+	// mergedChanges returns the changes merged into the given branch, most recently updated first.
+	mergedChanges(targetBranch string) ([]*MergedChange, error)
+	// changeCommits returns the commits of a merged change, oldest first.
+	changeCommits(change *MergedChange) ([]*ChangeCommit, error)
+}
+
+type githubClient struct {
+	apiURL     string
+	repository string // owner/name
+	client     *http.Client
+}
+
+type githubPullRequest struct {
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	CreatedAt time.Time  `json:"created_at"`
+	MergedAt  *time.Time `json:"merged_at"`
+	MergeSHA  string     `json:"merge_commit_sha"`
+	User      struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+type gitlabClient struct {
+	baseURL string
+	project string // group/project or numeric ID
+	client  *http.Client
+}
+
+type gitlabMergeRequest struct {
+	IID          int        `json:"iid"`
+	Title        string     `json:"title"`
+	CreatedAt    time.Time  `json:"created_at"`
+	MergedAt     *time.Time `json:"merged_at"`
+	TargetBranch string     `json:"target_branch"`
+	Squash       bool       `json:"squash"`
+	SquashSHA    string     `json:"squash_commit_sha"`
+	Author       struct {
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
+// mergedChange returns the pull request as merged change, nil if it was closed without merge.
+func (pull *githubPullRequest) mergedChange() *MergedChange {
+	if pull.MergedAt == nil {
+		return nil
+	}
+	return &MergedChange{
+		Number:       pull.Number,
+		Title:        pull.Title,
+		Author:       pull.User.Login,
+		TargetBranch: pull.Base.Ref,
+		OpenedAt:     pull.CreatedAt,
+		MergedAt:     *pull.MergedAt,
+		MergeCommit:  pull.MergeSHA,
+	}
+}
+
+// mergedChange returns the merge request as merged change, nil if it was not merged.
+func (mergeRequest *gitlabMergeRequest) mergedChange() *MergedChange {
+	if mergeRequest.MergedAt == nil {
+		return nil
+	}
+	mergeCommit := ""
+	if mergeRequest.Squash {
+		mergeCommit = mergeRequest.SquashSHA
+	}
+	return &MergedChange{
+		Number:       mergeRequest.IID,
+		Title:        mergeRequest.Title,
+		Author:       mergeRequest.Author.Username,
+		TargetBranch: mergeRequest.TargetBranch,
+		OpenedAt:     mergeRequest.CreatedAt,
+		MergedAt:     *mergeRequest.MergedAt,
+		MergeCommit:  mergeCommit,
+	}
+}
+
+// newGitHubClient creates a client of the GitHub REST API (or GitHub Enterprise with a custom apiURL).
+//
+// The token is taken from the environment variable GITHUB_TOKEN, public repositories work without it.
+func newGitHubClient(apiURL string, repository string) *githubClient {
+	return &githubClient{
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		repository: strings.Trim(repository, "/"),
+		client:     newAPIClient(apiCacheDirectory()),
+	}
+}
+
+func (github *githubClient) provider() string {
+	return HOSTING_GITHUB
+}
+
+func (github *githubClient) newRequest(requestURL string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", requestURL, err)
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	return req, nil
+}
+
+func (github *githubClient) mergedChanges(targetBranch string) ([]*MergedChange, error) {
+	var changes []*MergedChange
+	for page := 1; page <= maxHostingPages; page++ {
+		pullsURL := fmt.Sprintf("%s/repos/%s/pulls?state=closed&base=%s&sort=updated&direction=desc&per_page=100&page=%d",
+			github.apiURL, github.repository, url.QueryEscape(targetBranch), page)
+		req, err := github.newRequest(pullsURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var pulls []githubPullRequest
+		if err := doJSONRequest(github.client, req, &pulls); err != nil {
+			return nil, fmt.Errorf("failed to fetch pull requests of %s: %w", github.repository, err)
+		}
+
+		for _, pull := range pulls {
+			if change := pull.mergedChange(); change != nil {
+				changes = append(changes, change)
+			}
+		}
+
+		if len(pulls) < 100 {
+			break
+		}
+	}
+	return changes, nil
+}
+
+type githubPullCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Email string `json:"email"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+func (github *githubClient) changeCommits(change *MergedChange) ([]*ChangeCommit, error) {
+	var commits []*ChangeCommit
+	for page := 1; page <= maxHostingPages; page++ {
+		commitsURL := fmt.Sprintf("%s/repos/%s/pulls/%d/commits?per_page=100&page=%d", github.apiURL, github.repository, change.Number, page)
+		req, err := github.newRequest(commitsURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var pullCommits []githubPullCommit
+		if err := doJSONRequest(github.client, req, &pullCommits); err != nil {
+			return nil, fmt.Errorf("failed to fetch commits of pull request #%d: %w", change.Number, err)
+		}
+
+		for _, commit := range pullCommits {
+			commits = append(commits, &ChangeCommit{Hash: commit.SHA, Email: commit.Commit.Author.Email, Message: commit.Commit.Message})
+		}
+
+		if len(pullCommits) < 100 {
+			break
+		}
+	}
+	return commits, nil
+}
+
+// newGitLabClient creates a client of the GitLab REST API (gitlab.com or self-managed).
+//
+// The token is taken from the environment variable GITLAB_TOKEN, public projects work without it.
+func newGitLabClient(baseURL string, project string) *gitlabClient {
+	return &gitlabClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		project: strings.Trim(project, "/"),
+		client:  newAPIClient(apiCacheDirectory()),
+	}
+}
+
+func (gitlab *gitlabClient) provider() string {
+	return HOSTING_GITLAB
+}
+
+func (gitlab *gitlabClient) newRequest(requestURL string) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", requestURL, err)
+	}
+	if token := os.Getenv("GITLAB_TOKEN"); token != "" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+func (gitlab *gitlabClient) mergedChanges(targetBranch string) ([]*MergedChange, error) {
+	var changes []*MergedChange
+	for page := 1; page <= maxHostingPages; page++ {
+		requestsURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests?state=merged&target_branch=%s&order_by=updated_at&sort=desc&per_page=100&page=%d",
+			gitlab.baseURL, url.PathEscape(gitlab.project), url.QueryEscape(targetBranch), page)
+		req, err := gitlab.newRequest(requestsURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var mergeRequests []gitlabMergeRequest
+		if err := doJSONRequest(gitlab.client, req, &mergeRequests); err != nil {
+			return nil, fmt.Errorf("failed to fetch merge requests of %s: %w", gitlab.project, err)
+		}
+
+		for _, mergeRequest := range mergeRequests {
+			if change := mergeRequest.mergedChange(); change != nil {
+				changes = append(changes, change)
+			}
+		}
+
+		if len(mergeRequests) < 100 {
+			break
+		}
+	}
+	return changes, nil
+}
+
+type gitlabMergeRequestCommit struct {
+	ID          string `json:"id"`
+	AuthorEmail string `json:"author_email"`
+	Message     string `json:"message"`
+}
+
+func (gitlab *gitlabClient) changeCommits(change *MergedChange) ([]*ChangeCommit, error) {
+	var commits []*ChangeCommit
+	for page := 1; page <= maxHostingPages; page++ {
+		commitsURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d/commits?per_page=100&page=%d",
+			gitlab.baseURL, url.PathEscape(gitlab.project), change.Number, page)
+		req, err := gitlab.newRequest(commitsURL)
+		if err != nil {
+			return nil, err
+		}
+
+		var requestCommits []gitlabMergeRequestCommit
+		if err := doJSONRequest(gitlab.client, req, &requestCommits); err != nil {
+			return nil, fmt.Errorf("failed to fetch commits of merge request !%d: %w", change.Number, err)
+		}
+
+		for _, commit := range requestCommits {
+			commits = append(commits, &ChangeCommit{Hash: commit.ID, Email: commit.AuthorEmail, Message: commit.Message})
+		}
+
+		if len(requestCommits) < 100 {
+			break
+		}
+	}
+
+	// GitLab lists the commits of merge requests newest first
+	slices.Reverse(commits)
+	return commits, nil
+}
@@ -0,0 +1,305 @@
+package gogitstats
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// API_CACHE_DIRECTORY holds the responses of hosting APIs below the cache directory, see apiTransport.
+const API_CACHE_DIRECTORY = "api"
+
+// maxAPIRetries limits the retries of a request failing with a server error or a network error.
+const maxAPIRetries = 4
+
+// maxRateLimitWait is the longest wait for the reset of an exceeded rate limit, longer waits fail the request.
+const maxRateLimitWait = time.Hour
+
+// apiTransport sends the requests of REST API clients (e.g., GitHub, GitLab), so runs over many
+// repositories do not fail halfway through:
+//
+//   - Exceeded rate limits (429, or 403 with an exhausted limit) are waited out, using the headers Retry-After,
+//     X-RateLimit-Reset (GitHub) or RateLimit-Reset (GitLab). Once the remaining limit reaches 0, further
+//     requests wait for the reset instead of failing.
+//   - Server errors (5xx) and network errors are retried with exponential backoff.
+//   - Responses of GET requests are cached on disk (if cacheDirectory is set) and revalidated with
+//     If-None-Match (ETag) or If-Modified-Since, unchanged responses do not count against the GitHub rate limit.
+type apiTransport struct {
+	base           http.RoundTripper
+	cacheDirectory string
+
+	mutex        sync.Mutex
+	blockedUntil time.Time
+}
+
+// cachedAPIResponse is a response of an API stored on disk by apiTransport.
+type cachedAPIResponse struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag"`
+	LastModified string `json:"lastModified"`
+	ContentType  string `json:"contentType"`
+	Body         []byte `json:"body"`
+}
+
+// newAPIClient creates an HTTP client for REST APIs, see apiTransport.
+//
+// Parameters:
+//   - cacheDirectory: The directory responses are cached in, no caching if empty.
+func newAPIClient(cacheDirectory string) *http.Client {
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: &apiTransport{base: http.DefaultTransport, cacheDirectory: cacheDirectory},
+	}
+}
+
+// apiCacheDirectory returns the directory of cached API responses next to the analysis cache.
+func apiCacheDirectory() string {
+	return filepath.Join(REPOSITORIES_DIRECTORY, CACHE_DIRECTORY, API_CACHE_DIRECTORY)
+}
+
+func (transport *apiTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cached := transport.loadResponse(req)
+
+	for attempt := 0; ; attempt++ {
+		transport.waitForRateLimit()
+
+		attemptReq := req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+		if cached != nil {
+			if cached.ETag != "" {
+				attemptReq.Header.Set("If-None-Match", cached.ETag)
+			} else {
+				attemptReq.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+
+		resp, err := transport.base.RoundTrip(attemptReq)
+		retryable := req.Method == http.MethodGet || req.Body == nil || req.GetBody != nil
+		if err != nil {
+			if attempt >= maxAPIRetries || !retryable {
+				return nil, err
+			}
+			delay := backoffDelay(attempt)
+			log.Printf("Request to %s failed, retrying in %s: %v", req.URL.Host, delay, err)
+			time.Sleep(delay)
+			continue
+		}
+
+		if wait, limited := rateLimitWait(resp); limited {
+			if wait > maxRateLimitWait || attempt >= maxAPIRetries {
+				return resp, nil
+			}
+			resp.Body.Close()
+			log.Printf("Rate limit of %s exceeded, waiting %s for its reset", req.URL.Host, wait.Round(time.Second))
+			transport.blockUntil(time.Now().Add(wait))
+			continue
+		}
+		transport.trackRateLimit(resp)
+
+		if resp.StatusCode >= 500 && attempt < maxAPIRetries && retryable {
+			resp.Body.Close()
+			delay := backoffDelay(attempt)
+			log.Printf("Request to %s failed with status %s, retrying in %s", req.URL.Host, resp.Status, delay)
+			time.Sleep(delay)
+			continue
+		}
+
+		if resp.StatusCode == http.StatusNotModified && cached != nil {
+			resp.Body.Close()
+			return cached.response(req), nil
+		}
+		if resp.StatusCode == http.StatusOK && req.Method == http.MethodGet && transport.cacheDirectory != "" {
+			return transport.storeResponse(req, resp)
+		}
+		return resp, nil
+	}
+}
+
+// waitForRateLimit blocks until the reset of an exhausted rate limit.
+func (transport *apiTransport) waitForRateLimit() {
+	transport.mutex.Lock()
+	wait := time.Until(transport.blockedUntil)
+	transport.mutex.Unlock()
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+func (transport *apiTransport) blockUntil(until time.Time) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	if until.After(transport.blockedUntil) {
+		transport.blockedUntil = until
+	}
+}
+
+// trackRateLimit blocks further requests until the reset, if the response exhausted the rate limit.
+func (transport *apiTransport) trackRateLimit(resp *http.Response) {
+	remaining := firstHeader(resp.Header, "X-RateLimit-Remaining", "RateLimit-Remaining")
+	if remaining != "0" {
+		return
+	}
+	if reset, ok := rateLimitReset(resp.Header); ok {
+		wait := time.Until(reset)
+		if wait > 0 && wait <= maxRateLimitWait {
+			log.Printf("Rate limit of %s exhausted, further requests wait %s for its reset", resp.Request.URL.Host, wait.Round(time.Second))
+			transport.blockUntil(reset)
+		}
+	}
+}
+
+// rateLimitWait returns the time to wait, if the response reports an exceeded rate limit.
+func rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	exhausted := firstHeader(resp.Header, "X-RateLimit-Remaining", "RateLimit-Remaining") == "0"
+	if resp.StatusCode != http.StatusTooManyRequests && !(resp.StatusCode == http.StatusForbidden && (exhausted || resp.Header.Get("Retry-After") != "")) {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if reset, ok := rateLimitReset(resp.Header); ok {
+		// a second of tolerance for clock skew
+		return max(time.Until(reset), 0) + time.Second, true
+	}
+	// secondary rate limits of GitHub without further headers
+	return time.Minute, true
+}
+
+// rateLimitReset returns the reset of the rate limit given in epoch seconds by GitHub and GitLab.
+func rateLimitReset(header http.Header) (time.Time, bool) {
+	seconds, err := strconv.ParseInt(firstHeader(header, "X-RateLimit-Reset", "RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
+
+func firstHeader(header http.Header, names ...string) string {
+	for _, name := range names {
+		if value := header.Get(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// backoffDelay returns the exponential delay before the retry of the given attempt (1s, 2s, 4s, ...).
+func backoffDelay(attempt int) time.Duration {
+	return time.Second << attempt
+}
+
+// responseCachePath returns the file of the cached response, the credentials are part of the key, so
+// responses are never shared between tokens.
+func (transport *apiTransport) responseCachePath(req *http.Request) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%s\n%s", req.URL.String(), req.Header.Get("Authorization"), req.Header.Get("PRIVATE-TOKEN"), req.Header.Get("Accept"))
+	return filepath.Join(transport.cacheDirectory, hex.EncodeToString(hash.Sum(nil))+".json")
+}
+
+// loadResponse returns the cached response of the GET request, nil if there is none.
+func (transport *apiTransport) loadResponse(req *http.Request) *cachedAPIResponse {
+	if transport.cacheDirectory == "" || req.Method != http.MethodGet {
+		return nil
+	}
+	content, err := os.ReadFile(transport.responseCachePath(req))
+	if err != nil {
+		return nil
+	}
+	var cached cachedAPIResponse
+	if err := json.Unmarshal(content, &cached); err != nil || cached.URL != req.URL.String() || (cached.ETag == "" && cached.LastModified == "") {
+		return nil
+	}
+	return &cached
+}
+
+// storeResponse caches the response if it can be revalidated, and returns it with a readable body.
+func (transport *apiTransport) storeResponse(req *http.Request, resp *http.Response) (*http.Response, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	cached := &cachedAPIResponse{
+		URL:          req.URL.String(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		ContentType:  resp.Header.Get("Content-Type"),
+		Body:         body,
+	}
+	if cached.ETag == "" && cached.LastModified == "" {
+		return resp, nil
+	}
+
+	content, err := json.Marshal(cached)
+	if err == nil {
+		err = os.MkdirAll(transport.cacheDirectory, 0700)
+	}
+	if err == nil {
+		err = os.WriteFile(transport.responseCachePath(req), content, 0600)
+	}
+	if err != nil {
+		log.Printf("Caching the response of %s failed: %v", req.URL.Host, err)
+	}
+	return resp, nil
+}
+
+// response returns the cached response as a response to the request.
+func (cached *cachedAPIResponse) response(req *http.Request) *http.Response {
+	header := http.Header{}
+	header.Set("Content-Type", cached.ContentType)
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       req,
+	}
+}
+
+// doJSONRequest sends the request and decodes the JSON response into result (if not nil).
+//
+// Responses with a status code outside of the 2xx range are returned as an error including the response body.
+func doJSONRequest(client *http.Client, req *http.Request, result interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s, response: %s", resp.Status, body)
+	}
+
+	if result != nil {
+		return json.Unmarshal(body, result)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package gogitstats
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// localeFiles holds the message catalogs of the reports, one JSON file per language mapping
+// the English message to its translation. Messages missing in a catalog are shown in English.
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// ReportLanguages returns the languages with a message catalog.
+func ReportLanguages() []string {
+	entries, _ := localeFiles.ReadDir("locales")
+	var languages []string
+	for _, entry := range entries {
+		languages = append(languages, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// loadMessageCatalog reads the message catalog of the given language (e.g., de).
+//
+// Returns:
+//   - The messages by their English text.
+//   - An error if there is no catalog for the language or it is malformed.
+func loadMessageCatalog(language string) (map[string]string, error) {
+	content, err := localeFiles.ReadFile(path.Join("locales", language+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("language '%s' is not supported, expected any of: %s", language, strings.Join(ReportLanguages(), ", "))
+	}
+
+	messages := make(map[string]string)
+	if err := json.Unmarshal(content, &messages); err != nil {
+		return nil, fmt.Errorf("malformed message catalog for language '%s': %w", language, err)
+	}
+	return messages, nil
+}
+
+// translate returns the message in the report language. With arguments, the message is used as format.
+func (run *analysisRun) translate(message string, args ...interface{}) string {
+	if translated, ok := run.messages[message]; ok && translated != "" {
+		message = translated
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
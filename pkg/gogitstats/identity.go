@@ -0,0 +1,316 @@
+package gogitstats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const IDENTITY_PROVIDER_CSV = "csv"
+const IDENTITY_PROVIDER_SCIM = "scim"
+const IDENTITY_PROVIDER_COMMAND = "command"
+
+// UNKNOWN_DEPARTMENT collects contributors, who could not be resolved by the identity provider.
+const UNKNOWN_DEPARTMENT = "Unknown"
+
+const scimEnterpriseSchema = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+
+// Identity is the employee record of a commit email.
+type Identity struct {
+	Email      string `json:"email"`
+	Name       string `json:"name"`
+	Department string `json:"department"`
+	Manager    string `json:"manager"`
+}
+
+// IdentityConfig selects the provider resolving commit emails to employee records.
+type IdentityConfig struct {
+	Provider string   `yaml:"provider"` // csv, scim or command
+	Path     string   `yaml:"path"`     // CSV export with the columns email, name, department and manager
+	URL      string   `yaml:"url"`      // base URL of the SCIM 2.0 API
+	Command  []string `yaml:"command"`  // program and arguments, e.g. a script querying LDAP
+}
+
+// identityProvider resolves commit emails to employee records (e.g., from LDAP, SCIM or HR exports).
+type identityProvider interface {
+	// resolve returns the identities of the given emails, unknown emails are omitted.
+	resolve(emails []string) (map[string]*Identity, error)
+}
+
+type csvIdentityProvider struct {
+	path string
+}
+
+type scimIdentityProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+type commandIdentityProvider struct {
+	command []string
+}
+
+type scimUser struct {
+	DisplayName string `json:"displayName"`
+	Name        struct {
+		Formatted string `json:"formatted"`
+	} `json:"name"`
+	Enterprise struct {
+		Department string `json:"department"`
+		Manager    struct {
+			DisplayName string `json:"displayName"`
+			Value       string `json:"value"`
+		} `json:"manager"`
+	} `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"`
+}
+
+// DepartmentRollup sums the contributions of all contributors of a department across all branches.
+type DepartmentRollup struct {
+	Department   string
+	Managers     []string
+	Contributors []string
+	CommitCount  int
+	LinesEdited  int
+}
+
+// validate checks that the settings required by the provider are set.
+func (config *IdentityConfig) validate() error {
+	switch config.Provider {
+	case IDENTITY_PROVIDER_CSV:
+		if config.Path == "" {
+			return fmt.Errorf("identity provider '%s' requires 'path'", config.Provider)
+		}
+	case IDENTITY_PROVIDER_SCIM:
+		if config.URL == "" {
+			return fmt.Errorf("identity provider '%s' requires 'url'", config.Provider)
+		}
+	case IDENTITY_PROVIDER_COMMAND:
+		if len(config.Command) == 0 {
+			return fmt.Errorf("identity provider '%s' requires 'command'", config.Provider)
+		}
+	default:
+		return fmt.Errorf("unknown identity provider '%s', expected any of: %s, %s, %s", config.Provider, IDENTITY_PROVIDER_CSV, IDENTITY_PROVIDER_SCIM, IDENTITY_PROVIDER_COMMAND)
+	}
+	return nil
+}
+
+// newIdentityProvider creates the configured identity provider.
+//
+// The SCIM token is taken from the environment variable SCIM_TOKEN.
+func newIdentityProvider(config *IdentityConfig) identityProvider {
+	switch config.Provider {
+	case IDENTITY_PROVIDER_SCIM:
+		return &scimIdentityProvider{baseURL: strings.TrimSuffix(config.URL, "/"), client: &http.Client{Timeout: 30 * time.Second}}
+	case IDENTITY_PROVIDER_COMMAND:
+		return &commandIdentityProvider{command: config.Command}
+	default:
+		return &csvIdentityProvider{path: config.Path}
+	}
+}
+
+func (provider *csvIdentityProvider) resolve(emails []string) (map[string]*Identity, error) {
+	file, err := os.Open(provider.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open identity export %s: %w", provider.path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity export %s: %w", provider.path, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, fmt.Errorf("identity export %s has no column 'email'", provider.path)
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	wanted := make(map[string]string, len(emails))
+	for _, email := range emails {
+		wanted[strings.ToLower(email)] = email
+	}
+
+	identities := make(map[string]*Identity)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read identity export %s: %w", provider.path, err)
+		}
+		if email, ok := wanted[strings.ToLower(field(record, "email"))]; ok {
+			identities[email] = &Identity{Email: email, Name: field(record, "name"), Department: field(record, "department"), Manager: field(record, "manager")}
+		}
+	}
+	return identities, nil
+}
+
+func (provider *scimIdentityProvider) resolve(emails []string) (map[string]*Identity, error) {
+	identities := make(map[string]*Identity)
+	for _, email := range emails {
+		query := url.Values{}
+		query.Set("filter", fmt.Sprintf(`emails.value eq "%s"`, strings.ReplaceAll(email, `"`, ``)))
+		req, err := http.NewRequest("GET", provider.baseURL+"/Users?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/scim+json")
+		if token := os.Getenv("SCIM_TOKEN"); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		var result struct {
+			Resources []scimUser `json:"Resources"`
+		}
+		if err := doJSONRequest(provider.client, req, &result); err != nil {
+			return nil, fmt.Errorf("SCIM request for '%s' failed: %w", email, err)
+		}
+		if len(result.Resources) == 0 {
+			continue
+		}
+
+		user := result.Resources[0]
+		identity := &Identity{Email: email, Name: user.DisplayName, Department: user.Enterprise.Department, Manager: user.Enterprise.Manager.DisplayName}
+		if identity.Name == "" {
+			identity.Name = user.Name.Formatted
+		}
+		if identity.Manager == "" {
+			identity.Manager = user.Enterprise.Manager.Value
+		}
+		identities[email] = identity
+	}
+	return identities, nil
+}
+
+// resolve runs the command with the emails on its standard input (one per line). The command prints
+// one JSON object per resolved email, e.g. {"email": "...", "name": "...", "department": "...", "manager": "..."}.
+func (provider *commandIdentityProvider) resolve(emails []string) (map[string]*Identity, error) {
+	cmd := exec.Command(provider.command[0], provider.command[1:]...)
+	cmd.Stdin = strings.NewReader(strings.Join(emails, "\n") + "\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("identity command %s failed: %w, stderr: %s", provider.command[0], err, stderr.String())
+	}
+
+	wanted := make(map[string]string, len(emails))
+	for _, email := range emails {
+		wanted[strings.ToLower(email)] = email
+	}
+
+	identities := make(map[string]*Identity)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		identity := &Identity{}
+		if err := json.Unmarshal([]byte(line), identity); err != nil {
+			return nil, fmt.Errorf("unexpected output of identity command %s: %w", provider.command[0], err)
+		}
+		if email, ok := wanted[strings.ToLower(identity.Email)]; ok {
+			identity.Email = email
+			identities[email] = identity
+		}
+	}
+	return identities, nil
+}
+
+// assessDepartments resolves the contributors of all branches with the identity provider and rolls their
+// contributions up by department. The rollup is attached to the main branch report.
+func (run *analysisRun) assessDepartments(provider identityProvider, branchReports map[string]*BranchReport) {
+	report, ok := branchReports[run.mainBranch]
+	if !ok {
+		return
+	}
+
+	departments, err := rollupDepartments(provider, branchReports)
+	if err != nil {
+		log.Printf("Resolving identities failed: %v", err)
+		report.Departments = nil
+		return
+	}
+	report.Departments = departments
+}
+
+// rollupDepartments sums commits and lines edited by department, contributors who could not be
+// resolved are collected in UNKNOWN_DEPARTMENT.
+//
+// Returns:
+//   - The departments ordered by commit count, descending.
+//   - An error if the identity provider failed.
+func rollupDepartments(provider identityProvider, branchReports map[string]*BranchReport) ([]*DepartmentRollup, error) {
+	emailSet := make(map[string]bool)
+	for _, report := range branchReports {
+		for email := range report.Contributions {
+			emailSet[email] = true
+		}
+	}
+	emails := make([]string, 0, len(emailSet))
+	for email := range emailSet {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+
+	identities, err := provider.resolve(emails)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Resolved %d of %d contributors with the identity provider", len(identities), len(emails))
+
+	byName := make(map[string]*DepartmentRollup)
+	var departments []*DepartmentRollup
+	for _, email := range emails {
+		name, manager := UNKNOWN_DEPARTMENT, ""
+		if identity, ok := identities[email]; ok && identity.Department != "" {
+			name, manager = identity.Department, identity.Manager
+		}
+
+		department, ok := byName[name]
+		if !ok {
+			department = &DepartmentRollup{Department: name}
+			byName[name] = department
+			departments = append(departments, department)
+		}
+		department.Contributors = append(department.Contributors, email)
+		if manager != "" && !slices.Contains(department.Managers, manager) {
+			department.Managers = append(department.Managers, manager)
+		}
+		for _, report := range branchReports {
+			if contribution, ok := report.Contributions[email]; ok {
+				department.CommitCount += contribution.CommitCount
+				department.LinesEdited += contribution.LinesEdited
+			}
+		}
+	}
+
+	sort.SliceStable(departments, func(i, j int) bool {
+		return departments[i].CommitCount > departments[j].CommitCount
+	})
+	return departments, nil
+}
@@ -0,0 +1,61 @@
+package gogitstats
+
+import "regexp"
+
+// IMPORTS_KEEP, IMPORTS_EXCLUDE and IMPORTS_SEPARATE select how detected initial imports are counted, see
+// Options.Imports.
+const IMPORTS_KEEP = "keep"
+const IMPORTS_EXCLUDE = "exclude"
+const IMPORTS_SEPARATE = "separate"
+
+// IMPORTS_AUTHOR is the contributor initial imports are attributed to with IMPORTS_SEPARATE.
+const IMPORTS_AUTHOR = "imports"
+
+// importSubjectPattern matches the subjects of imports of pre-history (e.g., "Imported from SVN") and of
+// vendored code (e.g., "Vendor github.com/pkg/errors").
+var importSubjectPattern = regexp.MustCompile(`(?i)^\s*(imported? (from|of)\b|initial import\b|vendor(ed|ing)?\b|(add(s|ed)?|update[sd]?) vendor(ed)?\b)`)
+
+// ImportCommit is a commit detected as initial import or vendoring, which adds code written elsewhere.
+type ImportCommit struct {
+	Hash       string
+	Email      string
+	Date       string
+	Subject    string
+	FileCount  int
+	LinesAdded int
+}
+
+// isInitialImport reports whether a commit imports code written elsewhere: its subject names an import or
+// vendoring, or it touches at least importMinFiles files and (almost) only adds lines, like huge first
+// commits of a history migrated from another system.
+func (run *analysisRun) isInitialImport(commit CommitRecord) bool {
+	if importSubjectPattern.MatchString(commit.Subject) {
+		return true
+	}
+	if len(commit.Files) < run.importMinFiles {
+		return false
+	}
+
+	added, removed := 0, 0
+	for _, change := range commit.Files {
+		added += change.Added
+		removed += change.Removed
+	}
+	return removed*20 <= added
+}
+
+// addImportCommit records a detected initial import in the branch report.
+func addImportCommit(report *BranchReport, commit CommitRecord) {
+	linesAdded := 0
+	for _, change := range commit.Files {
+		linesAdded += change.Added
+	}
+	report.Imports = append(report.Imports, &ImportCommit{
+		Hash:       commit.Hash,
+		Email:      commit.Email,
+		Date:       commit.Date,
+		Subject:    commit.Subject,
+		FileCount:  len(commit.Files),
+		LinesAdded: linesAdded,
+	})
+}
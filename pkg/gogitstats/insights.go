@@ -0,0 +1,266 @@
+package gogitstats
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxInsightAreas limits the number of focus areas listed in the insights of an author.
+const maxInsightAreas = 5
+
+// InsightArea is an area of the repository (see focusArea) with the number of periods an author worked on it.
+type InsightArea struct {
+	Name    string
+	Periods int
+}
+
+// AuthorInsights summarizes the contributions of an author to a repository on a single page.
+type AuthorInsights struct {
+	Email        string
+	FirstDay     string
+	LastDay      string
+	TenureDays   int
+	ActiveDays   int
+	CommitCount  int
+	LinesAdded   int
+	LinesRemoved int
+	Branches     []string // branches with contributions of the author
+	Roles        map[string]int
+	Areas        []*InsightArea
+	Timeline     map[string]int // Period: commits on all branches
+}
+
+// buildAuthorInsights combines the contributions of each author on all branches with the active days of the author.
+//
+// Parameters:
+//   - branchReports: The branch reports, the reports of other branches than the main branch cover their own commits only.
+//   - activeDays: The commits by email and day, see listActiveDays.
+//
+// Returns:
+//   - The insights of all authors, most commits first.
+func buildAuthorInsights(branchReports map[string]*BranchReport, activeDays map[string]map[string]int) []*AuthorInsights {
+	insights := make(map[string]*AuthorInsights)
+	areaPeriods := make(map[string]map[string]int)
+
+	branchNames := make([]string, 0, len(branchReports))
+	for branchName := range branchReports {
+		branchNames = append(branchNames, branchName)
+	}
+	sort.Strings(branchNames)
+
+	for _, branchName := range branchNames {
+		for email, contribution := range branchReports[branchName].Contributions {
+			author, ok := insights[email]
+			if !ok {
+				author = &AuthorInsights{Email: email, Roles: make(map[string]int), Timeline: make(map[string]int)}
+				insights[email] = author
+				areaPeriods[email] = make(map[string]int)
+			}
+			author.Branches = append(author.Branches, branchName)
+			author.CommitCount += contribution.CommitCount
+			author.LinesAdded += contribution.LinesAdded
+			author.LinesRemoved += contribution.LinesRemoved
+			for role, lines := range contribution.Roles {
+				author.Roles[role] += lines
+			}
+			for period, count := range contribution.ContributionTimeline {
+				author.Timeline[period] += count
+			}
+			for _, areas := range contribution.FocusAreas {
+				for area := range areas {
+					areaPeriods[email][strings.TrimPrefix(area, "component:")]++
+				}
+			}
+		}
+	}
+
+	var sorted []*AuthorInsights
+	for email, author := range insights {
+		days := make([]string, 0, len(activeDays[email]))
+		for day := range activeDays[email] {
+			days = append(days, day)
+		}
+		sort.Strings(days)
+		if len(days) > 0 {
+			author.FirstDay, author.LastDay = days[0], days[len(days)-1]
+			author.ActiveDays = len(days)
+			first, errFirst := time.Parse("2006-01-02", author.FirstDay)
+			last, errLast := time.Parse("2006-01-02", author.LastDay)
+			if errFirst == nil && errLast == nil {
+				author.TenureDays = int(last.Sub(first).Hours()/24) + 1
+			}
+		}
+
+		for area, periods := range areaPeriods[email] {
+			author.Areas = append(author.Areas, &InsightArea{Name: area, Periods: periods})
+		}
+		sort.Slice(author.Areas, func(i, j int) bool {
+			if author.Areas[i].Periods != author.Areas[j].Periods {
+				return author.Areas[i].Periods > author.Areas[j].Periods
+			}
+			return author.Areas[i].Name < author.Areas[j].Name
+		})
+		if len(author.Areas) > maxInsightAreas {
+			author.Areas = author.Areas[:maxInsightAreas]
+		}
+		sorted = append(sorted, author)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].CommitCount != sorted[j].CommitCount {
+			return sorted[i].CommitCount > sorted[j].CommitCount
+		}
+		return sorted[i].Email < sorted[j].Email
+	})
+	return sorted
+}
+
+// writeAuthorInsights writes a one-page HTML document of insights per author into the given directory. The
+// documents are laid out for printing, so they can be saved as PDF from the browser.
+//
+// Returns:
+//   - The number of written documents.
+//   - An error if git failed or a document could not be written.
+func (run *analysisRun) writeAuthorInsights(repoPath string, repoName string, branchReports map[string]*BranchReport, directory string) (int, error) {
+	activeDays, err := run.listActiveDays(repoPath, run.fileFilter)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create directory %s: %w", directory, err)
+	}
+	insights := buildAuthorInsights(branchReports, activeDays)
+	for _, author := range insights {
+		content, err := run.generateHTMLAuthorInsights(author, repoName)
+		if err != nil {
+			return 0, err
+		}
+		documentPath := filepath.Join(directory, sanitizeDirectoryName(run.profile.displayEmail(author.Email))+".html")
+		if err := os.WriteFile(documentPath, []byte(content), 0644); err != nil {
+			return 0, fmt.Errorf("failed to write insights %s: %w", documentPath, err)
+		}
+	}
+	return len(insights), nil
+}
+
+func (run *analysisRun) generateHTMLAuthorInsights(author *AuthorInsights, repoName string) (string, error) {
+	tmpl := `
+<!DOCTYPE html>
+<html lang="{{.Data.Language}}" data-bs-theme="light">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>{{t "Contribution insights: %s" (email .Author.Email)}}</title>
+{{bootstrapStyles}}
+<style>
+	.timeline-chart {
+		max-width: 100%;
+		height: auto;
+	}
+	@page {
+		size: A4;
+		margin: 15mm;
+	}
+	@media print {
+		body {
+			font-size: 10pt;
+		}
+		.container {
+			max-width: 100% !important;
+		}
+	}
+</style>
+</head>
+<body>
+
+<main class="container mt-4">
+{{with .Author}}
+<header class="mb-4">
+<h1 class="h3">{{t "Contribution insights"}}</h1>
+<p class="h5">{{email .Email}} &middot; <span class="badge text-bg-success">{{$.RepoName}}</span></p>
+{{if .FirstDay}}<p>{{t "Contributing since %s, last contribution on %s (%d days, %d of them active)." .FirstDay .LastDay .TenureDays .ActiveDays}}</p>{{end}}
+</header>
+
+<section aria-labelledby="totals" class="mb-4">
+<h2 class="h5" id="totals">{{t "Totals"}}</h2>
+<div class="row row-cols-2 row-cols-md-4 g-3">
+	{{if index $.Data.Columns "commits"}}<div class="col"><div class="border rounded p-2"><div class="fs-4">{{.CommitCount}}</div>{{t "Commits"}}</div></div>{{end}}
+	{{if index $.Data.Columns "added"}}<div class="col"><div class="border rounded p-2"><div class="fs-4">{{.LinesAdded}}</div>{{t "Lines Added"}}</div></div>{{end}}
+	{{if index $.Data.Columns "removed"}}<div class="col"><div class="border rounded p-2"><div class="fs-4">{{.LinesRemoved}}</div>{{t "Lines Removed"}}</div></div>{{end}}
+	<div class="col"><div class="border rounded p-2"><div class="fs-4">{{len .Branches}}</div>{{t "Branches"}}</div></div>
+</div>
+</section>
+
+{{if .Timeline}}
+<section aria-labelledby="trend" class="mb-4">
+<h2 class="h5" id="trend">{{t "Trend"}}</h2>
+<figure class="text-info">{{timelineChart .Timeline $.Periods}}<figcaption class="text-body-secondary small">{{t "Commits per period"}}</figcaption></figure>
+</section>
+{{end}}
+
+{{if and .Areas (not $.Data.HidePaths)}}
+<section aria-labelledby="focus-areas" class="mb-4">
+<h2 class="h5" id="focus-areas">{{t "Focus areas"}}</h2>
+<table class="table table-striped">
+	<thead>
+		<tr>
+			<th scope="col">{{t "Area"}}</th>
+			<th scope="col" class="text-end">{{t "Active periods"}}</th>
+		</tr>
+	</thead>
+	<tbody>
+		{{range .Areas}}
+		<tr>
+			<td>{{.Name}}</td>
+			<td class="text-end">{{.Periods}}</td>
+		</tr>
+		{{end}}
+	</tbody>
+</table>
+</section>
+{{end}}
+
+{{if and $.Data.RoleNames (index $.Data.Columns "roles")}}
+<section aria-labelledby="roles" class="mb-4">
+<h2 class="h5" id="roles">{{t "Roles"}}</h2>
+<p>{{$roles := .Roles}}{{range $role := $.Data.RoleNames}}{{with index $roles $role}}<span class="badge text-bg-secondary me-1">{{$role}}</span>{{end}}{{end}}</p>
+</section>
+{{end}}
+{{end}}
+<footer class="text-body-secondary small">{{t "Generated on %s" .GeneratedOn}}</footer>
+</main>
+</body>
+</html>
+`
+	funcs := run.reportTemplateFuncs()
+	t, err := template.New("insights").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	data := struct {
+		Author      *AuthorInsights
+		RepoName    string
+		Data        ReportData
+		Periods     []string
+		GeneratedOn string
+	}{
+		Author:      author,
+		RepoName:    repoName,
+		Data:        run.newReportData(map[string]*BranchReport{}, repoName, ""),
+		Periods:     run.periodRange(sortedPeriods(author.Timeline)),
+		GeneratedOn: time.Now().Format("2006-01-02"),
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,235 @@
+package gogitstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var jiraKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9_]+-[0-9]+\b`)
+
+type TicketContribution struct {
+	Key          string
+	CommitCount  int
+	LinesEdited  int
+	Contributors map[string]int // Email: commit count
+}
+
+type InitiativeWork struct {
+	Name         string
+	Project      string
+	Tickets      []string
+	CommitCount  int
+	LinesEdited  int
+	Contributors []string
+}
+
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary string `json:"summary"`
+		Project struct {
+			Key  string `json:"key"`
+			Name string `json:"name"`
+		} `json:"project"`
+		IssueType struct {
+			Name string `json:"name"`
+		} `json:"issuetype"`
+		Parent *jiraIssue `json:"parent"`
+	} `json:"fields"`
+	EpicLink string `json:"-"`
+}
+
+type jiraClient struct {
+	baseURL   string
+	epicField string
+	client    *http.Client
+	issues    map[string]*jiraIssue
+	offline   bool // issues are only taken from a dump, see loadJiraDump
+}
+
+// extractTicketKeys returns the distinct Jira issue keys (e.g., PROJ-123) referenced in message.
+func extractTicketKeys(message string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range jiraKeyPattern.FindAllString(message, -1) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func newJiraClient(baseURL string, epicField string) *jiraClient {
+	return &jiraClient{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		epicField: epicField,
+		client:    newAPIClient(apiCacheDirectory()),
+		issues:    make(map[string]*jiraIssue),
+	}
+}
+
+// issue fetches the issue with the given key from Jira. Results (including misses) are kept for the run.
+//
+// The credentials are taken from the environment: JIRA_USER and JIRA_TOKEN for basic authentication
+// or JIRA_TOKEN only for bearer authentication.
+func (jira *jiraClient) issue(key string) (*jiraIssue, error) {
+	if issue, ok := jira.issues[key]; ok {
+		if issue == nil {
+			return nil, fmt.Errorf("issue %s is not available", key)
+		}
+		return issue, nil
+	}
+	if jira.offline {
+		jira.issues[key] = nil
+		return nil, fmt.Errorf("issue %s is not part of the dump", key)
+	}
+
+	fields := "summary,project,issuetype,parent"
+	if jira.epicField != "" {
+		fields += "," + jira.epicField
+	}
+	issueURL := fmt.Sprintf("%s/rest/api/2/issue/%s?fields=%s", jira.baseURL, url.PathEscape(key), url.QueryEscape(fields))
+
+	req, err := http.NewRequest(http.MethodGet, issueURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", issueURL, err)
+	}
+
+	token := os.Getenv("JIRA_TOKEN")
+	if user := os.Getenv("JIRA_USER"); user != "" {
+		req.SetBasicAuth(user, token)
+	} else if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	var body json.RawMessage
+	if err := doJSONRequest(jira.client, req, &body); err != nil {
+		jira.issues[key] = nil
+		return nil, fmt.Errorf("failed to fetch issue %s: %w", key, err)
+	}
+
+	issue, err := jira.decodeIssue(body)
+	if err != nil {
+		jira.issues[key] = nil
+		return nil, fmt.Errorf("failed to decode issue %s: %w", key, err)
+	}
+
+	jira.issues[key] = issue
+	return issue, nil
+}
+
+// decodeIssue decodes an issue as returned by the REST API of Jira, including the configured epic link field.
+func (jira *jiraClient) decodeIssue(body json.RawMessage) (*jiraIssue, error) {
+	var issue jiraIssue
+	if err := json.Unmarshal(body, &issue); err != nil {
+		return nil, err
+	}
+
+	if jira.epicField != "" {
+		var raw struct {
+			Fields map[string]interface{} `json:"fields"`
+		}
+		if err := json.Unmarshal(body, &raw); err == nil {
+			if epicLink, ok := raw.Fields[jira.epicField].(string); ok {
+				issue.EpicLink = epicLink
+			}
+		}
+	}
+
+	return &issue, nil
+}
+
+// initiativeOf determines the initiative (epic) the given issue belongs to.
+//
+// The epic is taken from the parent of the issue (team-managed projects and current Jira Cloud) or from
+// the configured epic link field (company-managed projects). Issues without an epic are grouped by project.
+func (jira *jiraClient) initiativeOf(issue *jiraIssue) (string, string) {
+	project := issue.Fields.Project.Name
+	if project == "" {
+		project = issue.Fields.Project.Key
+	}
+
+	if issue.Fields.IssueType.Name == "Epic" {
+		return fmt.Sprintf("%s: %s", issue.Key, issue.Fields.Summary), project
+	}
+
+	if parent := issue.Fields.Parent; parent != nil {
+		if parent.Fields.IssueType.Name == "Epic" {
+			return fmt.Sprintf("%s: %s", parent.Key, parent.Fields.Summary), project
+		}
+		if parentIssue, err := jira.issue(parent.Key); err == nil {
+			return jira.initiativeOf(parentIssue)
+		}
+	}
+
+	if issue.EpicLink != "" {
+		if epic, err := jira.issue(issue.EpicLink); err == nil {
+			return fmt.Sprintf("%s: %s", epic.Key, epic.Fields.Summary), project
+		}
+		return issue.EpicLink, project
+	}
+
+	return fmt.Sprintf("%s (no epic)", project), project
+}
+
+// groupWorkByInitiative resolves the tickets referenced on a branch with Jira and groups them by initiative.
+//
+// Tickets which can not be resolved are grouped by the project key contained in the ticket key.
+func groupWorkByInitiative(report *BranchReport, jira *jiraClient) []*InitiativeWork {
+	initiatives := make(map[string]*InitiativeWork)
+	contributors := make(map[string]map[string]bool)
+
+	for key, ticket := range report.Tickets {
+		name, project := "", strings.SplitN(key, "-", 2)[0]
+		_, known := jira.issues[key]
+		if issue, err := jira.issue(key); err != nil {
+			if !known {
+				log.Printf("Jira lookup for ticket %s failed: %v", key, err)
+			}
+			name = fmt.Sprintf("%s (not found in Jira)", project)
+		} else {
+			name, project = jira.initiativeOf(issue)
+		}
+
+		initiative, ok := initiatives[name]
+		if !ok {
+			initiative = &InitiativeWork{Name: name, Project: project}
+			initiatives[name] = initiative
+			contributors[name] = make(map[string]bool)
+		}
+		initiative.Tickets = append(initiative.Tickets, key)
+		initiative.CommitCount += ticket.CommitCount
+		initiative.LinesEdited += ticket.LinesEdited
+		for email := range ticket.Contributors {
+			contributors[name][email] = true
+		}
+	}
+
+	result := make([]*InitiativeWork, 0, len(initiatives))
+	for name, initiative := range initiatives {
+		for email := range contributors[name] {
+			initiative.Contributors = append(initiative.Contributors, email)
+		}
+		sort.Strings(initiative.Contributors)
+		sort.Strings(initiative.Tickets)
+		result = append(result, initiative)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].LinesEdited != result[j].LinesEdited {
+			return result[i].LinesEdited > result[j].LinesEdited
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
@@ -0,0 +1,237 @@
+package gogitstats
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxLeaderboardMonths limits the number of months of the leaderboard, the most recent ones are kept.
+const maxLeaderboardMonths = 6
+
+// maxLeaderboardRanks is the number of contributors ranked per month.
+const maxLeaderboardRanks = 3
+
+// STREAK_BADGES are the lengths of streaks (consecutive active days) awarded with a badge.
+var STREAK_BADGES = []int{3, 7, 14, 30}
+
+// LeaderboardEntry is a ranked contributor of a month.
+type LeaderboardEntry struct {
+	Rank    int
+	Email   string
+	Commits int
+}
+
+// LeaderboardMonth ranks the contributors of a month by commits.
+type LeaderboardMonth struct {
+	Month   string // 2006-01
+	Leaders []*LeaderboardEntry
+}
+
+// StreakBadge is awarded to contributors who were active on consecutive days.
+type StreakBadge struct {
+	Email         string
+	Badge         int    // the longest streak reached of STREAK_BADGES
+	LongestStreak int    // days
+	StreakEnd     string // last day of the longest streak
+}
+
+// FirstContribution is the first commit of a contributor to the repository.
+type FirstContribution struct {
+	Email string
+	Date  string
+}
+
+// Leaderboard is an opt-in, gamified view on the contributions meant for community engagement reports.
+type Leaderboard struct {
+	Months    []*LeaderboardMonth
+	Streaks   []*StreakBadge
+	Newcomers []*FirstContribution // first contributions within the months of the leaderboard
+}
+
+// assessLeaderboard ranks the contributors on all local branches, the leaderboard is attached to the main branch report.
+func (run *analysisRun) assessLeaderboard(repoPath string, branchReports map[string]*BranchReport, fileFilter string) {
+	report, ok := branchReports[run.mainBranch]
+	if !ok {
+		return
+	}
+
+	activeDays, err := run.listActiveDays(repoPath, fileFilter)
+	if err != nil {
+		log.Printf("Building the leaderboard failed: %v", err)
+		report.Leaderboard = nil
+		return
+	}
+	firstDays, err := run.listFirstContributions(repoPath, fileFilter)
+	if err != nil {
+		log.Printf("Building the leaderboard failed: %v", err)
+		report.Leaderboard = nil
+		return
+	}
+	report.Leaderboard = buildLeaderboard(activeDays, firstDays)
+}
+
+// buildLeaderboard ranks the contributors per month and awards streak badges.
+//
+// Parameters:
+//   - activeDays: The commits by email and day within the analyzed period, see listActiveDays.
+//   - firstDays: The day of the first commit by email in the whole history, see listFirstContributions.
+//
+// Returns:
+//   - The leaderboard, nil if there are no commits.
+func buildLeaderboard(activeDays map[string]map[string]int, firstDays map[string]string) *Leaderboard {
+	monthly := make(map[string]map[string]int)
+	var streaks []*StreakBadge
+	for email, days := range activeDays {
+		for day, count := range days {
+			month := day[:7]
+			if monthly[month] == nil {
+				monthly[month] = make(map[string]int)
+			}
+			monthly[month][email] += count
+		}
+		if streak := longestStreak(email, days); streak != nil {
+			streaks = append(streaks, streak)
+		}
+	}
+	if len(monthly) == 0 {
+		return nil
+	}
+
+	months := make([]string, 0, len(monthly))
+	for month := range monthly {
+		months = append(months, month)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(months)))
+	if len(months) > maxLeaderboardMonths {
+		months = months[:maxLeaderboardMonths]
+	}
+
+	leaderboard := &Leaderboard{}
+	for _, month := range months {
+		leaders := make([]*LeaderboardEntry, 0, len(monthly[month]))
+		for email, commits := range monthly[month] {
+			leaders = append(leaders, &LeaderboardEntry{Email: email, Commits: commits})
+		}
+		sort.Slice(leaders, func(i, j int) bool {
+			if leaders[i].Commits != leaders[j].Commits {
+				return leaders[i].Commits > leaders[j].Commits
+			}
+			return leaders[i].Email < leaders[j].Email
+		})
+		// contributors with the same number of commits share the rank
+		for i, leader := range leaders {
+			leader.Rank = i + 1
+			if i > 0 && leader.Commits == leaders[i-1].Commits {
+				leader.Rank = leaders[i-1].Rank
+			}
+		}
+		ranked := leaders[:0]
+		for _, leader := range leaders {
+			if leader.Rank <= maxLeaderboardRanks {
+				ranked = append(ranked, leader)
+			}
+		}
+		leaderboard.Months = append(leaderboard.Months, &LeaderboardMonth{Month: month, Leaders: ranked})
+	}
+
+	sort.Slice(streaks, func(i, j int) bool {
+		if streaks[i].LongestStreak != streaks[j].LongestStreak {
+			return streaks[i].LongestStreak > streaks[j].LongestStreak
+		}
+		return streaks[i].Email < streaks[j].Email
+	})
+	leaderboard.Streaks = streaks
+
+	oldestMonth := months[len(months)-1]
+	for email, day := range firstDays {
+		if _, ok := activeDays[email][day]; ok && day[:7] >= oldestMonth {
+			leaderboard.Newcomers = append(leaderboard.Newcomers, &FirstContribution{Email: email, Date: day})
+		}
+	}
+	sort.Slice(leaderboard.Newcomers, func(i, j int) bool {
+		if leaderboard.Newcomers[i].Date != leaderboard.Newcomers[j].Date {
+			return leaderboard.Newcomers[i].Date > leaderboard.Newcomers[j].Date
+		}
+		return leaderboard.Newcomers[i].Email < leaderboard.Newcomers[j].Email
+	})
+	return leaderboard
+}
+
+// longestStreak returns the badge of the longest run of consecutive active days, nil if it is shorter than
+// the first of STREAK_BADGES.
+func longestStreak(email string, days map[string]int) *StreakBadge {
+	var dates []time.Time
+	for day := range days {
+		if date, err := time.Parse("2006-01-02", day); err == nil {
+			dates = append(dates, date)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	longest, current := 0, 0
+	var longestEnd time.Time
+	for i, date := range dates {
+		if i > 0 && date.Sub(dates[i-1]) == 24*time.Hour {
+			current++
+		} else {
+			current = 1
+		}
+		if current > longest {
+			longest, longestEnd = current, date
+		}
+	}
+
+	badge := 0
+	for _, threshold := range STREAK_BADGES {
+		if longest >= threshold {
+			badge = threshold
+		}
+	}
+	if badge == 0 {
+		return nil
+	}
+	return &StreakBadge{Email: email, Badge: badge, LongestStreak: longest, StreakEnd: longestEnd.Format("2006-01-02")}
+}
+
+// listFirstContributions returns the day of the first commit of each author on all local branches, regardless
+// of the analyzed period.
+func (run *analysisRun) listFirstContributions(repoPath string, fileFilter string) (map[string]string, error) {
+	args := []string{"log", "--branches", "--format=%H%x1f%ae%x1f%ad", "--date=short"}
+	args = append(args, pathspecArgs(fileFilter)...)
+	cmd := gitCommand(repoPath, args...)
+	output, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git log output: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start git log: %w", err)
+	}
+
+	firstDays := make(map[string]string)
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\x1f")
+		if len(fields) != 3 || run.config.excludesCommit(fields[0]) {
+			continue
+		}
+		email := run.config.canonicalEmail(fields[1])
+		if !run.authorSelected(email) {
+			continue
+		}
+		if first, ok := firstDays[email]; !ok || fields[2] < first {
+			firstDays[email] = fields[2]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		cmd.Wait()
+		return nil, fmt.Errorf("failed to read git log output: %w", err)
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	return firstDays, nil
+}
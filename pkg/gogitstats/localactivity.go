@@ -0,0 +1,263 @@
+package gogitstats
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxAbandonedBranches limits the number of abandoned branches listed.
+const maxAbandonedBranches = 20
+
+// LocalActivityPeriod counts the reflog entries of HEAD in a period by kind.
+type LocalActivityPeriod struct {
+	Period      string
+	Commits     int // new commits, including merges and cherry-picks
+	Amends      int
+	Rebases     int // finished rebases
+	Resets      int
+	CherryPicks int
+	Checkouts   int // switches between branches or commits
+}
+
+// UnpublishedBranch is a local branch with commits not contained in any remote-tracking branch.
+type UnpublishedBranch struct {
+	Name    string
+	Commits int
+}
+
+// AbandonedBranch is a branch which has been worked on locally, was deleted since and left commits not
+// reachable from any remaining ref.
+type AbandonedBranch struct {
+	Name     string
+	Tip      string
+	Commits  int
+	LeftDate string // date of the last checkout away from the branch
+}
+
+// LocalActivity describes work done in the local clone as recorded in its reflog, including work which
+// never reached the remote (amended or rebased commits, unpublished and abandoned branches).
+type LocalActivity struct {
+	Total       *LocalActivityPeriod
+	Periods     []*LocalActivityPeriod
+	Unpublished []*UnpublishedBranch
+	Abandoned   []*AbandonedBranch
+}
+
+// reflogEntry is an entry of the reflog of HEAD.
+type reflogEntry struct {
+	Hash    string
+	Time    time.Time
+	Subject string // e.g., "commit (amend): Fix typo" or "checkout: moving from main to feature"
+}
+
+// assessLocalActivity reports the local work recorded in the reflog, the report is attached to the main branch report.
+func (run *analysisRun) assessLocalActivity(repoPath string, branchReports map[string]*BranchReport) {
+	report, ok := branchReports[run.mainBranch]
+	if !ok {
+		return
+	}
+
+	activity, err := run.readLocalActivity(repoPath)
+	if err != nil {
+		log.Printf("Reading the local activity failed: %v", err)
+		report.LocalActivity = nil
+		return
+	}
+	report.LocalActivity = activity
+}
+
+// readLocalActivity reads the reflog of HEAD within the analyzed period and the local branches of the repository.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//
+// Returns:
+//   - The local activity, nil if the reflog holds no entries in the period (e.g., of a fresh clone).
+//   - An error if git failed.
+func (run *analysisRun) readLocalActivity(repoPath string) (*LocalActivity, error) {
+	entries, err := readHeadReflog(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	since, until := run.dateRangeLimits()
+	activity := &LocalActivity{Total: &LocalActivityPeriod{}}
+	periods := make(map[string]*LocalActivityPeriod)
+	for i, entry := range entries {
+		if entry.Time.Unix() < since || entry.Time.Unix() > until {
+			continue
+		}
+		period, ok := run.timelinePeriod(entry.Time.Format("2006-01-02"))
+		if !ok {
+			continue
+		}
+		periodActivity, ok := periods[period]
+		if !ok {
+			periodActivity = &LocalActivityPeriod{Period: period}
+			periods[period] = periodActivity
+		}
+		countReflogEntry(periodActivity, entry.Subject)
+		countReflogEntry(activity.Total, entry.Subject)
+
+		// the previous (older) entry holds the tip of the branch checked out before
+		if i+1 < len(entries) {
+			if abandoned := abandonedBranch(repoPath, entry, entries[i+1].Hash); abandoned != nil {
+				activity.Abandoned = appendAbandonedBranch(activity.Abandoned, abandoned)
+			}
+		}
+	}
+	if len(periods) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(periods))
+	for period := range periods {
+		keys = append(keys, period)
+	}
+	for _, period := range sortPeriods(keys) {
+		activity.Periods = append(activity.Periods, periods[period])
+	}
+	if len(activity.Abandoned) > maxAbandonedBranches {
+		activity.Abandoned = activity.Abandoned[:maxAbandonedBranches]
+	}
+
+	activity.Unpublished, err = listUnpublishedBranches(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+// readHeadReflog returns the entries of the reflog of HEAD, newest first.
+func readHeadReflog(repoPath string) ([]reflogEntry, error) {
+	cmd := gitCommand(repoPath, "log", "--walk-reflogs", "--date=unix", "--format=%H%x1f%gd%x1f%gs", "HEAD", "--")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// a repository without commits has no reflog of HEAD
+		if strings.Contains(string(output), "does not have any commits") || strings.Contains(string(output), "unknown revision") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("git log --walk-reflogs failed: %w, output: %s", err, output)
+	}
+
+	var entries []reflogEntry
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.SplitN(line, "\x1f", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		// the selector of the entry holds its time with --date=unix, e.g. "HEAD@{1704067200}"
+		selector := parts[1]
+		start, end := strings.Index(selector, "@{"), strings.LastIndex(selector, "}")
+		if start < 0 || end < start {
+			continue
+		}
+		seconds, err := strconv.ParseInt(selector[start+2:end], 10, 64)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, reflogEntry{Hash: parts[0], Time: time.Unix(seconds, 0), Subject: parts[2]})
+	}
+	return entries, nil
+}
+
+// countReflogEntry counts the entry by the action recorded in its subject (e.g., "commit (amend): ...").
+func countReflogEntry(period *LocalActivityPeriod, subject string) {
+	action, _, _ := strings.Cut(subject, ":")
+	switch {
+	case action == "commit (amend)":
+		period.Amends++
+	case strings.HasPrefix(action, "commit"), strings.HasPrefix(action, "merge"):
+		period.Commits++
+	case strings.HasPrefix(action, "cherry-pick"):
+		period.Commits++
+		period.CherryPicks++
+	case strings.HasPrefix(action, "rebase") && strings.Contains(action, "(finish)"):
+		period.Rebases++
+	case strings.HasPrefix(action, "reset"):
+		period.Resets++
+	case strings.HasPrefix(action, "checkout"):
+		period.Checkouts++
+	}
+}
+
+// abandonedBranch returns the branch left by a checkout entry, if it does not exist anymore and its
+// last commits are not reachable from any ref.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - entry: The reflog entry, only checkouts are considered.
+//   - tip: The commit checked out before the entry, i.e. the tip of the branch when it was left.
+func abandonedBranch(repoPath string, entry reflogEntry, tip string) *AbandonedBranch {
+	// e.g., "checkout: moving from feature/login to main"
+	moving, ok := strings.CutPrefix(entry.Subject, "checkout: moving from ")
+	if !ok {
+		return nil
+	}
+	name, _, ok := strings.Cut(moving, " to ")
+	if !ok || name == tip || strings.HasPrefix(tip, name) {
+		return nil // detached HEAD
+	}
+
+	if err := gitCommand(repoPath, "rev-parse", "--verify", "--quiet", "refs/heads/"+name).Run(); err == nil {
+		return nil
+	}
+
+	output, err := gitCommand(repoPath, "rev-list", "--count", tip, "--not", "--all").Output()
+	if err != nil {
+		return nil // the commits have been pruned
+	}
+	commits, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil || commits == 0 {
+		return nil
+	}
+
+	return &AbandonedBranch{Name: name, Tip: tip, Commits: commits, LeftDate: entry.Time.Format("2006-01-02")}
+}
+
+// appendAbandonedBranch adds the branch unless a later visit of the same branch has been recorded already.
+func appendAbandonedBranch(branches []*AbandonedBranch, branch *AbandonedBranch) []*AbandonedBranch {
+	for _, known := range branches {
+		if known.Name == branch.Name {
+			return branches
+		}
+	}
+	return append(branches, branch)
+}
+
+// listUnpublishedBranches lists the local branches with commits not contained in any remote-tracking branch.
+func listUnpublishedBranches(repoPath string) ([]*UnpublishedBranch, error) {
+	output, err := gitCommand(repoPath, "for-each-ref", "--format=%(refname:short)", "refs/heads").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref failed: %w", err)
+	}
+
+	var branches []*UnpublishedBranch
+	for _, name := range strings.Fields(string(output)) {
+		countOutput, err := gitCommand(repoPath, "rev-list", "--count", "refs/heads/"+name, "--not", "--remotes").Output()
+		if err != nil {
+			return nil, fmt.Errorf("git rev-list for '%s' failed: %w", name, err)
+		}
+		if commits, err := strconv.Atoi(strings.TrimSpace(string(countOutput))); err == nil && commits > 0 {
+			branches = append(branches, &UnpublishedBranch{Name: name, Commits: commits})
+		}
+	}
+	return branches, nil
+}
+
+// dateRangeLimits returns the analyzed period (see gitDateRange) as Unix timestamps, for data which is not
+// read with `git log` date limits (e.g., the reflog).
+func (run *analysisRun) dateRangeLimits() (int64, int64) {
+	since, until := int64(0), int64(1<<62)
+	for _, arg := range run.gitDateRange {
+		if value, ok := strings.CutPrefix(arg, "--max-age="); ok {
+			since, _ = strconv.ParseInt(value, 10, 64)
+		} else if value, ok := strings.CutPrefix(arg, "--min-age="); ok {
+			until, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	return since, until
+}
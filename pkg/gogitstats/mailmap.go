@@ -0,0 +1,89 @@
+package gogitstats
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// MAILMAP_FILE is the mailmap of git shipped inside an analyzed repository, see gitmailmap(5).
+const MAILMAP_FILE = ".mailmap"
+
+// mailmapEmailPattern matches the emails of a mailmap entry, e.g. "Jane <jane@example.com> <jane@private.org>".
+var mailmapEmailPattern = regexp.MustCompile(`<([^<>]*)>`)
+
+// loadMailmap reads the email mappings of the mailmap file located at mailmapPath.
+//
+// Entries mapping names only are skipped, since contributions are reported by email. Entries naming the
+// commit name (e.g., "Jane <jane@example.com> jane <jane@private.org>") map the email regardless of the name.
+//
+// Returns:
+//   - The mappings from lower case commit emails to proper emails.
+//   - An error if the file could not be read.
+func loadMailmap(mailmapPath string) (map[string]string, error) {
+	content, err := os.ReadFile(mailmapPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mailmap file %s: %w", mailmapPath, err)
+	}
+	return parseMailmap(content), nil
+}
+
+// parseMailmap parses the email mappings of a mailmap, see loadMailmap.
+func parseMailmap(content []byte) map[string]string {
+	mailmap := make(map[string]string)
+	for _, line := range strings.Split(string(content), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		emails := mailmapEmailPattern.FindAllStringSubmatch(line, -1)
+		if len(emails) != 2 {
+			continue
+		}
+		proper, commit := strings.TrimSpace(emails[0][1]), strings.TrimSpace(emails[1][1])
+		if proper == "" || commit == "" {
+			continue
+		}
+		mailmap[strings.ToLower(commit)] = proper
+	}
+	return mailmap
+}
+
+// loadRepositoryMailmap reads the mailmap shipped in the working tree of the repository located at repoPath,
+// or in the commit HEAD points to if the repository is bare.
+//
+// Returns:
+//   - The mappings, nil if the repository has no mailmap.
+//   - An error if the file could not be read.
+func loadRepositoryMailmap(repoPath string) (map[string]string, error) {
+	if isBareRepository(repoPath) {
+		content, err := readHeadFile(repoPath, MAILMAP_FILE)
+		if content == nil || err != nil {
+			return nil, err
+		}
+		return parseMailmap(content), nil
+	}
+
+	mailmapPath := filepath.Join(repoPath, MAILMAP_FILE)
+	if _, err := os.Stat(mailmapPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return loadMailmap(mailmapPath)
+}
+
+// withMailmap returns a copy of the configuration, whose aliases are extended by the mailmap.
+// Configured aliases take precedence.
+func (config *Config) withMailmap(mailmap map[string]string) *Config {
+	merged := *config
+	if len(mailmap) == 0 {
+		return &merged
+	}
+
+	merged.Aliases = make(map[string]string, len(mailmap)+len(config.Aliases))
+	for email, proper := range mailmap {
+		merged.Aliases[email] = proper
+	}
+	for alias, email := range config.Aliases {
+		merged.Aliases[alias] = email
+	}
+	return &merged
+}
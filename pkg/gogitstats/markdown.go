@@ -0,0 +1,369 @@
+package gogitstats
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SPARKLINE_BLOCKS are the bars of the timelines in Markdown reports, from no commits to the busiest period.
+var SPARKLINE_BLOCKS = []rune("·▁▂▃▄▅▆▇█")
+
+// markdownTableEscaper escapes the characters breaking the cells of GitHub-flavored Markdown tables.
+var markdownTableEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "\r", "")
+
+// reportFileExtension returns the extension of the report files of a format, see REPORT_FORMATS.
+func reportFileExtension(format string) string {
+	if format == REPORT_FORMAT_MARKDOWN {
+		return "md"
+	}
+	return format
+}
+
+// encodeMarkdownReport renders the contribution tables of the report data as GitHub-flavored Markdown, one table
+// per branch (the main branch first), so the report can be pasted into wikis, pull requests or release notes.
+func (run *analysisRun) encodeMarkdownReport(data ReportData) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "# %s\n\n", run.translate("Git Contribution Report: %s", data.RepoName))
+	if data.FileFilter != "" && !data.HidePaths {
+		fmt.Fprintf(&buf, "%s `%s`\n\n", run.translate("Applied file filter:"), data.FileFilter)
+	}
+	run.writeMarkdownPeriod(&buf, data)
+
+	if totals := data.Totals; totals != nil && data.Sections["totals"] {
+		fmt.Fprintf(&buf, "## %s\n\n", run.translate("Repository totals"))
+		fmt.Fprintf(&buf, "%s\n\n", run.translate("Contributions to the %d analyzed branches with each commit counted once: %d commits, while the branch tables count %d commits.", totals.Branches, totals.CommitCount, totals.BranchCommits))
+		run.writeMarkdownContributions(&buf, data, &BranchReport{Contributions: totals.Contributions})
+	}
+
+	if protected := data.Protected; protected != nil && data.Sections["protected-paths"] {
+		fmt.Fprintf(&buf, "## %s\n\n", run.translate("Protected path changes"))
+		fmt.Fprintf(&buf, "%s\n\n", run.translate("%d commits by %d authors changed protected paths.", len(protected.Changes), protected.Authors))
+		run.writeMarkdownProtectedChanges(&buf, data, protected)
+	}
+
+	if len(data.Teams) > 0 && data.Sections["teams"] {
+		fmt.Fprintf(&buf, "## %s\n\n", run.translate("Contributions by team"))
+		run.writeMarkdownTeams(&buf, data)
+	}
+
+	branchNames := make([]string, 0, len(data.BranchReports))
+	for branchName := range data.BranchReports {
+		branchNames = append(branchNames, branchName)
+	}
+	slices.SortFunc(branchNames, func(a, b string) int {
+		if (a == data.MainBranch) != (b == data.MainBranch) {
+			if a == data.MainBranch {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+
+	for _, branchName := range branchNames {
+		report := data.BranchReports[branchName]
+		fmt.Fprintf(&buf, "## %s %s\n\n", run.translate("Branch:"), markdownCell(branchName))
+		if report.Parsing.LogError != "" {
+			fmt.Fprintf(&buf, "> %s\n\n", run.translate("git log failed, only the commits read until then are counted: %s", report.Parsing.LogError))
+		}
+		if report.Parsing.SkippedCommits > 0 || report.Parsing.SkippedLines > 0 {
+			fmt.Fprintf(&buf, "> %s\n\n", run.translate("Incomplete statistics: %d commits and %d lines of git log output could not be parsed and were skipped.", report.Parsing.SkippedCommits, report.Parsing.SkippedLines))
+		}
+		run.writeMarkdownContributions(&buf, data, report)
+	}
+	return buf.String()
+}
+
+// writeMarkdownContributions writes the contribution table of a branch with the selected columns.
+func (run *analysisRun) writeMarkdownContributions(buf *strings.Builder, data ReportData, report *BranchReport) {
+	columns := data.Columns
+	periods := run.branchPeriods(report)
+
+	var header []string
+	var numeric []bool
+	addColumn := func(title string, isNumeric bool) {
+		header = append(header, title)
+		numeric = append(numeric, isNumeric)
+	}
+	if columns["email"] {
+		addColumn(run.translate("Email"), false)
+	}
+	if columns["commits"] {
+		addColumn(run.translate("Commit Count"), true)
+	}
+	if columns["timeline"] && len(periods) > 0 {
+		addColumn(fmt.Sprintf("%s (%s – %s)", run.translate("Contribution Timeline"), periods[0], periods[len(periods)-1]), false)
+	}
+	if columns["added"] {
+		addColumn(run.translate("Lines Added"), true)
+	}
+	if columns["removed"] {
+		addColumn(run.translate("Lines Removed"), true)
+	}
+	if columns["edited"] {
+		addColumn(run.translate("Lines Edited"), true)
+	}
+	if columns["files-per-commit"] {
+		addColumn(run.translate("Files per Commit"), true)
+	}
+	if columns["distinct-files"] {
+		addColumn(run.translate("Distinct Files"), true)
+	}
+	if columns["filter"] && !data.HidePaths {
+		addColumn(run.translate("File Filter"), false)
+	}
+	if columns["roles"] && len(data.RoleNames) > 0 {
+		addColumn(run.translate("Roles"), false)
+	}
+	if columns["without-ticket"] && data.TicketPolicy != nil {
+		addColumn(run.translate("Commits Without Ticket"), true)
+	}
+
+	var rows [][]string
+	for _, contribution := range run.topContributions(report.Contributions) {
+		var row []string
+		if columns["email"] {
+			if contribution.Others > 0 {
+				row = append(row, markdownCell(run.translate("%d others", contribution.Others)))
+			} else {
+				row = append(row, markdownCell(run.profile.displayEmail(contribution.Email)))
+			}
+		}
+		if columns["commits"] {
+			row = append(row, strconv.Itoa(contribution.CommitCount))
+		}
+		if columns["timeline"] && len(periods) > 0 {
+			row = append(row, "`"+textSparkline(contribution.ContributionTimeline, periods)+"`")
+		}
+		if columns["added"] {
+			row = append(row, strconv.Itoa(contribution.LinesAdded))
+		}
+		if columns["removed"] {
+			row = append(row, strconv.Itoa(contribution.LinesRemoved))
+		}
+		if columns["edited"] {
+			row = append(row, strconv.Itoa(contribution.LinesEdited))
+		}
+		if columns["files-per-commit"] {
+			row = append(row, filesPerCommit(contribution))
+		}
+		if columns["distinct-files"] {
+			row = append(row, strconv.Itoa(distinctFiles(contribution)))
+		}
+		if columns["filter"] && !data.HidePaths {
+			row = append(row, markdownCell(contribution.FileFilter))
+		}
+		if columns["roles"] && len(data.RoleNames) > 0 {
+			var roles []string
+			for _, role := range data.RoleNames {
+				if lines := contribution.Roles[role]; lines > 0 {
+					roles = append(roles, fmt.Sprintf("%s: %d%%", role, lines*100/max(contribution.LinesEdited, 1)))
+				}
+			}
+			row = append(row, markdownCell(strings.Join(roles, ", ")))
+		}
+		if columns["without-ticket"] && data.TicketPolicy != nil {
+			row = append(row, strconv.Itoa(contribution.CommitsWithoutTicket))
+		}
+		rows = append(rows, row)
+	}
+	writeMarkdownTable(buf, header, numeric, rows)
+}
+
+// writeMarkdownProtectedChanges writes the audit trail of the protected paths as a Markdown table, commits are
+// linked if their URL is known.
+func (run *analysisRun) writeMarkdownProtectedChanges(buf *strings.Builder, data ReportData, protected *ProtectedPathReport) {
+	if len(protected.Changes) == 0 {
+		return
+	}
+	header := []string{run.translate("Date"), run.translate("Commit"), run.translate("Email"), run.translate("Subject")}
+	numeric := []bool{false, false, false, false}
+	if !data.HidePaths {
+		header = append(header, run.translate("Paths"))
+		numeric = append(numeric, false)
+	}
+
+	var rows [][]string
+	for _, change := range protected.Changes {
+		commit := "`" + change.Hash[:min(len(change.Hash), 10)] + "`"
+		if change.URL != "" {
+			commit = "[" + commit + "](" + change.URL + ")"
+		}
+		row := []string{change.Date, commit, markdownCell(run.profile.displayEmail(change.Email)), markdownCell(change.Subject)}
+		if !data.HidePaths {
+			row = append(row, markdownCell(strings.Join(change.Paths, ", ")))
+		}
+		rows = append(rows, row)
+	}
+	writeMarkdownTable(buf, header, numeric, rows)
+}
+
+// writeMarkdownTeams writes the team roll-up as a Markdown table, the timeline as a text sparkline.
+func (run *analysisRun) writeMarkdownTeams(buf *strings.Builder, data ReportData) {
+	periods := teamPeriods(data.Teams)
+	showTimeline := data.Columns["timeline"] && len(periods) > 0
+
+	header := []string{run.translate("Team"), run.translate("Commit Count")}
+	numeric := []bool{false, true}
+	if showTimeline {
+		header = append(header, fmt.Sprintf("%s (%s – %s)", run.translate("Contribution Timeline"), periods[0], periods[len(periods)-1]))
+		numeric = append(numeric, false)
+	}
+	if data.ShowLines {
+		header = append(header, run.translate("Lines Added"), run.translate("Lines Removed"), run.translate("Lines Edited"))
+		numeric = append(numeric, true, true, true)
+	}
+	header = append(header, run.translate("Contributors"))
+	numeric = append(numeric, true)
+
+	var rows [][]string
+	for _, team := range data.Teams {
+		name := team.Team
+		if name == UNASSIGNED_TEAM {
+			name = run.translate(UNASSIGNED_TEAM)
+		}
+		row := []string{markdownCell(name), strconv.Itoa(team.CommitCount)}
+		if showTimeline {
+			row = append(row, "`"+textSparkline(team.Timeline, periods)+"`")
+		}
+		if data.ShowLines {
+			row = append(row, strconv.Itoa(team.LinesAdded), strconv.Itoa(team.LinesRemoved), strconv.Itoa(team.LinesEdited))
+		}
+		row = append(row, strconv.Itoa(len(team.Contributors)))
+		rows = append(rows, row)
+	}
+	writeMarkdownTable(buf, header, numeric, rows)
+}
+
+// generateMarkdownCombinedReport renders the combined report as GitHub-flavored Markdown: the repositories and the
+// contributions of each author across them.
+func (run *analysisRun) generateMarkdownCombinedReport(combined *CombinedReport) string {
+	data := run.newReportData(map[string]*BranchReport{}, "", "")
+	columns := data.Columns
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "# %s\n\n", run.translate("Combined report of %d repositories", len(combined.Repositories)))
+	run.writeMarkdownPeriod(&buf, data)
+	fmt.Fprintf(&buf, "%s\n\n", run.translate("Contributions to the main branch of each repository."))
+
+	fmt.Fprintf(&buf, "## %s\n\n", run.translate("Repositories"))
+	header := []string{run.translate("Repository"), run.translate("Main Branch"), run.translate("Branches"), run.translate("Contributors"), run.translate("Commits")}
+	numeric := []bool{false, false, true, true, true}
+	if data.ShowLines {
+		header = append(header, run.translate("Lines Edited"))
+		numeric = append(numeric, true)
+	}
+	var rows [][]string
+	for _, repository := range combined.Repositories {
+		row := []string{markdownCell(repository.Name), markdownCell(repository.MainBranch), strconv.Itoa(repository.BranchCount),
+			strconv.Itoa(len(repository.Contributions)), strconv.Itoa(repository.CommitCount)}
+		if data.ShowLines {
+			row = append(row, strconv.Itoa(repository.LinesEdited))
+		}
+		rows = append(rows, row)
+	}
+	writeMarkdownTable(&buf, header, numeric, rows)
+
+	fmt.Fprintf(&buf, "## %s\n\n", run.translate("Contributions across repositories"))
+	header, numeric, rows = nil, nil, nil
+	for _, column := range []struct {
+		key   string
+		title string
+	}{{"email", "Email"}, {"commits", "Commit Count"}, {"added", "Lines Added"}, {"removed", "Lines Removed"}, {"edited", "Lines Edited"}} {
+		if columns[column.key] {
+			header = append(header, run.translate(column.title))
+			numeric = append(numeric, column.key != "email")
+		}
+	}
+	header = append(header, run.translate("Commits by repository"))
+	numeric = append(numeric, false)
+	repositoryNames := combined.repositoryNames()
+	for _, contributor := range combined.Contributors {
+		var row []string
+		if columns["email"] {
+			row = append(row, markdownCell(run.profile.displayEmail(contributor.Email)))
+		}
+		for _, column := range []struct {
+			key   string
+			value int
+		}{{"commits", contributor.CommitCount}, {"added", contributor.LinesAdded}, {"removed", contributor.LinesRemoved}, {"edited", contributor.LinesEdited}} {
+			if columns[column.key] {
+				row = append(row, strconv.Itoa(column.value))
+			}
+		}
+		var repositories []string
+		for _, name := range repositoryNames {
+			if count := contributor.Repositories[name]; count > 0 {
+				repositories = append(repositories, fmt.Sprintf("%s: %d", name, count))
+			}
+		}
+		rows = append(rows, append(row, markdownCell(strings.Join(repositories, ", "))))
+	}
+	writeMarkdownTable(&buf, header, numeric, rows)
+	return buf.String()
+}
+
+// writeMarkdownPeriod writes the analyzed period and revision range, if they are limited.
+func (run *analysisRun) writeMarkdownPeriod(buf *strings.Builder, data ReportData) {
+	if data.RevRange != "" {
+		fmt.Fprintf(buf, "%s `%s`\n\n", run.translate("Revision range:"), data.RevRange)
+	}
+	if data.Since == "" && data.Until == "" {
+		return
+	}
+	var period []string
+	if data.Since != "" {
+		period = append(period, run.translate("since")+" "+data.Since)
+	}
+	if data.Until != "" {
+		period = append(period, run.translate("until")+" "+data.Until)
+	}
+	fmt.Fprintf(buf, "%s %s\n\n", run.translate("Period:"), strings.Join(period, " "))
+}
+
+// writeMarkdownTable writes a table with the given header and rows, numeric columns are right-aligned.
+func writeMarkdownTable(buf *strings.Builder, header []string, numeric []bool, rows [][]string) {
+	if len(header) == 0 {
+		return
+	}
+	alignment := make([]string, len(header))
+	for i := range header {
+		alignment[i] = "---"
+		if numeric[i] {
+			alignment[i] = "---:"
+		}
+	}
+
+	fmt.Fprintf(buf, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(buf, "| %s |\n", strings.Join(alignment, " | "))
+	for _, row := range rows {
+		fmt.Fprintf(buf, "| %s |\n", strings.Join(row, " | "))
+	}
+	buf.WriteString("\n")
+}
+
+// textSparkline renders the commits of a timeline per period as a row of block characters, scaled to the busiest
+// period of the timeline.
+func textSparkline(timeline map[string]int, periods []string) string {
+	maxCount := 0
+	for _, period := range periods {
+		maxCount = max(maxCount, timeline[period])
+	}
+
+	bars := make([]rune, len(periods))
+	for i, period := range periods {
+		level := 0
+		if count := timeline[period]; count > 0 {
+			level = (count*(len(SPARKLINE_BLOCKS)-1) + maxCount - 1) / maxCount
+		}
+		bars[i] = SPARKLINE_BLOCKS[level]
+	}
+	return string(bars)
+}
+
+// markdownCell escapes a value for a cell of a Markdown table.
+func markdownCell(value string) string {
+	return markdownTableEscaper.Replace(value)
+}
@@ -0,0 +1,72 @@
+package gogitstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// exportedDataset holds the fields telling the JSON exports apart: reports of a repository (see ReportData)
+// and combined reports (see CombinedReport).
+type exportedDataset struct {
+	RepoName     string
+	Repositories []*RepositorySummary
+	StartedOn    time.Time
+}
+
+// Merge combines reports exported with the JSON format (e.g., produced by CI runners analyzing different
+// repositories) into a combined report, like the reports of a run analyzing all repositories (see Combine).
+//
+// Exports of a repository and combined exports (e.g., of earlier merges) can be merged, a repository must not be
+// part of several exports.
+//
+// Parameters:
+//   - dataPaths: The paths of the exported JSON reports.
+//
+// Returns:
+//   - The combined report.
+//   - An error if an export could not be read or a repository is part of several exports.
+func (analyzer *Analyzer) Merge(dataPaths []string) (*CombinedReport, error) {
+	startedOn := time.Now()
+	var summaries []*RepositorySummary
+	sources := make(map[string]string)
+
+	for _, dataPath := range dataPaths {
+		content, err := os.ReadFile(dataPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report data %s: %w", dataPath, err)
+		}
+		var dataset exportedDataset
+		if err := json.Unmarshal(content, &dataset); err != nil {
+			return nil, fmt.Errorf("failed to decode report data %s: %w", dataPath, err)
+		}
+
+		var merged []*RepositorySummary
+		switch {
+		case dataset.Repositories != nil:
+			merged = dataset.Repositories
+			if !dataset.StartedOn.IsZero() && dataset.StartedOn.Before(startedOn) {
+				startedOn = dataset.StartedOn
+			}
+		case dataset.RepoName != "":
+			report, err := analyzer.LoadReport(dataPath)
+			if err != nil {
+				return nil, err
+			}
+			merged = []*RepositorySummary{summarizeRepository(report)}
+		default:
+			return nil, fmt.Errorf("report data %s is not a JSON report exported by gogitstats", dataPath)
+		}
+
+		for _, summary := range merged {
+			if source, ok := sources[summary.Name]; ok {
+				return nil, fmt.Errorf("repository '%s' is part of %s and %s", summary.Name, source, dataPath)
+			}
+			sources[summary.Name] = dataPath
+			summaries = append(summaries, summary)
+		}
+	}
+
+	return analyzer.combineSummaries(summaries, startedOn), nil
+}
@@ -0,0 +1,153 @@
+package gogitstats
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MIRRORS_DIRECTORY is the default directory of the mirror farm, see AddMirror.
+const MIRRORS_DIRECTORY = "mirrors"
+
+// Mirror is a bare `git clone --mirror` of a remote repository in the directory of a mirror farm.
+type Mirror struct {
+	Path string
+	URL  string
+}
+
+// mirrorName names the mirror of a repository like the mirrors found by `--reference`, e.g. "api.git" for
+// https://example.com/group/api.git.
+func mirrorName(repoURL string) string {
+	return sanitizeDirectoryName(urlRepositoryName(repoURL) + ".git")
+}
+
+// AddMirror adds a mirror of a remote repository to the mirror farm. The mirrors of the farm are analyzed
+// with `--discover` (e.g., by scheduled org-wide runs) and can serve as `--reference` of clones.
+//
+// Parameters:
+//   - farm: The directory of the mirror farm, created if needed.
+//   - repoURL: The URL of the mirrored repository.
+//
+// Returns:
+//   - The local path of the mirror, an existing mirror of the repository is kept as it is.
+//   - An error if the repository could not be cloned or another repository is mirrored under the same name.
+func AddMirror(farm string, repoURL string) (string, error) {
+	if err := os.MkdirAll(farm, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", farm, err)
+	}
+
+	mirrorPath := filepath.Join(farm, mirrorName(repoURL))
+	if _, err := os.Stat(mirrorPath); err == nil {
+		mirroredURL := originURL(mirrorPath)
+		if mirroredURL != repoURL {
+			return "", fmt.Errorf("mirror %s already exists for another repository: %s", mirrorPath, mirroredURL)
+		}
+		log.Printf("Mirror already exists at: %s", mirrorPath)
+		return mirrorPath, nil
+	}
+
+	output, err := gitRemoteCommand("", repoURL, "clone", "--mirror", repoURL, mirrorPath).CombinedOutput()
+	if err != nil {
+		return "", remoteCommandError("mirror repository", err, output)
+	}
+	log.Printf("Repository mirrored to: %s", mirrorPath)
+	return mirrorPath, nil
+}
+
+// ListMirrors lists the mirrors in the directory of the mirror farm, i.e. the bare repositories with a remote
+// named origin. Other directories (e.g., of failed clones) are skipped.
+func ListMirrors(farm string) ([]Mirror, error) {
+	entries, err := os.ReadDir(farm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mirror directory %s: %w", farm, err)
+	}
+
+	var mirrors []Mirror
+	for _, entry := range entries {
+		mirrorPath := filepath.Join(farm, entry.Name())
+		if !entry.IsDir() || !isBareRepositoryDirectory(mirrorPath) {
+			continue
+		}
+		repoURL := originURL(mirrorPath)
+		if repoURL == "" {
+			log.Printf("Skipping repository without remote: %s", mirrorPath)
+			continue
+		}
+		mirrors = append(mirrors, Mirror{Path: mirrorPath, URL: repoURL})
+	}
+	return mirrors, nil
+}
+
+// originURL returns the URL of the remote origin of a mirror or a clone, empty if it has none.
+func originURL(repoPath string) string {
+	output, err := gitCommand(repoPath, "config", "--get", "remote.origin.url").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// FetchMirrors updates all mirrors of the mirror farm. Refs deleted in the remote repositories (e.g., merged
+// branches) are deleted in the mirrors as well, so the analysis does not report stale branches.
+//
+// Returns:
+//   - The number of updated mirrors.
+//   - An error naming every mirror which could not be updated, the other mirrors are updated anyway.
+func FetchMirrors(farm string) (int, error) {
+	mirrors, err := ListMirrors(farm)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	var errs []error
+	for _, mirror := range mirrors {
+		log.Printf("Fetching mirror: %s", mirror.Path)
+		output, err := gitRemoteCommand(mirror.Path, mirror.URL, "remote", "update", "--prune").CombinedOutput()
+		if err != nil {
+			errs = append(errs, remoteCommandError("fetch mirror "+mirror.Path, err, output))
+			continue
+		}
+		updated++
+	}
+	return updated, errors.Join(errs...)
+}
+
+// PruneMirrors removes the mirrors of repositories which are not listed anymore (e.g., archived or moved
+// repositories of an organization), so scheduled runs stop analyzing them.
+//
+// Clones borrowing the objects of a removed mirror (see CloneOptions.Reference) are broken, they have to be
+// cloned again.
+//
+// Parameters:
+//   - farm: The directory of the mirror farm.
+//   - repoURLs: The URLs of the repositories whose mirrors are kept.
+//   - dryRun: Whether the mirrors are only reported instead of removed.
+//
+// Returns:
+//   - The removed (or, with dryRun, the outdated) mirrors.
+//   - An error if a mirror could not be removed.
+func PruneMirrors(farm string, repoURLs []string, dryRun bool) ([]Mirror, error) {
+	mirrors, err := ListMirrors(farm)
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []Mirror
+	for _, mirror := range mirrors {
+		if slices.Contains(repoURLs, mirror.URL) {
+			continue
+		}
+		if !dryRun {
+			if err := os.RemoveAll(mirror.Path); err != nil {
+				return pruned, fmt.Errorf("failed to remove mirror %s: %w", mirror.Path, err)
+			}
+		}
+		pruned = append(pruned, mirror)
+	}
+	return pruned, nil
+}
@@ -0,0 +1,168 @@
+package gogitstats
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// BranchNamingPolicy lists the patterns the names of local branches must match (e.g., feature/JIRA-\d+-.*),
+// branches matching none of them violate the policy. The main branch and exempt branches are not checked.
+type BranchNamingPolicy struct {
+	// Patterns are regular expressions matching whole branch names
+	Patterns []string `yaml:"patterns" json:"patterns"`
+	// Exempt lists further branches not checked (e.g., develop), given as globs (e.g., dependabot/*)
+	Exempt  []string `yaml:"exempt" json:"exempt"`
+	regexes []*regexp.Regexp
+}
+
+// NamingViolation is a branch whose name matches no pattern of the branch naming policy.
+type NamingViolation struct {
+	Branch     string
+	LastCommit string          // 2006-01-02
+	Authors    []*UnmergedWork // authors of the commits not on the main branch, or of the tip if all are merged
+}
+
+// BranchNamingReport lists the local branches violating the branch naming policy.
+type BranchNamingReport struct {
+	Patterns   []string
+	Checked    int
+	Violations []*NamingViolation
+}
+
+// compile prepares the regular expressions of the policy, each matching whole branch names.
+func (policy *BranchNamingPolicy) compile() error {
+	if len(policy.Patterns) == 0 {
+		return fmt.Errorf("branch naming policy has no patterns")
+	}
+	policy.regexes = nil
+	for _, pattern := range policy.Patterns {
+		regex, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return fmt.Errorf("invalid branch naming pattern '%s': %w", pattern, err)
+		}
+		policy.regexes = append(policy.regexes, regex)
+	}
+	return validateBranchPatterns(policy.Exempt)
+}
+
+// exempts reports whether a branch is not checked against the policy, the main branch is exempt.
+func (policy *BranchNamingPolicy) exempts(branchName string, mainBranch string) bool {
+	if branchName == mainBranch {
+		return true
+	}
+	for _, pattern := range policy.Exempt {
+		if matched, _ := path.Match(pattern, branchName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// allows reports whether a branch name matches any pattern of the policy.
+func (policy *BranchNamingPolicy) allows(branchName string) bool {
+	for _, regex := range policy.regexes {
+		if regex.MatchString(branchName) {
+			return true
+		}
+	}
+	return false
+}
+
+// assessBranchNaming checks the names of all local branches (not only the analyzed ones) against the branch
+// naming policy of the configuration, the report is attached to the main branch report.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - branchReports: The branch reports.
+func (run *analysisRun) assessBranchNaming(repoPath string, branchReports map[string]*BranchReport) {
+	report, ok := branchReports[run.mainBranch]
+	if !ok {
+		return
+	}
+	report.Naming = nil
+	policy := run.config.BranchNaming
+	if policy == nil {
+		return
+	}
+
+	naming, err := run.checkBranchNaming(repoPath, policy)
+	if err != nil {
+		log.Printf("Checking branch names failed: %v", err)
+		return
+	}
+	report.Naming = naming
+}
+
+// checkBranchNaming lists the local branches violating the policy and the authors of their work.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - policy: The compiled branch naming policy.
+//
+// Returns:
+//   - The violations ordered by branch name.
+//   - An error if git failed.
+func (run *analysisRun) checkBranchNaming(repoPath string, policy *BranchNamingPolicy) (*BranchNamingReport, error) {
+	output, err := gitCommand(repoPath, "for-each-ref", "--format=%(refname:short)%09%(authoremail:trim)%09%(committerdate:short)", "refs/heads").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref failed: %w", err)
+	}
+
+	naming := &BranchNamingReport{Patterns: policy.Patterns}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || policy.exempts(fields[0], run.mainBranch) {
+			continue
+		}
+		naming.Checked++
+		if policy.allows(fields[0]) {
+			continue
+		}
+
+		violation := &NamingViolation{Branch: fields[0], LastCommit: fields[2]}
+		violation.Authors, err = run.branchAuthors(repoPath, fields[0])
+		if err != nil {
+			return nil, err
+		}
+		if len(violation.Authors) == 0 {
+			violation.Authors = []*UnmergedWork{{Email: run.config.canonicalEmail(fields[1])}}
+		}
+		naming.Violations = append(naming.Violations, violation)
+	}
+
+	sort.Slice(naming.Violations, func(i, j int) bool {
+		return naming.Violations[i].Branch < naming.Violations[j].Branch
+	})
+	return naming, nil
+}
+
+// branchAuthors returns the authors of the commits of a branch which are not on the main branch, most commits first.
+func (run *analysisRun) branchAuthors(repoPath string, branchName string) ([]*UnmergedWork, error) {
+	logRange := branchName
+	if _, err := resolveRevision(repoPath, run.branchRevision(run.mainBranch)); err == nil {
+		logRange = run.branchRevision(run.mainBranch) + ".." + branchName
+	}
+	output, err := gitCommand(repoPath, "log", "--format=%ae", logRange).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log of branch '%s' failed: %w", branchName, err)
+	}
+
+	var authors []*UnmergedWork
+	byEmail := make(map[string]*UnmergedWork)
+	for _, email := range strings.Fields(string(output)) {
+		email = run.config.canonicalEmail(email)
+		if _, ok := byEmail[email]; !ok {
+			byEmail[email] = &UnmergedWork{Email: email}
+			authors = append(authors, byEmail[email])
+		}
+		byEmail[email].CommitCount++
+	}
+	sort.SliceStable(authors, func(i, j int) bool {
+		return authors[i].CommitCount > authors[j].CommitCount
+	})
+	return authors, nil
+}
@@ -0,0 +1,544 @@
+package gogitstats
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OIDC_CALLBACK_PATH is the path of the served report, the identity provider redirects to after the login.
+const OIDC_CALLBACK_PATH = "/oidc/callback"
+
+const oidcSessionCookie = "gogitstats_session"
+const oidcLoginCookie = "gogitstats_login"
+
+// oidcSessionDuration limits how long a login is valid, before the identity provider is asked again.
+const oidcSessionDuration = 8 * time.Hour
+
+// oidcLoginTimeout limits how long the login at the identity provider may take.
+const oidcLoginTimeout = 10 * time.Minute
+
+// OIDCAuthenticator protects the served report with an OpenID Connect login (e.g., Okta, Entra ID, Keycloak)
+// using the authorization code flow.
+type OIDCAuthenticator struct {
+	issuer         string
+	clientID       string
+	clientSecret   string
+	redirectURL    string
+	allowedDomains []string
+	client         *http.Client
+
+	authorizationEndpoint string
+	tokenEndpoint         string
+	jwksURI               string
+
+	// sessionKey signs the cookies, it is created on start, so sessions end with the process
+	sessionKey []byte
+
+	// requestGroups requests the groups of the user (scope and claim "groups"), e.g. for access rules
+	requestGroups bool
+
+	keysMutex sync.Mutex
+	keys      map[string]crypto.PublicKey
+}
+
+// sessionUser is the user logged in by OIDC, it is kept in the session cookie.
+type sessionUser struct {
+	Email  string   `json:"email"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+type sessionUserKey struct{}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcJSONWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type oidcClaims struct {
+	Issuer        string          `json:"iss"`
+	Subject       string          `json:"sub"`
+	Audience      json.RawMessage `json:"aud"`
+	Expiry        int64           `json:"exp"`
+	Nonce         string          `json:"nonce"`
+	Email         string          `json:"email"`
+	EmailVerified *bool           `json:"email_verified"`
+	Groups        []string        `json:"groups"`
+}
+
+// NewOIDCAuthenticator creates an authenticator of the identity provider issuer, whose endpoints are
+// taken from its discovery document (/.well-known/openid-configuration).
+//
+// Parameters:
+//   - issuer: The issuer URL of the identity provider (e.g., https://example.okta.com).
+//   - clientID: The ID of the client registered at the identity provider.
+//   - clientSecret: The secret of the client.
+//   - redirectURL: The callback URL registered at the identity provider, ending with OIDC_CALLBACK_PATH.
+//   - allowedDomains: Email domains allowed to read the report, all authenticated users if empty.
+//
+// Returns:
+//   - The authenticator.
+//   - An error if the discovery document could not be fetched or is incomplete.
+func NewOIDCAuthenticator(issuer string, clientID string, clientSecret string, redirectURL string, allowedDomains []string) (*OIDCAuthenticator, error) {
+	auth := &OIDCAuthenticator{
+		issuer:         strings.TrimSuffix(issuer, "/"),
+		clientID:       clientID,
+		clientSecret:   clientSecret,
+		redirectURL:    redirectURL,
+		allowedDomains: allowedDomains,
+		client:         &http.Client{Timeout: 30 * time.Second},
+		sessionKey:     make([]byte, 32),
+		keys:           make(map[string]crypto.PublicKey),
+	}
+	if _, err := rand.Read(auth.sessionKey); err != nil {
+		return nil, err
+	}
+
+	discoveryURL := auth.issuer + "/.well-known/openid-configuration"
+	req, err := http.NewRequest(http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", discoveryURL, err)
+	}
+	var discovery oidcDiscovery
+	if err := doJSONRequest(auth.client, req, &discovery); err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document %s: %w", discoveryURL, err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != auth.issuer {
+		return nil, fmt.Errorf("issuer of the OIDC discovery document '%s' does not match '%s'", discovery.Issuer, auth.issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC discovery document %s lacks the authorization, token or JWKS endpoint", discoveryURL)
+	}
+	auth.issuer = discovery.Issuer
+	auth.authorizationEndpoint = discovery.AuthorizationEndpoint
+	auth.tokenEndpoint = discovery.TokenEndpoint
+	auth.jwksURI = discovery.JWKSURI
+	return auth, nil
+}
+
+// protect returns a handler, which serves next to users with a valid session only (see requestUser). Others
+// are redirected to the identity provider, requests of scripts (e.g., the live reload) are rejected.
+func (auth *OIDCAuthenticator) protect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == OIDC_CALLBACK_PATH {
+			auth.handleCallback(w, r)
+			return
+		}
+
+		if cookie, err := r.Cookie(oidcSessionCookie); err == nil {
+			if value, ok := auth.verifyCookie(cookie.Value); ok {
+				user := &sessionUser{}
+				if json.Unmarshal([]byte(value), user) == nil {
+					next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), sessionUserKey{}, user)))
+					return
+				}
+			}
+		}
+
+		if r.Method != http.MethodGet || strings.HasSuffix(r.URL.Path, "/version") {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		auth.startLogin(w, r)
+	})
+}
+
+// requestUser returns the user logged in by the handler of protect, nil if the report is not protected.
+func requestUser(r *http.Request) *sessionUser {
+	user, _ := r.Context().Value(sessionUserKey{}).(*sessionUser)
+	return user
+}
+
+// startLogin redirects to the authorization endpoint of the identity provider. State, nonce and the
+// requested page are kept in a signed cookie until the callback.
+func (auth *OIDCAuthenticator) startLogin(w http.ResponseWriter, r *http.Request) {
+	state, nonce := randomToken(), randomToken()
+	expiry := time.Now().Add(oidcLoginTimeout)
+	http.SetCookie(w, auth.newCookie(oidcLoginCookie, state+"|"+nonce+"|"+r.URL.Path, expiry))
+
+	scope := "openid email profile"
+	if auth.requestGroups {
+		scope += " groups"
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", auth.clientID)
+	query.Set("redirect_uri", auth.redirectURL)
+	query.Set("scope", scope)
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+
+	separator := "?"
+	if strings.Contains(auth.authorizationEndpoint, "?") {
+		separator = "&"
+	}
+	http.Redirect(w, r, auth.authorizationEndpoint+separator+query.Encode(), http.StatusFound)
+}
+
+// handleCallback exchanges the authorization code for an ID token, verifies it and starts the session.
+func (auth *OIDCAuthenticator) handleCallback(w http.ResponseWriter, r *http.Request) {
+	if message := r.URL.Query().Get("error"); message != "" {
+		log.Printf("OIDC login failed: %s %s", message, r.URL.Query().Get("error_description"))
+		http.Error(w, "Login failed", http.StatusUnauthorized)
+		return
+	}
+
+	cookie, err := r.Cookie(oidcLoginCookie)
+	if err != nil {
+		http.Error(w, "Login expired, please reload the report", http.StatusBadRequest)
+		return
+	}
+	value, ok := auth.verifyCookie(cookie.Value)
+	login := strings.SplitN(value, "|", 3)
+	if !ok || len(login) != 3 || !hmac.Equal([]byte(login[0]), []byte(r.URL.Query().Get("state"))) {
+		http.Error(w, "Login expired, please reload the report", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, auth.newCookie(oidcLoginCookie, "", time.Unix(0, 0)))
+
+	nonce, page := login[1], login[2]
+	if !strings.HasPrefix(page, "/") || strings.HasPrefix(page, "//") {
+		page = "/"
+	}
+
+	claims, err := auth.exchangeCode(r.URL.Query().Get("code"), nonce)
+	if err != nil {
+		log.Printf("OIDC login failed: %v", err)
+		http.Error(w, "Login failed", http.StatusUnauthorized)
+		return
+	}
+	if !auth.allowed(claims) {
+		log.Printf("OIDC login of '%s' denied, email domain is not allowed", claims.Email)
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	user := &sessionUser{Email: claims.Email, Groups: claims.Groups}
+	if user.Email == "" || (claims.EmailVerified != nil && !*claims.EmailVerified) {
+		// unverified emails must not match the access rules
+		user.Email = claims.Subject
+	}
+	session, err := json.Marshal(user)
+	if err != nil {
+		http.Error(w, "Login failed", http.StatusInternalServerError)
+		return
+	}
+	expiry := time.Now().Add(oidcSessionDuration)
+	http.SetCookie(w, auth.newCookie(oidcSessionCookie, string(session), expiry))
+	log.Printf("OIDC login of '%s'", user.Email)
+	http.Redirect(w, r, page, http.StatusFound)
+}
+
+// exchangeCode redeems the authorization code at the token endpoint.
+//
+// Returns:
+//   - The claims of the verified ID token.
+//   - An error if the code could not be redeemed or the ID token is invalid.
+func (auth *OIDCAuthenticator) exchangeCode(code string, nonce string) (*oidcClaims, error) {
+	if code == "" {
+		return nil, fmt.Errorf("callback without authorization code")
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", auth.redirectURL)
+	req, err := http.NewRequest(http.MethodPost, auth.tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", auth.tokenEndpoint, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(auth.clientID), url.QueryEscape(auth.clientSecret))
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := doJSONRequest(auth.client, req, &token); err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("token response without ID token")
+	}
+	return auth.verifyIDToken(token.IDToken, nonce)
+}
+
+// verifyIDToken verifies the signature (RS256/384/512, ES256/384/512 or EdDSA) of the ID token with
+// the keys of the identity provider, as well as its issuer, audience, expiry and nonce.
+func (auth *OIDCAuthenticator) verifyIDToken(idToken string, nonce string) (*oidcClaims, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed ID token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed ID token header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed ID token signature: %w", err)
+	}
+
+	key, err := auth.publicKey(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyJWTSignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	claims := &oidcClaims{}
+	if err := decodeJWTPart(parts[1], claims); err != nil {
+		return nil, fmt.Errorf("malformed ID token claims: %w", err)
+	}
+	if claims.Issuer != auth.issuer {
+		return nil, fmt.Errorf("ID token of unexpected issuer '%s'", claims.Issuer)
+	}
+	var audience []string
+	if err := json.Unmarshal(claims.Audience, &audience); err != nil {
+		var single string
+		json.Unmarshal(claims.Audience, &single)
+		audience = []string{single}
+	}
+	if !slices.Contains(audience, auth.clientID) {
+		return nil, fmt.Errorf("ID token of unexpected audience %v", audience)
+	}
+	if time.Now().Unix() >= claims.Expiry {
+		return nil, fmt.Errorf("ID token expired")
+	}
+	if !hmac.Equal([]byte(claims.Nonce), []byte(nonce)) {
+		return nil, fmt.Errorf("ID token with unexpected nonce")
+	}
+	return claims, nil
+}
+
+// allowed reports whether the authenticated user may read the report.
+func (auth *OIDCAuthenticator) allowed(claims *oidcClaims) bool {
+	if len(auth.allowedDomains) == 0 {
+		return true
+	}
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		return false
+	}
+	_, domain, found := strings.Cut(claims.Email, "@")
+	return found && slices.Contains(auth.allowedDomains, strings.ToLower(domain))
+}
+
+// publicKey returns the key with the given ID, the keys are fetched again for unknown IDs (key rotation).
+func (auth *OIDCAuthenticator) publicKey(kid string) (crypto.PublicKey, error) {
+	auth.keysMutex.Lock()
+	defer auth.keysMutex.Unlock()
+
+	if key, ok := auth.keys[kid]; ok {
+		return key, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, auth.jwksURI, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request for %s: %w", auth.jwksURI, err)
+	}
+	var jwks struct {
+		Keys []oidcJSONWebKey `json:"keys"`
+	}
+	if err := doJSONRequest(auth.client, req, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch keys of the identity provider: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range jwks.Keys {
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.Printf("Ignoring key '%s' of the identity provider: %v", jwk.Kid, err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	auth.keys = keys
+
+	if key, ok := auth.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("ID token signed with unknown key '%s'", kid)
+}
+
+// publicKey decodes RSA, EC (P-256, P-384, P-521) and Ed25519 keys.
+func (jwk *oidcJSONWebKey) publicKey() (crypto.PublicKey, error) {
+	decode := func(value string) (*big.Int, error) {
+		content, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetBytes(content), nil
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+		curve, ok := curves[jwk.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve '%s'", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve '%s'", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type '%s'", jwk.Kty)
+}
+
+// verifyJWTSignature verifies the signature of the signed content (header and claims) with the key.
+func verifyJWTSignature(alg string, key crypto.PublicKey, signed []byte, signature []byte) error {
+	hashes := map[string]func() hash.Hash{"256": sha256.New, "384": sha512.New384, "512": sha512.New}
+	hashFunctions := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}
+
+	if alg == "EdDSA" {
+		if public, ok := key.(ed25519.PublicKey); ok && ed25519.Verify(public, signed, signature) {
+			return nil
+		}
+		return fmt.Errorf("invalid ID token signature")
+	}
+	if len(alg) != 5 || hashes[alg[2:]] == nil {
+		return fmt.Errorf("unsupported ID token algorithm '%s'", alg)
+	}
+
+	digest := hashes[alg[2:]]()
+	digest.Write(signed)
+	sum := digest.Sum(nil)
+
+	switch public := key.(type) {
+	case *rsa.PublicKey:
+		if strings.HasPrefix(alg, "RS") && rsa.VerifyPKCS1v15(public, hashFunctions[alg[2:]], sum, signature) == nil {
+			return nil
+		}
+	case *ecdsa.PublicKey:
+		size := (public.Curve.Params().BitSize + 7) / 8
+		if strings.HasPrefix(alg, "ES") && len(signature) == 2*size {
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			if ecdsa.Verify(public, sum, r, s) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("invalid ID token signature")
+}
+
+func decodeJWTPart(part string, result interface{}) error {
+	content, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, result)
+}
+
+// newCookie creates a cookie with the value and its expiry signed by the session key.
+func (auth *OIDCAuthenticator) newCookie(name string, value string, expiry time.Time) *http.Cookie {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(value)) + "." + strconv.FormatInt(expiry.Unix(), 10)
+	return &http.Cookie{
+		Name:     name,
+		Value:    payload + "." + auth.sign(payload),
+		Path:     "/",
+		Expires:  expiry,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(auth.redirectURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// verifyCookie returns the value of a cookie created by newCookie, if its signature is valid and it did not expire.
+func (auth *OIDCAuthenticator) verifyCookie(cookie string) (string, bool) {
+	parts := strings.Split(cookie, ".")
+	if len(parts) != 3 {
+		return "", false
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(auth.sign(payload)), []byte(parts[2])) {
+		return "", false
+	}
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() >= expiry {
+		return "", false
+	}
+	value, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", false
+	}
+	return string(value), true
+}
+
+func (auth *OIDCAuthenticator) sign(payload string) string {
+	mac := hmac.New(sha256.New, auth.sessionKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func randomToken() string {
+	token := make([]byte, 16)
+	rand.Read(token)
+	return hex.EncodeToString(token)
+}
@@ -0,0 +1,115 @@
+package gogitstats
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// OUTPUT_STDOUT writes the report to the standard output instead of a file, see Options.Output.
+const OUTPUT_STDOUT = "-"
+
+// IsOutputDirectory reports whether the output (see Options.Output) names a directory the reports are written
+// into: a path ending with a separator or an existing directory.
+func IsOutputDirectory(output string) bool {
+	if output == "" || output == OUTPUT_STDOUT {
+		return false
+	}
+	if strings.HasSuffix(output, "/") || strings.HasSuffix(output, string(os.PathSeparator)) {
+		return true
+	}
+	info, err := os.Stat(output)
+	return err == nil && info.IsDir()
+}
+
+// outputName names a file or directory written by the analyzer, timestamped unless Options.Overwrite is set.
+//
+// Parameters:
+//   - name: The name without timestamp, e.g. "report_api".
+//   - extension: The extension of files (e.g., "html"), empty for directories.
+//   - generatedOn: The time the output is generated.
+func (analyzer *Analyzer) outputName(name string, extension string, generatedOn time.Time) string {
+	if !analyzer.options.Overwrite {
+		name = name + "_" + generatedOn.Format("2006-01-02_150405")
+	}
+	if extension != "" {
+		name = name + "." + extension
+	}
+	return name
+}
+
+// outputDirectory returns the directory the reports and the files next to them (e.g., calendars) are written
+// into, creating it if needed.
+func (analyzer *Analyzer) outputDirectory() (string, error) {
+	output := analyzer.options.Output
+	directory := ""
+	if IsOutputDirectory(output) {
+		directory = output
+	} else if output != "" && output != OUTPUT_STDOUT {
+		directory = filepath.Dir(output)
+	}
+	if directory == "" || directory == "." {
+		return "", nil
+	}
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", directory, err)
+	}
+	return directory, nil
+}
+
+// outputSubdirectory returns the path of a directory of files written next to the reports (e.g., calendars), the
+// directory of a previous run is removed if Options.Overwrite is set, so it holds no outdated files.
+func (analyzer *Analyzer) outputSubdirectory(name string, generatedOn time.Time) (string, error) {
+	directory, err := analyzer.outputDirectory()
+	if err != nil {
+		return "", err
+	}
+	subdirectory := filepath.Join(directory, analyzer.outputName(name, "", generatedOn))
+	if analyzer.options.Overwrite {
+		if err := os.RemoveAll(subdirectory); err != nil {
+			return "", fmt.Errorf("failed to remove directory %s: %w", subdirectory, err)
+		}
+	}
+	return subdirectory, nil
+}
+
+// outputPath returns the path a report file is written to, see Options.Output and Options.Overwrite.
+//
+// Parameters:
+//   - name: The name of the report without timestamp and extension, e.g. "report_api".
+//   - extension: The extension of the report, i.e. its format.
+//   - generatedOn: The time the report is generated.
+//
+// Returns:
+//   - The path, OUTPUT_STDOUT for the standard output.
+//   - An error if the directory could not be created or the file exists and must not be overwritten.
+func (analyzer *Analyzer) outputPath(name string, extension string, generatedOn time.Time) (string, error) {
+	output := analyzer.options.Output
+	if output == OUTPUT_STDOUT {
+		return OUTPUT_STDOUT, nil
+	}
+
+	directory, err := analyzer.outputDirectory()
+	if err != nil {
+		return "", err
+	}
+	if output == "" || IsOutputDirectory(output) {
+		return filepath.Join(directory, analyzer.outputName(name, extension, generatedOn)), nil
+	}
+
+	if _, err := os.Stat(output); err == nil && !analyzer.options.Overwrite {
+		return "", fmt.Errorf("output file %s already exists, enable overwriting (e.g., `--overwrite`) to replace it", output)
+	}
+	return output, nil
+}
+
+// writeOutput writes the content to the file or the standard output (OUTPUT_STDOUT).
+func writeOutput(outputPath string, content string) error {
+	if outputPath == OUTPUT_STDOUT {
+		_, err := os.Stdout.WriteString(content)
+		return err
+	}
+	return os.WriteFile(outputPath, []byte(content), 0644)
+}
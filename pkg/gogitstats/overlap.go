@@ -0,0 +1,57 @@
+package gogitstats
+
+import (
+	"sort"
+)
+
+type ContributorOverlap struct {
+	Email       string
+	Commits     []int // Commit count per branch, in the order of OverlapMatrix.Branches
+	BranchCount int
+	Spread      bool
+}
+
+type OverlapMatrix struct {
+	Branches []string
+	Rows     []*ContributorOverlap
+}
+
+// buildOverlapMatrix shows which contributors are active on which branches.
+//
+// Contributors active on at least half of the branches (and at least three) are marked as spread
+// across branches, contributors active on a single branch are branch specialists.
+func buildOverlapMatrix(branchReports map[string]*BranchReport) *OverlapMatrix {
+	matrix := &OverlapMatrix{}
+	for branchName := range branchReports {
+		matrix.Branches = append(matrix.Branches, branchName)
+	}
+	sort.Strings(matrix.Branches)
+
+	rows := make(map[string]*ContributorOverlap)
+	for i, branchName := range matrix.Branches {
+		for email, contribution := range branchReports[branchName].Contributions {
+			row, ok := rows[email]
+			if !ok {
+				row = &ContributorOverlap{Email: email, Commits: make([]int, len(matrix.Branches))}
+				rows[email] = row
+				matrix.Rows = append(matrix.Rows, row)
+			}
+			row.Commits[i] = contribution.CommitCount
+			row.BranchCount++
+		}
+	}
+
+	spreadThreshold := max(3, (len(matrix.Branches)+1)/2)
+	for _, row := range matrix.Rows {
+		row.Spread = row.BranchCount >= spreadThreshold
+	}
+
+	sort.Slice(matrix.Rows, func(i, j int) bool {
+		if matrix.Rows[i].BranchCount != matrix.Rows[j].BranchCount {
+			return matrix.Rows[i].BranchCount > matrix.Rows[j].BranchCount
+		}
+		return matrix.Rows[i].Email < matrix.Rows[j].Email
+	})
+
+	return matrix
+}
@@ -0,0 +1,183 @@
+package gogitstats
+
+import (
+	"log"
+	"sort"
+	"time"
+)
+
+// pairingMinSequences is the minimum number of sequences after which two authors are reported.
+const pairingMinSequences = 2
+
+// maxPairings limits the number of author pairs listed.
+const maxPairings = 20
+
+const PAIRING_PATTERN_PAIRING = "pairing"
+const PAIRING_PATTERN_HANDOFF = "hand-off"
+const PAIRING_PATTERN_COAUTHORED = "co-authored"
+
+// AuthorPairing describes two authors who committed to the same files shortly after each other.
+type AuthorPairing struct {
+	First       string // author of the earlier commits of the most sequences
+	Second      string
+	Sequences   int // commit pairs (earlier, later) touching a common file within the window
+	Forward     int // sequences in which First committed before Second
+	FileCount   int
+	CoAuthored  int // commits of either author naming the other in a Co-authored-by trailer
+	Pattern     string
+	LastCommits string
+}
+
+type PairingReport struct {
+	Window   time.Duration
+	Pairings []*AuthorPairing
+}
+
+// assessPairing reports likely pairing and hand-off patterns across all local branches.
+// The report is attached to the main branch report.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - branchReports: The branch reports.
+//   - fileFilter: The pathspec limiting the files, ignored if empty.
+func (run *analysisRun) assessPairing(repoPath string, branchReports map[string]*BranchReport, fileFilter string) {
+	report, ok := branchReports[run.mainBranch]
+	if !ok {
+		return
+	}
+
+	edits, err := run.listFileEdits(repoPath, fileFilter)
+	if err != nil {
+		log.Printf("Detecting pairing failed: %v", err)
+		report.Pairing = nil
+		return
+	}
+	report.Pairing = detectPairing(edits, run.pairingWindow)
+}
+
+// detectPairing finds clusters of commits by different authors on the same files within window.
+//
+// Whenever a file is changed by one author and within window by another, the two commits form a sequence.
+// Authors whose sequences go in both directions likely work together (pairing, batch work), authors whose
+// sequences go in one direction only likely hand work off.
+//
+// Parameters:
+//   - edits: The edits by file path.
+//   - window: The maximum time between the commits of a sequence.
+//
+// Returns:
+//   - The author pairs with at least pairingMinSequences sequences, most sequences first.
+func detectPairing(edits map[string][]fileEdit, window time.Duration) *PairingReport {
+	type pairKey struct{ a, b string } // a < b
+	type pairStat struct {
+		sequences map[[2]string]bool // (earlier, later) commit hashes
+		forward   int                // sequences in which a committed before b
+		files     map[string]bool
+		last      time.Time
+	}
+
+	pairs := make(map[pairKey]*pairStat)
+	coAuthored := make(map[pairKey]map[string]bool) // commit hashes
+	for filePath, fileEdits := range edits {
+		sort.Slice(fileEdits, func(i, j int) bool {
+			return fileEdits[i].Time.Before(fileEdits[j].Time)
+		})
+
+		for i, edit := range fileEdits {
+			for _, coAuthor := range edit.CoAuthors {
+				if coAuthor == edit.Email {
+					continue
+				}
+				key := pairKey{min(edit.Email, coAuthor), max(edit.Email, coAuthor)}
+				if coAuthored[key] == nil {
+					coAuthored[key] = make(map[string]bool)
+				}
+				coAuthored[key][edit.Hash] = true
+			}
+
+			// the next commit of another author within the window continues the work on the file
+			for _, next := range fileEdits[i+1:] {
+				if next.Time.Sub(edit.Time) > window {
+					break
+				}
+				if next.Email == edit.Email {
+					break
+				}
+
+				key := pairKey{min(edit.Email, next.Email), max(edit.Email, next.Email)}
+				state, ok := pairs[key]
+				if !ok {
+					state = &pairStat{sequences: make(map[[2]string]bool), files: make(map[string]bool)}
+					pairs[key] = state
+				}
+				state.files[filePath] = true
+				if next.Time.After(state.last) {
+					state.last = next.Time
+				}
+				sequence := [2]string{edit.Hash, next.Hash}
+				if !state.sequences[sequence] {
+					state.sequences[sequence] = true
+					if edit.Email == key.a {
+						state.forward++
+					}
+				}
+				break
+			}
+		}
+	}
+
+	// pairs known from Co-authored-by trailers only are reported as well
+	for key := range coAuthored {
+		if _, ok := pairs[key]; !ok {
+			pairs[key] = &pairStat{sequences: make(map[[2]string]bool), files: make(map[string]bool)}
+		}
+	}
+
+	report := &PairingReport{Window: window}
+	for key, state := range pairs {
+		if len(state.sequences) < pairingMinSequences && len(coAuthored[key]) == 0 {
+			continue
+		}
+
+		pairing := &AuthorPairing{
+			First:       key.a,
+			Second:      key.b,
+			Sequences:   len(state.sequences),
+			Forward:     state.forward,
+			FileCount:   len(state.files),
+			CoAuthored:  len(coAuthored[key]),
+			Pattern:     PAIRING_PATTERN_PAIRING,
+			LastCommits: state.last.Format("2006-01-02"),
+		}
+		if pairing.Forward*2 < pairing.Sequences {
+			pairing.First, pairing.Second = pairing.Second, pairing.First
+			pairing.Forward = pairing.Sequences - pairing.Forward
+		}
+		if pairing.Sequences == 0 {
+			pairing.Pattern = PAIRING_PATTERN_COAUTHORED
+			pairing.LastCommits = ""
+		} else if pairing.Forward == pairing.Sequences {
+			pairing.Pattern = PAIRING_PATTERN_HANDOFF
+		}
+		report.Pairings = append(report.Pairings, pairing)
+	}
+
+	sort.Slice(report.Pairings, func(i, j int) bool {
+		a, b := report.Pairings[i], report.Pairings[j]
+		if a.Sequences != b.Sequences {
+			return a.Sequences > b.Sequences
+		}
+		if a.CoAuthored != b.CoAuthored {
+			return a.CoAuthored > b.CoAuthored
+		}
+		if a.First != b.First {
+			return a.First < b.First
+		}
+		return a.Second < b.Second
+	})
+	if len(report.Pairings) > maxPairings {
+		report.Pairings = report.Pairings[:maxPairings]
+	}
+
+	return report
+}
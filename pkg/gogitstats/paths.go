@@ -0,0 +1,132 @@
+package gogitstats
+
+import (
+	"sort"
+	"strings"
+)
+
+// PATH_BREAKDOWN_DIRECTORY and PATH_BREAKDOWN_FILE select how contributions are broken down by path.
+const PATH_BREAKDOWN_DIRECTORY = "directory"
+const PATH_BREAKDOWN_FILE = "file"
+
+// maxReportedPaths limits the paths listed per branch, the most edited ones are kept.
+const maxReportedPaths = 50
+
+// PathAuthor is the contribution of an author to a directory or file.
+type PathAuthor struct {
+	Email        string
+	CommitCount  int
+	LinesAdded   int
+	LinesRemoved int
+}
+
+// LinesEdited returns the lines added and removed.
+func (author *PathAuthor) LinesEdited() int {
+	return author.LinesAdded + author.LinesRemoved
+}
+
+// PathContribution sums up the changes of a directory or file, broken down by author.
+type PathContribution struct {
+	Path         string
+	CommitCount  int
+	LinesAdded   int
+	LinesRemoved int
+	Authors      map[string]*PathAuthor
+}
+
+// LinesEdited returns the lines added and removed.
+func (contribution *PathContribution) LinesEdited() int {
+	return contribution.LinesAdded + contribution.LinesRemoved
+}
+
+// breakdownPath returns the directory (formed by the first pathDepth directories, e.g. "src/billing")
+// or the file a changed file is counted for. Files in the repository root are counted for ".".
+func (run *analysisRun) breakdownPath(filePath string) string {
+	if run.pathBreakdown == PATH_BREAKDOWN_FILE {
+		return filePath
+	}
+
+	directories := strings.Split(filePath, "/")
+	directories = directories[:len(directories)-1]
+	if len(directories) == 0 {
+		return "."
+	}
+	if len(directories) > run.pathDepth {
+		directories = directories[:run.pathDepth]
+	}
+	return strings.Join(directories, "/")
+}
+
+// addPathContributions adds the changed files of a commit to the directories or files of the branch report.
+//
+// Renamed files are followed, their changes before a rename are counted for their current path (see renamedPaths).
+func (run *analysisRun) addPathContributions(report *BranchReport, commit CommitRecord) {
+	counted := make(map[string]bool)
+	for _, change := range commit.Files {
+		pathName := run.breakdownPath(report.renamedPaths.follow(change))
+		contribution, ok := report.Paths[pathName]
+		if !ok {
+			contribution = &PathContribution{Path: pathName, Authors: make(map[string]*PathAuthor)}
+			report.Paths[pathName] = contribution
+		}
+		author, ok := contribution.Authors[commit.Email]
+		if !ok {
+			author = &PathAuthor{Email: commit.Email}
+			contribution.Authors[commit.Email] = author
+		}
+
+		// a commit is counted once per path, even if it changed several files of a directory
+		if !counted[pathName] {
+			counted[pathName] = true
+			contribution.CommitCount++
+			author.CommitCount++
+		}
+		if change.Binary {
+			continue
+		}
+		contribution.LinesAdded += change.Added
+		contribution.LinesRemoved += change.Removed
+		author.LinesAdded += change.Added
+		author.LinesRemoved += change.Removed
+	}
+}
+
+// sortPaths orders the directories or files by lines edited (then commits), descending, and keeps the
+// first maxReportedPaths of them.
+func sortPaths(paths map[string]*PathContribution) []*PathContribution {
+	sorted := make([]*PathContribution, 0, len(paths))
+	for _, contribution := range paths {
+		sorted = append(sorted, contribution)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].LinesEdited() != sorted[j].LinesEdited() {
+			return sorted[i].LinesEdited() > sorted[j].LinesEdited()
+		}
+		if sorted[i].CommitCount != sorted[j].CommitCount {
+			return sorted[i].CommitCount > sorted[j].CommitCount
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+	if len(sorted) > maxReportedPaths {
+		sorted = sorted[:maxReportedPaths]
+	}
+	return sorted
+}
+
+// sortPathAuthors orders the authors of a directory or file by lines edited (then commits), descending.
+func sortPathAuthors(authors map[string]*PathAuthor) []*PathAuthor {
+	sorted := make([]*PathAuthor, 0, len(authors))
+	for _, author := range authors {
+		sorted = append(sorted, author)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].LinesEdited() != sorted[j].LinesEdited() {
+			return sorted[i].LinesEdited() > sorted[j].LinesEdited()
+		}
+		if sorted[i].CommitCount != sorted[j].CommitCount {
+			return sorted[i].CommitCount > sorted[j].CommitCount
+		}
+		return sorted[i].Email < sorted[j].Email
+	})
+	return sorted
+}
@@ -0,0 +1,62 @@
+package gogitstats
+
+import (
+	"regexp"
+	"strings"
+)
+
+// fileExtensionPattern matches file filters naming a file type by its extension, e.g. "go" or ".go".
+var fileExtensionPattern = regexp.MustCompile(`^\.?[A-Za-z0-9_+-]+$`)
+
+// fileFilterPathspecs translates a file filter (see Options.FileFilter) into the pathspecs passed to git: a
+// comma-separated list of file types (e.g., "go" or ".go" become "*.go"), globs and directories (e.g., "src/*.go",
+// "docs/") and exclusions (e.g., ":!vendor/" or ":(exclude)vendor/"), which are passed as they are.
+func fileFilterPathspecs(fileFilter string) []string {
+	var pathspecs []string
+	for _, item := range strings.Split(fileFilter, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if fileExtensionPattern.MatchString(item) {
+			item = "*." + strings.TrimPrefix(item, ".")
+		}
+		pathspecs = append(pathspecs, item)
+	}
+	return pathspecs
+}
+
+// pathspecArgs returns the trailing arguments of git commands limiting them to the file filter, nil if empty.
+func pathspecArgs(fileFilter string) []string {
+	pathspecs := fileFilterPathspecs(fileFilter)
+	if len(pathspecs) == 0 {
+		return nil
+	}
+	return append([]string{"--"}, pathspecs...)
+}
+
+// pathspecMagic splits a pathspec into its magic prefix (e.g., ":!" or ":(exclude)") and its pattern.
+func pathspecMagic(pathspec string) (string, string) {
+	if !strings.HasPrefix(pathspec, ":") {
+		return "", pathspec
+	}
+	if strings.HasPrefix(pathspec, ":(") {
+		if end := strings.Index(pathspec, ")"); end > 0 {
+			return pathspec[:end+1], pathspec[end+1:]
+		}
+		return pathspec, ""
+	}
+	end := 1
+	for end < len(pathspec) && strings.ContainsRune("!^/", rune(pathspec[end])) {
+		end++
+	}
+	if end < len(pathspec) && pathspec[end] == ':' {
+		end++
+	}
+	return pathspec[:end], pathspec[end:]
+}
+
+// isExcludingPathspec reports whether the magic of a pathspec excludes the matching paths.
+func isExcludingPathspec(magic string) bool {
+	return strings.ContainsAny(magic, "!^") || strings.Contains(magic, "exclude")
+}
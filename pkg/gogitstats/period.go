@@ -0,0 +1,103 @@
+package gogitstats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// timelinePeriod returns the timeline key ("2024-JAN" or "2024-05") of a short date (2006-01-02),
+// depending on the configured grouping of the git log date.
+func (run *analysisRun) timelinePeriod(date string) (string, bool) {
+	dateParsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", false
+	}
+
+	if run.groupBy == "month" {
+		yearMonth := fmt.Sprintf("%d-%s", dateParsed.Year(), dateParsed.Month().String()[:3])
+		return strings.ToUpper(yearMonth), true
+	}
+
+	year, week := dateParsed.ISOWeek()
+	return fmt.Sprintf("%d-%02d", year, week), true
+}
+
+// periodSortKey converts a timeline key into a key which sorts chronologically.
+func periodSortKey(period string) string {
+	if parsed, err := time.Parse("2006-Jan", period); err == nil {
+		return parsed.Format("2006-01")
+	}
+	return period
+}
+
+// sortPeriods sorts timeline keys chronologically.
+func sortPeriods(periods []string) []string {
+	sort.Slice(periods, func(i, j int) bool {
+		return periodSortKey(periods[i]) < periodSortKey(periods[j])
+	})
+	return periods
+}
+
+// periodStart returns the first day of a timeline key ("2024-JAN" or "2024-05").
+func periodStart(period string) (time.Time, bool) {
+	if parsed, err := time.Parse("2006-Jan", period); err == nil {
+		return parsed, true
+	}
+
+	var year, week int
+	if _, err := fmt.Sscanf(period, "%d-%d", &year, &week); err != nil {
+		return time.Time{}, false
+	}
+	// the 4th of January is always in the first ISO week
+	firstWeek := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	firstWeek = firstWeek.AddDate(0, 0, -((int(firstWeek.Weekday()) + 6) % 7))
+	return firstWeek.AddDate(0, 0, (week-1)*7), true
+}
+
+// nextPeriod returns the timeline key following the given one.
+func (run *analysisRun) nextPeriod(period string) (string, bool) {
+	start, ok := periodStart(period)
+	if !ok {
+		return "", false
+	}
+	if run.groupBy == "month" {
+		return run.timelinePeriod(start.AddDate(0, 1, 0).Format("2006-01-02"))
+	}
+	return run.timelinePeriod(start.AddDate(0, 0, 7).Format("2006-01-02"))
+}
+
+// followingPeriods returns the count timeline keys following the given one.
+func (run *analysisRun) followingPeriods(period string, count int) []string {
+	var periods []string
+	for len(periods) < count {
+		next, ok := run.nextPeriod(period)
+		if !ok {
+			break
+		}
+		periods = append(periods, next)
+		period = next
+	}
+	return periods
+}
+
+// periodRange fills the gaps between chronologically sorted timeline keys, so that every period
+// from the first to the last one is included.
+func (run *analysisRun) periodRange(sorted []string) []string {
+	if len(sorted) == 0 {
+		return nil
+	}
+
+	last := periodSortKey(sorted[len(sorted)-1])
+	periods := []string{sorted[0]}
+	for period := sorted[0]; periodSortKey(period) < last; {
+		next, ok := run.nextPeriod(period)
+		if !ok || periodSortKey(next) <= periodSortKey(period) {
+			return sorted // keys not produced by timelinePeriod
+		}
+		periods = append(periods, next)
+		period = next
+	}
+	return periods
+}
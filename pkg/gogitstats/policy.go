@@ -0,0 +1,53 @@
+package gogitstats
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// defaultTicketReferencePattern matches Jira style keys (PROJ-123) and GitHub/GitLab style references (#123).
+const defaultTicketReferencePattern = `\b[A-Z][A-Z0-9_]+-[0-9]+\b|#[0-9]+\b`
+
+type TicketPolicy struct {
+	Pattern string `yaml:"pattern" json:"pattern"`
+	regex   *regexp.Regexp
+}
+
+type PeriodCompliance struct {
+	Period        string
+	CommitCount   int
+	WithoutTicket int
+}
+
+// compile prepares the regular expression of the policy, falling back to the default pattern.
+func (policy *TicketPolicy) compile() error {
+	if policy.Pattern == "" {
+		policy.Pattern = defaultTicketReferencePattern
+	}
+
+	regex, err := regexp.Compile(policy.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid ticket policy pattern '%s': %w", policy.Pattern, err)
+	}
+	policy.regex = regex
+	return nil
+}
+
+// referencesTicket reports whether the commit message satisfies the policy.
+func (policy *TicketPolicy) referencesTicket(message string) bool {
+	return policy.regex.MatchString(message)
+}
+
+// sortedCompliance returns the per period compliance of a branch in chronological order.
+func sortedCompliance(compliance map[string]*PeriodCompliance) []*PeriodCompliance {
+	periods := make([]string, 0, len(compliance))
+	for period := range compliance {
+		periods = append(periods, period)
+	}
+
+	sorted := make([]*PeriodCompliance, 0, len(compliance))
+	for _, period := range sortPeriods(periods) {
+		sorted = append(sorted, compliance[period])
+	}
+	return sorted
+}
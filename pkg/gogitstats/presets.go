@@ -0,0 +1,53 @@
+package gogitstats
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PRESET_QUICK, PRESET_STANDARD and PRESET_DEEP name the presets of analysis settings, see Options.applyPreset.
+const PRESET_QUICK = "quick"
+const PRESET_STANDARD = "standard"
+const PRESET_DEEP = "deep"
+
+var ANALYSIS_PRESETS = []string{PRESET_QUICK, PRESET_STANDARD, PRESET_DEEP}
+
+// quickPresetSections are the sections computed from the log of the main branch alone, without further git
+// commands over all branches, signature verification or API requests.
+var quickPresetSections = []string{"summary", "timelines", "compliance", "categories", "components", "focus", "teams", "themes"}
+
+// deepPresetForecastPeriods is the number of periods forecasted by the deep preset.
+const deepPresetForecastPeriods = 3
+
+// applyPreset fills the settings left empty with the values of the preset, settings given explicitly (or
+// in the configuration, for the sections) take precedence.
+//
+//   - quick: the main branch of the last 90 days, sections which need no analysis beyond its log.
+//   - standard: the defaults, i.e. all branches and all sections.
+//   - deep: the defaults, extended by the breakdown by directory (ownership) and an activity forecast.
+//
+// Returns:
+//   - An error if the preset is not known.
+func (options *Options) applyPreset() error {
+	switch options.Preset {
+	case "", PRESET_STANDARD:
+	case PRESET_QUICK:
+		options.MainBranchOnly = true
+		if options.Since == "" {
+			options.Since = "90.days"
+		}
+		if len(options.Sections) == 0 && (options.Config == nil || options.Config.Sections == nil) {
+			options.Sections = quickPresetSections
+		}
+	case PRESET_DEEP:
+		if options.PathBreakdown == "" {
+			options.PathBreakdown = PATH_BREAKDOWN_DIRECTORY
+		}
+		if options.ForecastPeriods == 0 {
+			options.ForecastPeriods = deepPresetForecastPeriods
+		}
+	default:
+		return fmt.Errorf("preset '%s' is not supported, expected any of: %s", options.Preset, strings.Join(ANALYSIS_PRESETS, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package gogitstats
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// OutputProfile describes what a report reveals to its audience, e.g. "external" or "internal".
+type OutputProfile struct {
+	// AnonymizeEmails replaces emails with stable pseudonyms
+	AnonymizeEmails bool `yaml:"anonymizeEmails" json:"anonymizeEmails"`
+	// HidePaths removes file paths, path patterns and the file filter from the report
+	HidePaths bool `yaml:"hidePaths" json:"hidePaths"`
+	// Columns replaces the columns selected with `--columns`, if set
+	Columns []string `yaml:"columns" json:"columns"`
+}
+
+// selectOutputProfile looks up the named profile in the configuration.
+//
+// Parameters:
+//   - config: The loaded configuration.
+//   - name: The name of the profile as given with `--profile`.
+//
+// Returns:
+//   - The profile.
+//   - An error if the profile is not configured or selects unknown columns.
+func selectOutputProfile(config *Config, name string) (*OutputProfile, error) {
+	profile, ok := config.Profiles[name]
+	if !ok || profile == nil {
+		names := make([]string, 0, len(config.Profiles))
+		for configured := range config.Profiles {
+			names = append(names, configured)
+		}
+		slices.Sort(names)
+		return nil, fmt.Errorf("profile '%s' is not configured, available profiles: %s", name, strings.Join(names, ", "))
+	}
+
+	if profile.Columns != nil {
+		if _, err := parseReportColumns(strings.Join(profile.Columns, ",")); err != nil {
+			return nil, fmt.Errorf("invalid columns of profile '%s': %w", name, err)
+		}
+	}
+
+	return profile, nil
+}
+
+// reportColumns returns the columns to show, restricted by the profile.
+func (profile *OutputProfile) reportColumns(selected []string) []string {
+	columns := selected
+	if profile.Columns != nil {
+		columns = profile.Columns
+	}
+
+	var allowed []string
+	for _, column := range columns {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if profile.HidePaths && column == "filter" {
+			continue
+		}
+		allowed = append(allowed, column)
+	}
+	return allowed
+}
+
+// displayEmail returns the email as it is shown in the report.
+//
+// Anonymized emails are replaced by a pseudonym derived from a hash of the email, so the same person
+// gets the same pseudonym in every section and every report.
+func (profile *OutputProfile) displayEmail(email string) string {
+	if !profile.AnonymizeEmails {
+		return email
+	}
+	sum := sha256.Sum256([]byte(strings.ToLower(email)))
+	return "contributor-" + hex.EncodeToString(sum[:])[:10]
+}
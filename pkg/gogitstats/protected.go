@@ -0,0 +1,133 @@
+package gogitstats
+
+import (
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// COMMIT_URL_PLACEHOLDER is replaced by the hash of a commit in the commitURL of the configuration.
+const COMMIT_URL_PLACEHOLDER = "{hash}"
+
+// ProtectedChange is a commit changing protected paths.
+type ProtectedChange struct {
+	Hash    string
+	Date    string // 2006-01-02
+	Email   string
+	Subject string
+	Paths   []string // protected paths changed by the commit
+	URL     string   // the commit on the hosting platform, empty if unknown
+}
+
+// ProtectedPathReport is the audit trail of the protected paths: every commit of the analyzed branches and
+// period changing them, regardless of the selected authors and excluded revisions.
+type ProtectedPathReport struct {
+	Patterns []string
+	Authors  int
+	Changes  []*ProtectedChange // newest first
+}
+
+// assessProtectedPaths lists the commits of all analyzed branches changing the protected paths of the
+// configuration, each commit once, the report is attached to the main branch report.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - branchReports: The branch reports.
+//   - fileFilter: The pathspec limiting the commits and files, ignored if empty.
+func (run *analysisRun) assessProtectedPaths(repoPath string, branchReports map[string]*BranchReport, fileFilter string) {
+	report, ok := branchReports[run.mainBranch]
+	if !ok {
+		return
+	}
+	report.Protected = nil
+	if len(run.config.ProtectedPaths) == 0 {
+		return
+	}
+
+	protected := PathCategory{Patterns: run.config.ProtectedPaths}
+	commitURL := run.commitURLTemplate(repoPath)
+
+	var parsing LogParseStats
+	var changes []*ProtectedChange
+	authors := make(map[string]bool)
+	err := run.streamGitLog(gitCommand(repoPath, run.gitLogArgs(run.analyzedRevisions(branchReports), fileFilter)...), run.batchSize, func(commits []CommitRecord) {
+		for _, commit := range commits {
+			paths, _ := protected.matchingPaths(commit)
+			if len(paths) == 0 {
+				continue
+			}
+			change := &ProtectedChange{
+				Hash:    commit.Hash,
+				Date:    commit.Date,
+				Email:   run.config.canonicalEmail(commit.Email),
+				Subject: commit.Subject,
+				Paths:   paths,
+			}
+			if commitURL != "" {
+				change.URL = strings.ReplaceAll(commitURL, COMMIT_URL_PLACEHOLDER, commit.Hash)
+			}
+			authors[change.Email] = true
+			changes = append(changes, change)
+		}
+	}, &parsing)
+	if err != nil {
+		log.Printf("Listing the changes of protected paths failed: %v", err)
+		return
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Date > changes[j].Date
+	})
+	report.Protected = &ProtectedPathReport{Patterns: run.config.ProtectedPaths, Authors: len(authors), Changes: changes}
+}
+
+// commitURLTemplate returns the URL of a commit with COMMIT_URL_PLACEHOLDER in place of the hash: the commitURL of
+// the configuration, or else derived from the remote origin if it is hosted on GitHub, GitLab, Bitbucket or a
+// server with the same URL layout.
+//
+// Returns:
+//   - The URL template, empty if the repository has no remote origin with a host.
+func (run *analysisRun) commitURLTemplate(repoPath string) string {
+	if run.config.CommitURL != "" {
+		return run.config.CommitURL
+	}
+	output, err := gitCommand(repoPath, "remote", "get-url", "origin").Output()
+	if err != nil {
+		return ""
+	}
+	webURL := remoteWebURL(strings.TrimSpace(string(output)))
+	switch {
+	case webURL == "":
+		return ""
+	case strings.Contains(webURL, "gitlab"):
+		return webURL + "/-/commit/" + COMMIT_URL_PLACEHOLDER
+	case strings.Contains(webURL, "bitbucket"):
+		return webURL + "/commits/" + COMMIT_URL_PLACEHOLDER
+	}
+	return webURL + "/commit/" + COMMIT_URL_PLACEHOLDER
+}
+
+// remoteWebURL converts the URL of a remote (HTTPS, SSH or scp-like, e.g. git@github.com:owner/repo.git) to the
+// HTTPS URL of the repository on its host, dropping credentials and ports.
+//
+// Returns:
+//   - The web URL, empty for local paths and file URLs.
+func remoteWebURL(remote string) string {
+	if !strings.Contains(remote, "://") {
+		sshURL, ok := scpLikeURL(remote)
+		if !ok {
+			return ""
+		}
+		remote = sshURL
+	}
+	parsed, err := url.Parse(remote)
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+	repoPath := strings.TrimSuffix(strings.Trim(parsed.Path, "/"), ".git")
+	if repoPath == "" {
+		return ""
+	}
+	return "https://" + parsed.Hostname() + "/" + repoPath
+}
@@ -0,0 +1,91 @@
+package gogitstats
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// CloneOptions control how repositories given by URL are cloned, see PrepareRepositoryWith.
+type CloneOptions struct {
+	// Subdir limits the clone to a sparse checkout of a subdirectory, see cloneSparseRepository
+	Subdir string
+	// Reference is a local repository, or a directory of mirrors (e.g., api.git) found by the name of the cloned
+	// repository, whose objects are borrowed by the clone instead of being downloaded and stored again
+	Reference string
+	// Depth limits the clone to the last commits of each branch (shallow clone), the history is complete if 0
+	Depth int
+	// SingleBranch limits the clone to one branch: Branch, or the default branch of the remote if empty
+	SingleBranch bool
+	Branch       string
+	// Blobless clones without file contents (blobs), git fetches the contents of the analyzed commits on demand,
+	// e.g. if the analysis is limited to a recent period
+	Blobless bool
+	// SSHKey is the private key authenticating to remotes given by SSH URLs, the SSH configuration is used if empty
+	SSHKey string
+	// CredentialHelper is a git credential helper (e.g., store, "!gh auth git-credential") used next to the
+	// configured ones, see gitcredentials(7). Access tokens for HTTPS URLs are taken from the environment, see
+	// GIT_TOKEN_VARIABLE
+	CredentialHelper string
+}
+
+// referenceArgs returns the arguments of `git clone` borrowing the objects of the reference repository of the
+// cloned repository. The clone refers to the object store of the reference (git alternates), which must not be
+// removed or pruned as long as the clone is used.
+//
+// Returns:
+//   - The arguments, nil if no reference is configured or the directory of mirrors holds no mirror of the repository.
+//   - An error if the reference does not exist.
+func (options CloneOptions) referenceArgs(repoURL string) ([]string, error) {
+	if options.Reference == "" {
+		return nil, nil
+	}
+	if info, err := os.Stat(options.Reference); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("reference repository %s does not exist", options.Reference)
+	}
+
+	reference := findReferenceRepository(options.Reference, repoURL)
+	if reference == "" {
+		log.Printf("No reference repository found for %s in: %s", repoURL, options.Reference)
+		return nil, nil
+	}
+	log.Printf("Borrowing objects from reference repository: %s", reference)
+	return []string{"--reference", reference}, nil
+}
+
+// findReferenceRepository returns the reference if it is a repository itself, otherwise the mirror in the
+// directory named after the repository, e.g. "api.git" or "api" for https://example.com/group/api.git.
+func findReferenceRepository(reference string, repoURL string) string {
+	if isLocalRepository(reference) {
+		return reference
+	}
+
+	name := urlRepositoryName(repoURL)
+	for _, candidate := range []string{name + ".git", name} {
+		mirror := filepath.Join(reference, sanitizeDirectoryName(candidate))
+		if isLocalRepository(mirror) {
+			return mirror
+		}
+	}
+	return ""
+}
+
+// urlRepositoryName names a repository given by URL without the suffix ".git", e.g. "api" for
+// https://example.com/group/api.git.
+func urlRepositoryName(repoURL string) string {
+	if sshURL, ok := scpLikeURL(repoURL); ok {
+		repoURL = sshURL
+	}
+	return strings.TrimSuffix(path.Base(strings.TrimRight(strings.ReplaceAll(repoURL, "\\", "/"), "/")), ".git")
+}
+
+// isLocalRepository reports whether the directory is a bare repository or the working tree of a repository.
+func isLocalRepository(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return true
+	}
+	return isBareRepositoryDirectory(dir)
+}
@@ -0,0 +1,103 @@
+package gogitstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// LoadReport reads a report exported in the JSON format (see REPORT_FORMAT_JSON), so it can be rendered again
+// without analyzing the repository, e.g., in another format, language or theme, with fewer sections or columns,
+// or to iterate on the templates.
+//
+// The results are taken from the export as they are, the options of the analyzer only select how they are
+// rendered: format, theme, language, sections, columns and output profile. Sections missing from the export
+// are not rendered.
+//
+// Parameters:
+//   - dataPath: The path of the exported JSON report.
+//
+// Returns:
+//   - The report, which can be rendered or written but not analyzed any further.
+//   - An error if the export could not be read.
+func (analyzer *Analyzer) LoadReport(dataPath string) (*Report, error) {
+	content, err := os.ReadFile(dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report data %s: %w", dataPath, err)
+	}
+
+	var data ReportData
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode report data %s: %w", dataPath, err)
+	}
+	if data.RepoName == "" || data.BranchReports == nil {
+		return nil, fmt.Errorf("report data %s is not a JSON report exported by gogitstats", dataPath)
+	}
+
+	mainBranch := data.MainBranch
+	if mainBranch == "" {
+		mainBranch = analyzer.options.MainBranch
+	}
+
+	run := analyzer.newRun()
+	run.mainBranch = mainBranch
+	run.fileFilter = data.FileFilter
+	if data.GroupBy != "" {
+		// the timelines of stored results have been grouped by the analysis
+		run.groupBy = data.GroupBy
+	}
+
+	return &Report{
+		RepoName:   data.RepoName,
+		MainBranch: mainBranch,
+		FileFilter: data.FileFilter,
+		Branches:   data.BranchReports,
+		StartedOn:  time.Now(),
+		analyzer:   analyzer,
+		run:        run,
+		data:       &data,
+	}, nil
+}
+
+// renderData returns the stored report data with the layout (theme, language, sections, columns, output
+// profile) of the analyzer.
+func (report *Report) renderData() ReportData {
+	data := *report.data
+	columns, sections := report.run.reportLayout()
+	for section := range sections {
+		sections[section] = report.data.Sections[section]
+	}
+	if !sections["timelines"] {
+		columns["timeline"] = false
+	}
+
+	data.Language = report.run.language
+	data.Theme = report.run.theme
+	data.TableTheme = ""
+	if report.run.theme == "dark" {
+		data.TableTheme = "table-dark"
+	}
+	data.Columns = columns
+	data.Sections = sections
+	data.ShowLines = columns["added"] || columns["removed"] || columns["edited"]
+	data.HidePaths = report.data.HidePaths || report.run.profile.HidePaths
+	return data
+}
+
+// renderStored generates the report of stored data (see Analyzer.LoadReport) in the given format.
+func (report *Report) renderStored(format string) (string, error) {
+	data := report.renderData()
+	switch format {
+	case REPORT_FORMAT_HTML:
+		return report.run.generateHTMLReport(data)
+	case REPORT_FORMAT_JSON:
+		return report.run.encodeJSONReport(data)
+	case REPORT_FORMAT_CSV:
+		return report.run.encodeCSVReport(data)
+	case REPORT_FORMAT_MARKDOWN:
+		return report.run.encodeMarkdownReport(data), nil
+	}
+	return "", fmt.Errorf("format '%s' is not supported, expected any of: %s", format, strings.Join(REPORT_FORMATS, ", "))
+}
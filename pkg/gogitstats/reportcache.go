@@ -0,0 +1,153 @@
+package gogitstats
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// repositoryResults holds the repository-level results attached to the main branch report, which are not
+// part of the serialized branch report.
+type repositoryResults struct {
+	Delivery    *DeliveryMetrics
+	Reviews     *ReviewLatency
+	Contention  *ContentionReport
+	Pairing     *PairingReport
+	Signatures  *SignatureReport
+	Leaderboard *Leaderboard
+	Heatmap     *Heatmap
+	FileAge     *FileAgeChurn
+	Totals      *RepositoryTotals
+	Naming      *BranchNamingReport
+	Protected   *ProtectedPathReport
+	Departments []*DepartmentRollup
+}
+
+// ReportCache is the result of the latest analysis of a repository, which is reused (e.g., to render the
+// report in another format) as long as neither the refs of the repository nor the analysis options change.
+type ReportCache struct {
+	Version    int
+	Key        string
+	Branches   map[string]*BranchReport
+	Repository *repositoryResults
+}
+
+// reportCacheable reports whether the result of an analysis depends on the repository and the options alone.
+// Results enriched by APIs, identity providers or the reflog may change without any change of the refs.
+func (analyzer *Analyzer) reportCacheable(run *analysisRun) bool {
+	return analyzer.hosting == nil && analyzer.jira == nil && !run.localActivity && run.config.Identities == nil
+}
+
+// reportCacheKey describes the state of the repository (all refs) and the effective options of the analysis.
+//
+// Options only affecting the rendering (e.g., format, language, columns) or the way the analysis runs
+// (e.g., workers) are not part of the key, so a report can be rendered in other formats from the cache.
+func (analyzer *Analyzer) reportCacheKey(run *analysisRun, repoPath string) (string, error) {
+	refs, err := gitCommand(repoPath, "for-each-ref", "--format=%(refname) %(objectname)").Output()
+	if err != nil {
+		return "", fmt.Errorf("git for-each-ref failed: %w", err)
+	}
+
+	options := analyzer.options
+	options.Format, options.Theme, options.Language, options.Columns, options.Profile = "", "", "", nil, ""
+	options.SortBy, options.Top = "", 0
+	options.Workers, options.BatchSize, options.UseCache = 0, 0, false
+	options.Calendars, options.Insights, options.Offline = false, false, false
+	options.Output, options.Overwrite = "", false
+	options.AttestationSigner, options.Config = nil, nil
+	encodedOptions, err := json.Marshal(options)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode options: %w", err)
+	}
+
+	// the configuration is keyed as applied (e.g., read from a config file), the period by its limits as
+	// given rather than by the resolved timestamps, which move by the second for relative limits (e.g., 6.months)
+	config, err := json.Marshal(run.config)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode configuration: %w", err)
+	}
+
+	hash := sha256.New()
+	for _, part := range []string{string(refs), string(encodedOptions), string(config), strings.Join(run.sections, ","), run.dateRangeKey()} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// reportCacheFilePath returns the location of the report cache of the repository, next to its analysis cache.
+func reportCacheFilePath(repoPath string) (string, error) {
+	cachePath, err := cacheFilePath(repoPath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(cachePath, ".json") + "-report.json", nil
+}
+
+// loadReportCache returns the branch reports of the cached analysis, with the repository-level results attached
+// to the main branch report.
+//
+// Returns:
+//   - The branch reports, nil if the cache is missing, unreadable, outdated or has been written with another key.
+func (run *analysisRun) loadReportCache(cachePath string, key string) map[string]*BranchReport {
+	content, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil
+	}
+
+	var stored ReportCache
+	if err := json.Unmarshal(content, &stored); err != nil || stored.Version != cacheFormatVersion || stored.Key != key || stored.Branches == nil {
+		return nil
+	}
+
+	if mainReport, ok := stored.Branches[run.mainBranch]; ok && stored.Repository != nil {
+		mainReport.Delivery = stored.Repository.Delivery
+		mainReport.Reviews = stored.Repository.Reviews
+		mainReport.Contention = stored.Repository.Contention
+		mainReport.Pairing = stored.Repository.Pairing
+		mainReport.Signatures = stored.Repository.Signatures
+		mainReport.Leaderboard = stored.Repository.Leaderboard
+		mainReport.Heatmap = stored.Repository.Heatmap
+		mainReport.FileAge = stored.Repository.FileAge
+		mainReport.Totals = stored.Repository.Totals
+		mainReport.Naming = stored.Repository.Naming
+		mainReport.Protected = stored.Repository.Protected
+		mainReport.Departments = stored.Repository.Departments
+	}
+	return stored.Branches
+}
+
+// saveReportCache writes the branch reports and the repository-level results of an analysis to the given file.
+func (run *analysisRun) saveReportCache(cachePath string, key string, branchReports map[string]*BranchReport) error {
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(cachePath), err)
+	}
+
+	stored := &ReportCache{Version: cacheFormatVersion, Key: key, Branches: branchReports}
+	if mainReport, ok := branchReports[run.mainBranch]; ok {
+		stored.Repository = &repositoryResults{
+			Delivery:    mainReport.Delivery,
+			Reviews:     mainReport.Reviews,
+			Contention:  mainReport.Contention,
+			Pairing:     mainReport.Pairing,
+			Signatures:  mainReport.Signatures,
+			Leaderboard: mainReport.Leaderboard,
+			Heatmap:     mainReport.Heatmap,
+			FileAge:     mainReport.FileAge,
+			Totals:      mainReport.Totals,
+			Naming:      mainReport.Naming,
+			Protected:   mainReport.Protected,
+			Departments: mainReport.Departments,
+		}
+	}
+
+	content, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode report cache: %w", err)
+	}
+	return os.WriteFile(cachePath, content, 0644)
+}
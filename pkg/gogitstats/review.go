@@ -0,0 +1,109 @@
+package gogitstats
+
+import (
+	"log"
+	"sort"
+)
+
+type ContributorLatency struct {
+	Author      string
+	MergedCount int
+	MedianHours float64
+	hours       []float64
+}
+
+type PeriodLatency struct {
+	Period      string
+	MergedCount int
+	MedianHours float64
+	hours       []float64
+}
+
+// ReviewLatency summarizes the time from opening a pull/merge request until it is merged.
+type ReviewLatency struct {
+	Provider     string
+	MergedCount  int
+	MedianHours  float64
+	Contributors []*ContributorLatency
+	Periods      []*PeriodLatency
+}
+
+// assessReviewLatency attaches the review-to-merge latency of changes merged into the main branch
+// to the report of the main branch.
+func (run *analysisRun) assessReviewLatency(hosting hostingClient, branchReports map[string]*BranchReport) {
+	report, ok := branchReports[run.mainBranch]
+	if !ok {
+		return
+	}
+	report.Reviews = nil
+
+	changes, err := hosting.mergedChanges(run.mainBranch)
+	if err != nil {
+		log.Printf("Fetching merged changes from %s failed: %v", hosting.provider(), err)
+		return
+	}
+	log.Printf("Fetched %d merged changes from %s", len(changes), hosting.provider())
+
+	report.Reviews = run.measureReviewLatency(hosting.provider(), changes)
+}
+
+// measureReviewLatency computes the median review-to-merge latency per contributor and per period (of the merge).
+func (run *analysisRun) measureReviewLatency(provider string, changes []*MergedChange) *ReviewLatency {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	latency := &ReviewLatency{Provider: provider, MergedCount: len(changes)}
+	contributors := make(map[string]*ContributorLatency)
+	periods := make(map[string]*PeriodLatency)
+	var all []float64
+
+	for _, change := range changes {
+		hours := change.MergedAt.Sub(change.OpenedAt).Hours()
+		all = append(all, hours)
+
+		contributor, ok := contributors[change.Author]
+		if !ok {
+			contributor = &ContributorLatency{Author: change.Author}
+			contributors[change.Author] = contributor
+		}
+		contributor.MergedCount++
+		contributor.hours = append(contributor.hours, hours)
+
+		key, ok := run.timelinePeriod(change.MergedAt.Format("2006-01-02"))
+		if !ok {
+			continue
+		}
+		period, ok := periods[key]
+		if !ok {
+			period = &PeriodLatency{Period: key}
+			periods[key] = period
+		}
+		period.MergedCount++
+		period.hours = append(period.hours, hours)
+	}
+
+	latency.MedianHours = median(all)
+	for _, contributor := range contributors {
+		contributor.MedianHours = median(contributor.hours)
+		latency.Contributors = append(latency.Contributors, contributor)
+	}
+	sort.Slice(latency.Contributors, func(i, j int) bool {
+		if latency.Contributors[i].MedianHours != latency.Contributors[j].MedianHours {
+			return latency.Contributors[i].MedianHours > latency.Contributors[j].MedianHours
+		}
+		return latency.Contributors[i].Author < latency.Contributors[j].Author
+	})
+
+	keys := make([]string, 0, len(periods))
+	for key := range periods {
+		keys = append(keys, key)
+	}
+	for _, key := range sortPeriods(keys) {
+		period := periods[key]
+		period.MedianHours = median(period.hours)
+		latency.Periods = append(latency.Periods, period)
+	}
+
+	return latency
+}
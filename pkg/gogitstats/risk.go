@@ -0,0 +1,109 @@
+package gogitstats
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+type UnmergedWork struct {
+	Email       string
+	CommitCount int
+}
+
+type BranchDivergence struct {
+	MergeBase          string
+	MergeBaseDate      string
+	CommitsAhead       int
+	DaysSinceMergeBase int
+	UnmergedAuthors    []*UnmergedWork
+	AtRisk             bool
+}
+
+// assessBranchRisk determines for every branch how far it diverged from the main branch.
+//
+// A branch is flagged as integration risk, if it has unmerged work and either the number of commits
+// ahead of the main branch or the days since the merge-base exceed the configured thresholds.
+func (run *analysisRun) assessBranchRisk(repoPath string, branchReports map[string]*BranchReport) {
+	for branchName, report := range branchReports {
+		report.Divergence = nil
+		if branchName == run.mainBranch {
+			continue
+		}
+
+		divergence, err := run.measureDivergence(repoPath, branchName)
+		if err != nil {
+			log.Printf("Measuring divergence of branch '%s' failed: %v", branchName, err)
+			continue
+		}
+
+		divergence.AtRisk = divergence.CommitsAhead > 0 &&
+			(divergence.CommitsAhead > run.riskMaxCommits || divergence.DaysSinceMergeBase > run.riskMaxDays)
+		report.Divergence = divergence
+	}
+}
+
+// measureDivergence compares a branch with the main branch.
+func (run *analysisRun) measureDivergence(repoPath string, branchName string) (*BranchDivergence, error) {
+	cmdMergeBase := gitCommand(repoPath, "merge-base", run.branchRevision(run.mainBranch), run.branchRevision(branchName))
+	output, err := cmdMergeBase.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git merge-base failed: %w, output: %s", err, output)
+	}
+	divergence := &BranchDivergence{MergeBase: strings.TrimSpace(string(output))}
+
+	cmdDate := gitCommand(repoPath, "show", "-s", "--format=%cI", divergence.MergeBase)
+	output, err = cmdDate.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git show failed: %w, output: %s", err, output)
+	}
+	mergeBaseDate, err := time.Parse(time.RFC3339, strings.TrimSpace(string(output)))
+	if err != nil {
+		return nil, fmt.Errorf("unexpected commit date '%s': %w", strings.TrimSpace(string(output)), err)
+	}
+	divergence.MergeBaseDate = mergeBaseDate.Format("2006-01-02")
+	divergence.DaysSinceMergeBase = int(time.Since(mergeBaseDate).Hours() / 24)
+
+	cmdAuthors := gitCommand(repoPath, "log", "--format=%ae", fmt.Sprintf("%s..%s", run.branchRevision(run.mainBranch), run.branchRevision(branchName)))
+	output, err = cmdAuthors.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w, output: %s", err, output)
+	}
+
+	authors := make(map[string]*UnmergedWork)
+	for _, email := range strings.Split(string(output), "\n") {
+		email = strings.TrimSpace(email)
+		if email == "" {
+			continue
+		}
+		email = run.config.canonicalEmail(email)
+		divergence.CommitsAhead++
+		if _, ok := authors[email]; !ok {
+			authors[email] = &UnmergedWork{Email: email}
+			divergence.UnmergedAuthors = append(divergence.UnmergedAuthors, authors[email])
+		}
+		authors[email].CommitCount++
+	}
+
+	sort.Slice(divergence.UnmergedAuthors, func(i, j int) bool {
+		return divergence.UnmergedAuthors[i].CommitCount > divergence.UnmergedAuthors[j].CommitCount
+	})
+
+	return divergence, nil
+}
+
+// branchesAtRisk returns the reports of the branches flagged as integration risk, most diverged first.
+func branchesAtRisk(branchReports map[string]*BranchReport) []*BranchReport {
+	var atRisk []*BranchReport
+	for _, report := range branchReports {
+		if report.Divergence != nil && report.Divergence.AtRisk {
+			atRisk = append(atRisk, report)
+		}
+	}
+	sort.Slice(atRisk, func(i, j int) bool {
+		return atRisk[i].Divergence.DaysSinceMergeBase > atRisk[j].Divergence.DaysSinceMergeBase
+	})
+	return atRisk
+}
@@ -0,0 +1,50 @@
+package gogitstats
+
+import (
+	"strconv"
+	"strings"
+)
+
+// cloneArgs returns the arguments of `git clone` limiting the history, the branches and the objects of the clone.
+//
+// A shallow clone (Depth) of all branches needs `--no-single-branch`, since git clones only the default branch
+// with `--depth` otherwise. Sparse clones (Subdir) are blobless already.
+func (options CloneOptions) cloneArgs() []string {
+	var args []string
+	if options.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(options.Depth))
+		if !options.SingleBranch {
+			args = append(args, "--no-single-branch")
+		}
+	}
+	if options.SingleBranch {
+		args = append(args, "--single-branch")
+		if options.Branch != "" {
+			args = append(args, "--branch", options.Branch)
+		}
+	}
+	if options.Blobless && options.Subdir == "" {
+		args = append(args, "--filter=blob:none")
+	}
+	return args
+}
+
+// cloneSuffix tells clones with limited history or branches apart from complete clones of the repository (e.g.,
+// "@depth-100" or "@single-main"), so they are not reused by analyses expecting the complete repository. Blobless
+// clones are complete, git fetches missing contents on demand.
+func (options CloneOptions) cloneSuffix() string {
+	var suffix []string
+	if options.Subdir != "" {
+		suffix = append(suffix, sanitizeDirectoryName(options.Subdir))
+	}
+	if options.Depth > 0 {
+		suffix = append(suffix, "depth-"+strconv.Itoa(options.Depth))
+	}
+	if options.SingleBranch {
+		suffix = append(suffix, sanitizeDirectoryName(strings.TrimSuffix("single-"+options.Branch, "-")))
+	}
+	if len(suffix) == 0 {
+		return ""
+	}
+	return "@" + strings.Join(suffix, "@")
+}
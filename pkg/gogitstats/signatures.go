@@ -0,0 +1,161 @@
+package gogitstats
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const SIGNATURE_UNSIGNED = "unsigned"
+const SIGNATURE_VALID = "valid"
+const SIGNATURE_INVALID = "invalid"
+const SIGNATURE_UNKNOWN_KEY = "unknown key"
+const SIGNATURE_UNVERIFIED = "unverified"
+
+// sshSignaturePattern matches the output of git verifying SSH signatures.
+var sshSignaturePattern = regexp.MustCompile(`Good "git" signature for (\S+) with (\S+) key (\S+)`)
+
+type TagSignature struct {
+	Name     string
+	Tagger   string
+	Date     string
+	Status   string
+	Identity string // signer (user ID or principal) and key of verified signatures
+}
+
+// SignatureIdentity is a signer with the number of tags carrying a valid signature of the signer.
+type SignatureIdentity struct {
+	Identity string
+	TagCount int
+}
+
+type SignatureReport struct {
+	Tags        []*TagSignature
+	SignedCount int
+	ValidCount  int
+	Identities  []*SignatureIdentity
+}
+
+// assessTagSignatures verifies the signatures of all annotated tags, the report is attached to the main branch report.
+func (run *analysisRun) assessTagSignatures(repoPath string, branchReports map[string]*BranchReport) {
+	report, ok := branchReports[run.mainBranch]
+	if !ok {
+		return
+	}
+
+	signatures, err := run.verifyTagSignatures(repoPath)
+	if err != nil {
+		log.Printf("Verifying tag signatures failed: %v", err)
+		report.Signatures = nil
+		return
+	}
+	report.Signatures = signatures
+}
+
+// verifyTagSignatures reports which annotated tags are signed and verifies the signatures with `git verify-tag`.
+//
+// Verification uses the trust configuration of the user (gpg keyring, gpg.ssh.allowedSignersFile),
+// signatures of keys which are not trusted there are reported as SIGNATURE_UNKNOWN_KEY.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//
+// Returns:
+//   - The signatures of all annotated tags, newest first; nil if the repository has no annotated tags.
+//   - An error if git failed.
+func (run *analysisRun) verifyTagSignatures(repoPath string) (*SignatureReport, error) {
+	cmdTags := gitCommand(repoPath, "for-each-ref", "--sort=-taggerdate",
+		"--format=%(objecttype)%1f%(refname:short)%1f%(taggeremail)%1f%(taggerdate:iso-strict)%1f%(if)%(contents:signature)%(then)signed%(end)", "refs/tags")
+	output, err := cmdTags.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref failed: %w, output: %s", err, output)
+	}
+
+	report := &SignatureReport{}
+	identities := make(map[string]*SignatureIdentity)
+	for _, line := range strings.Split(string(output), "\n") {
+		parts := strings.Split(line, "\x1f")
+		if len(parts) != 5 || parts[0] != "tag" {
+			continue
+		}
+
+		tag := &TagSignature{
+			Name:   parts[1],
+			Tagger: run.config.canonicalEmail(strings.Trim(parts[2], "<>")),
+			Status: SIGNATURE_UNSIGNED,
+		}
+		if taggedAt, err := time.Parse(time.RFC3339, parts[3]); err == nil {
+			tag.Date = taggedAt.Format("2006-01-02")
+		}
+		report.Tags = append(report.Tags, tag)
+		if parts[4] != "signed" {
+			continue
+		}
+
+		report.SignedCount++
+		tag.Status, tag.Identity = verifyTag(repoPath, tag.Name)
+		if tag.Status == SIGNATURE_VALID {
+			report.ValidCount++
+			identity, ok := identities[tag.Identity]
+			if !ok {
+				identity = &SignatureIdentity{Identity: tag.Identity}
+				identities[tag.Identity] = identity
+				report.Identities = append(report.Identities, identity)
+			}
+			identity.TagCount++
+		}
+	}
+
+	if len(report.Tags) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(report.Identities, func(i, j int) bool {
+		return report.Identities[i].TagCount > report.Identities[j].TagCount
+	})
+	return report, nil
+}
+
+// verifyTag verifies the signature of a signed tag.
+//
+// Returns:
+//   - The status of the signature.
+//   - The signer and key of a valid signature.
+func verifyTag(repoPath string, tagName string) (string, string) {
+	cmdVerify := gitVerifyCommand(repoPath, "verify-tag", "--raw", "refs/tags/"+tagName)
+	output, err := cmdVerify.CombinedOutput()
+
+	var status, identity string
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 3 && fields[0] == "[GNUPG:]" && fields[1] == "GOODSIG":
+			status, identity = SIGNATURE_VALID, fmt.Sprintf("%s (%s)", strings.Join(fields[3:], " "), fields[2])
+		case len(fields) >= 2 && fields[0] == "[GNUPG:]" && (fields[1] == "BADSIG" || fields[1] == "EXPKEYSIG" || fields[1] == "REVKEYSIG"):
+			status = SIGNATURE_INVALID
+		case len(fields) >= 2 && fields[0] == "[GNUPG:]" && (fields[1] == "ERRSIG" || fields[1] == "NO_PUBKEY"):
+			if status == "" {
+				status = SIGNATURE_UNKNOWN_KEY
+			}
+		case sshSignaturePattern.MatchString(line):
+			match := sshSignaturePattern.FindStringSubmatch(line)
+			status, identity = SIGNATURE_VALID, fmt.Sprintf("%s (%s %s)", match[1], match[2], match[3])
+		case strings.Contains(line, "No principal matched") || strings.Contains(line, "allowedSignersFile needs to be configured"):
+			status = SIGNATURE_UNKNOWN_KEY
+		}
+	}
+
+	// git exits with an error for invalid signatures and signatures it could not check
+	if err != nil && status == SIGNATURE_VALID {
+		status = SIGNATURE_INVALID
+	}
+	if status == "" {
+		status = SIGNATURE_UNVERIFIED
+		if err == nil {
+			status = SIGNATURE_VALID
+		}
+	}
+	return status, identity
+}
@@ -0,0 +1,193 @@
+package gogitstats
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// SNAPSHOT_LATEST names the symbolic link to the latest snapshot in the snapshot directory, see Options.Snapshots.
+const SNAPSHOT_LATEST = "latest"
+
+// SNAPSHOT_INDEX names the page listing the snapshots in the snapshot directory.
+const SNAPSHOT_INDEX = "index.html"
+
+// snapshotDateLayout names the directory of a snapshot by its day.
+const snapshotDateLayout = "2006-01-02"
+
+// snapshotIndexTemplate lists the snapshots and their reports, latest first.
+const snapshotIndexTemplate = `<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<title>{{t "Snapshots"}}</title>
+</head>
+<body>
+<h1>{{t "Snapshots"}}</h1>
+{{with .Latest}}<p><a href="{{.}}/">{{t "Latest snapshot"}}: {{.}}</a></p>
+{{end}}<ul>
+{{range .Snapshots}}{{$snapshot := .Name}}<li>{{.Name}}{{range .Files}} &middot; <a href="{{$snapshot}}/{{.}}">{{.}}</a>{{end}}</li>
+{{end}}</ul>
+</body>
+</html>`
+
+// snapshotEntry is a snapshot listed by the index page.
+type snapshotEntry struct {
+	Name  string
+	Files []string
+}
+
+// snapshotDirectory returns the directory of the snapshot of the given day.
+func snapshotDirectory(snapshots string, takenOn time.Time) string {
+	return filepath.Join(snapshots, takenOn.Format(snapshotDateLayout))
+}
+
+// snapshotStagingDirectory returns the directory the reports of a run are written to before they replace the snapshot
+// (see Analyzer.FinishSnapshot), hidden and named by the process, so it is neither listed as a snapshot nor shared.
+func snapshotStagingDirectory(snapshot string) string {
+	return filepath.Join(filepath.Dir(snapshot), "."+filepath.Base(snapshot)+"."+strconv.Itoa(os.Getpid()))
+}
+
+// replaceSnapshot moves the staging directory of a run to the snapshot, an earlier snapshot of the day is removed.
+func replaceSnapshot(staging string, snapshot string) error {
+	if _, err := os.Stat(staging); os.IsNotExist(err) {
+		// no report has been written, an earlier snapshot of the day is kept
+		return nil
+	}
+	replaced := staging + ".replaced"
+	if err := os.Rename(snapshot, replaced); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace snapshot %s: %w", snapshot, err)
+	}
+	if err := os.Rename(staging, snapshot); err != nil {
+		return fmt.Errorf("failed to move snapshot %s to %s: %w", staging, snapshot, err)
+	}
+	if err := os.RemoveAll(replaced); err != nil {
+		return fmt.Errorf("failed to remove replaced snapshot %s: %w", replaced, err)
+	}
+	return nil
+}
+
+// listSnapshots returns the names of the snapshot directories (named by day), latest first.
+func listSnapshots(snapshots string) ([]string, error) {
+	entries, err := os.ReadDir(snapshots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", snapshots, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if _, err := time.Parse(snapshotDateLayout, entry.Name()); err == nil && entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	return names, nil
+}
+
+// retainedSnapshots selects the snapshots kept by the retention policy: the keepLast latest ones and the last
+// snapshot of each of the keepMonthly latest months, the latest snapshot is always kept.
+//
+// Parameters:
+//   - names: The snapshots named by day, latest first.
+func retainedSnapshots(names []string, keepLast int, keepMonthly int) map[string]bool {
+	retained := make(map[string]bool)
+	months := make(map[string]bool)
+	for i, name := range names {
+		if i < max(keepLast, 1) {
+			retained[name] = true
+		}
+		month := name[:len("2006-01")]
+		if !months[month] && len(months) < keepMonthly {
+			months[month] = true
+			retained[name] = true
+		}
+	}
+	return retained
+}
+
+// FinishSnapshot completes the snapshot written by the run (see Options.Snapshots): it replaces an earlier snapshot
+// of the day, snapshots beyond the retention policy are removed, the symbolic link SNAPSHOT_LATEST points to the latest snapshot and the page SNAPSHOT_INDEX
+// lists the snapshots, which links the latest snapshot where symbolic links are not supported.
+//
+// Returns:
+//   - An error if snapshots mode is disabled or the snapshot directory could not be updated.
+func (analyzer *Analyzer) FinishSnapshot() error {
+	snapshots := analyzer.options.Snapshots
+	if snapshots == "" {
+		return fmt.Errorf("snapshots are not enabled")
+	}
+	if err := replaceSnapshot(snapshotStagingDirectory(analyzer.snapshot), analyzer.snapshot); err != nil {
+		return err
+	}
+	names, err := listSnapshots(snapshots)
+	if err != nil {
+		return err
+	}
+
+	retained := retainedSnapshots(names, analyzer.options.SnapshotKeepLast, analyzer.options.SnapshotKeepMonthly)
+	var kept []*snapshotEntry
+	for _, name := range names {
+		directory := filepath.Join(snapshots, name)
+		if !retained[name] {
+			if err := os.RemoveAll(directory); err != nil {
+				return fmt.Errorf("failed to remove snapshot %s: %w", directory, err)
+			}
+			log.Printf("Snapshot removed by the retention policy: %s", directory)
+			continue
+		}
+		entries, err := os.ReadDir(directory)
+		if err != nil {
+			return fmt.Errorf("failed to read directory %s: %w", directory, err)
+		}
+		snapshot := &snapshotEntry{Name: name}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				snapshot.Files = append(snapshot.Files, entry.Name())
+			}
+		}
+		kept = append(kept, snapshot)
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+
+	latest := filepath.Join(snapshots, SNAPSHOT_LATEST)
+	if info, err := os.Lstat(latest); err == nil && info.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("%s is not a symbolic link, remove it to link the latest snapshot", latest)
+	}
+	if err := os.Remove(latest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to update %s: %w", latest, err)
+	}
+	if err := os.Symlink(kept[0].Name, latest); err != nil {
+		// e.g., on Windows without the privilege, the index page links the latest snapshot anyway
+		log.Printf("Failed to link the latest snapshot, see %s instead: %v", SNAPSHOT_INDEX, err)
+	}
+
+	index, err := template.New("snapshots").Funcs(template.FuncMap{
+		"t": func(message string) string {
+			if translated, ok := analyzer.messages[message]; ok && translated != "" {
+				return translated
+			}
+			return message
+		},
+	}).Parse(snapshotIndexTemplate)
+	if err != nil {
+		return err
+	}
+	var page bytes.Buffer
+	data := map[string]interface{}{"Language": analyzer.options.Language, "Latest": kept[0].Name, "Snapshots": kept}
+	if err := index.Execute(&page, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", SNAPSHOT_INDEX, err)
+	}
+	indexPath := filepath.Join(snapshots, SNAPSHOT_INDEX)
+	if err := os.WriteFile(indexPath, page.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", indexPath, err)
+	}
+	log.Printf("Snapshot %s completed, %d snapshots kept: %s", kept[0].Name, len(kept), indexPath)
+	return nil
+}
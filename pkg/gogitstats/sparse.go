@@ -0,0 +1,66 @@
+package gogitstats
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// normalizeSubdir validates a subdirectory of a repository (see Options.Subdir) and returns it slash separated,
+// without leading or trailing slashes.
+func normalizeSubdir(subdir string) (string, error) {
+	normalized := path.Clean(strings.Trim(strings.ReplaceAll(subdir, "\\", "/"), "/"))
+	if normalized == "." || normalized == ".." || strings.HasPrefix(normalized, "../") || strings.HasPrefix(normalized, ":") || strings.HasPrefix(normalized, "-") {
+		return "", fmt.Errorf("subdirectory '%s' is not supported, expected a directory relative to the root of the repository", subdir)
+	}
+	return normalized, nil
+}
+
+// subdirFileFilter limits the file filter (see fileFilterPathspecs) to the subdirectory, e.g. "go,:!vendor/"
+// within "services/billing" becomes "services/billing/*.go,:!services/billing/vendor/", which matches Go files in
+// all directories below except the vendored ones.
+func subdirFileFilter(subdir string, fileFilter string) string {
+	var pathspecs []string
+	included := false
+	for _, pathspec := range fileFilterPathspecs(fileFilter) {
+		magic, pattern := pathspecMagic(pathspec)
+		included = included || !isExcludingPathspec(magic)
+		pathspecs = append(pathspecs, magic+subdir+"/"+strings.TrimPrefix(pattern, "/"))
+	}
+	if !included {
+		// exclusions alone apply to the whole repository
+		pathspecs = append([]string{subdir + "/"}, pathspecs...)
+	}
+	return strings.Join(pathspecs, ",")
+}
+
+// cloneSparseRepository clones a repository limited to a subdirectory: file contents (blobs) are not downloaded by
+// the clone, the checkouts of the branches only hold the subdirectory, and git fetches the contents of the files
+// within the subdirectory on demand while their history is analyzed.
+//
+// Parameters:
+//   - repoURL: The URL of the Git repository to clone.
+//   - localRepoPath: The path of the clone.
+//   - subdir: The subdirectory, relative to the root of the repository.
+//   - cloneArgs: Further arguments of `git clone`, see CloneOptions.cloneArgs and CloneOptions.referenceArgs.
+//
+// Returns:
+//   - An error if the repository could not be cloned.
+func cloneSparseRepository(repoURL string, localRepoPath string, subdir string, cloneArgs []string) error {
+	subdir, err := normalizeSubdir(subdir)
+	if err != nil {
+		return err
+	}
+
+	args := append([]string{"clone", "--filter=blob:none", "--sparse"}, cloneArgs...)
+	cmd := gitRemoteCommand("", repoURL, append(args, repoURL, localRepoPath)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return remoteCommandError("clone repository", err, output)
+	}
+
+	cmd = gitRemoteCommand(localRepoPath, repoURL, "sparse-checkout", "set", subdir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to limit the checkout to %s: %s, output: %s", subdir, err, output)
+	}
+	return nil
+}
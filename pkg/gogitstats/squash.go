@@ -0,0 +1,202 @@
+package gogitstats
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// squashAuthor is an author of the commits squashed into a commit, credited with a share of it.
+type squashAuthor struct {
+	Email  string
+	Shares int // commits of the change authored or co-authored
+}
+
+// loadSquashedAuthors fetches the commits of the changes squashed into the main branch from the hosting platform,
+// so squash commits can be credited to the authors of the squashed commits instead of the committer who merged
+// them (see Options.ReconcileSquash).
+//
+// Changes merged with a merge commit are attributed by git already and skipped, as well as rebased changes: the
+// commit of a change with several commits, whose subject is the subject of the last commit of the change.
+//
+// Returns:
+//   - The authors by squash commit, the changes fetched until then if fetching the commits of a change fails.
+func (run *analysisRun) loadSquashedAuthors(repoPath string, hosting hostingClient) map[string][]squashAuthor {
+	changes, err := hosting.mergedChanges(run.mainBranch)
+	if err != nil {
+		log.Printf("Fetching merged changes from %s failed: %v", hosting.provider(), err)
+		return nil
+	}
+
+	var hashes []string
+	for _, change := range changes {
+		if change.MergeCommit != "" {
+			hashes = append(hashes, change.MergeCommit)
+		}
+	}
+	subjects, err := listSquashCandidates(repoPath, hashes)
+	if err != nil {
+		log.Printf("Reading merge commits failed: %v", err)
+		return nil
+	}
+
+	authors := make(map[string][]squashAuthor)
+	for _, change := range changes {
+		subject, ok := subjects[change.MergeCommit]
+		if !ok {
+			continue
+		}
+		commits, err := hosting.changeCommits(change)
+		if err != nil {
+			log.Printf("Fetching commits of merged changes from %s failed: %v", hosting.provider(), err)
+			break
+		}
+		if len(commits) == 0 || (len(commits) > 1 && commitSubject(commits[len(commits)-1].Message) == subject) {
+			continue
+		}
+		authors[change.MergeCommit] = changeAuthors(commits)
+	}
+	log.Printf("Squash commits of %d merged changes are credited to the authors of their commits", len(authors))
+	return authors
+}
+
+// listSquashCandidates returns the subjects of the given commits present in the repository with a single parent,
+// i.e. squashed or rebased changes.
+func listSquashCandidates(repoPath string, hashes []string) (map[string]string, error) {
+	subjects := make(map[string]string)
+	if len(hashes) == 0 {
+		return subjects, nil
+	}
+
+	// commits missing in the clone (e.g., merged before a shallow fetch) would fail git log
+	cmd := gitCommand(repoPath, "cat-file", "--batch-check=%(objectname) %(objecttype)")
+	cmd.Stdin = strings.NewReader(strings.Join(hashes, "\n") + "\n")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check merge commits: %w", err)
+	}
+	var present []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if hash, objectType, ok := strings.Cut(line, " "); ok && objectType == "commit" {
+			present = append(present, hash)
+		}
+	}
+	if len(present) == 0 {
+		return subjects, nil
+	}
+
+	cmd = gitCommand(repoPath, "log", "--no-walk=unsorted", "--stdin", "--format=%H%x1f%P%x1f%s")
+	cmd.Stdin = strings.NewReader(strings.Join(present, "\n") + "\n")
+	output, err = cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read merge commits: %w", err)
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\x1f")
+		if len(fields) == 3 && len(strings.Fields(fields[1])) == 1 {
+			subjects[fields[0]] = fields[2]
+		}
+	}
+	return subjects, nil
+}
+
+// changeAuthors credits every commit of a change to its author and its co-authors (Co-authored-by trailers).
+func changeAuthors(commits []*ChangeCommit) []squashAuthor {
+	shares := make(map[string]int)
+	for _, commit := range commits {
+		emails := []string{commit.Email}
+		for _, line := range strings.Split(commit.Message, "\n") {
+			name, value, found := strings.Cut(line, ":")
+			if found && strings.EqualFold(strings.TrimSpace(name), "Co-authored-by") {
+				emails = append(emails, trailerEmail(value))
+			}
+		}
+		for _, email := range emails {
+			if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+				shares[email]++
+			}
+		}
+	}
+
+	authors := make([]squashAuthor, 0, len(shares))
+	for email, count := range shares {
+		authors = append(authors, squashAuthor{Email: email, Shares: count})
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Shares != authors[j].Shares {
+			return authors[i].Shares > authors[j].Shares
+		}
+		return authors[i].Email < authors[j].Email
+	})
+	return authors
+}
+
+// commitSubject returns the first line of a commit message.
+func commitSubject(message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(subject)
+}
+
+// reconcileSquashMerges splits squash commits into a commit per author of the squashed commits: each of them is
+// credited with the commit and with the lines in proportion to their shares, so the lines add up to the lines of
+// the squash commit. Other commits are returned as they are.
+func (run *analysisRun) reconcileSquashMerges(commits []CommitRecord) []CommitRecord {
+	if len(run.squashedAuthors) == 0 {
+		return commits
+	}
+
+	reconciled := make([]CommitRecord, 0, len(commits))
+	for _, commit := range commits {
+		authors, ok := run.squashedAuthors[commit.Hash]
+		if !ok {
+			reconciled = append(reconciled, commit)
+			continue
+		}
+
+		total := 0
+		for _, author := range authors {
+			total += author.Shares
+		}
+		cumulative := 0
+		for _, author := range authors {
+			share := commit
+			share.Email = author.Email
+			share.Files = make([]FileChange, len(commit.Files))
+			for i, change := range commit.Files {
+				change.Added = change.Added*(cumulative+author.Shares)/total - change.Added*cumulative/total
+				change.Removed = change.Removed*(cumulative+author.Shares)/total - change.Removed*cumulative/total
+				share.Files[i] = change
+			}
+			cumulative += author.Shares
+			reconciled = append(reconciled, share)
+		}
+	}
+	return reconciled
+}
+
+// squashedAuthorsKey describes the reconciled squash commits for the analysis cache key.
+func (run *analysisRun) squashedAuthorsKey() string {
+	if len(run.squashedAuthors) == 0 {
+		return ""
+	}
+	hashes := make([]string, 0, len(run.squashedAuthors))
+	for hash := range run.squashedAuthors {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	digest := sha256.New()
+	for _, hash := range hashes {
+		fmt.Fprintf(digest, "%s:", hash)
+		for _, author := range run.squashedAuthors[hash] {
+			fmt.Fprintf(digest, "%s=%d,", author.Email, author.Shares)
+		}
+		fmt.Fprintln(digest)
+	}
+	return hex.EncodeToString(digest.Sum(nil))
+}
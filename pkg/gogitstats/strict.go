@@ -0,0 +1,12 @@
+package gogitstats
+
+import "fmt"
+
+// unparsableLogLine reports a line of git log output which could not be parsed: an error in strict mode,
+// otherwise nil and the line is skipped.
+func (run *analysisRun) unparsableLogLine(line string) error {
+	if !run.strict {
+		return nil
+	}
+	return fmt.Errorf("unparsable git log line (strict mode): %q", line)
+}
@@ -0,0 +1,124 @@
+package gogitstats
+
+import (
+	"sort"
+)
+
+// maxTopMovers limits the number of contributors listed as top movers.
+const maxTopMovers = 5
+
+// TopMover is a contributor whose activity changed the most between the last two periods.
+type TopMover struct {
+	Email    string
+	Previous int
+	Current  int
+	Delta    int
+}
+
+// ExecutiveSummary holds the headline numbers shown ahead of the detailed sections.
+type ExecutiveSummary struct {
+	BranchCount      int
+	ContributorCount int
+	CommitCount      int
+	LinesEdited      int
+	CurrentPeriod    string
+	PreviousPeriod   string
+	TopMovers        []*TopMover
+	// risk flags
+	BranchesAtRisk     int
+	MissingBackports   int
+	WithoutTicket      int
+	TicketPolicyActive bool
+	UnverifiedTags     int // annotated tags without valid signature, set if signatures are verified
+	NamingViolations   int // branches violating the branch naming policy, set if a policy is configured
+	ReviewMedianHours  float64
+	ReviewsAvailable   bool
+}
+
+// buildExecutiveSummary condenses the branch reports into headline numbers, top movers and risk flags.
+//
+// Commits of branches other than the main branch are counted since their merge-base, so the
+// totals do not count commits of the main branch twice. Commits shared by other branches are
+// counted once if the repository totals are available, see RepositoryTotals.
+func (run *analysisRun) buildExecutiveSummary(branchReports map[string]*BranchReport) *ExecutiveSummary {
+	summary := &ExecutiveSummary{BranchCount: len(branchReports)}
+
+	contributors := make(map[string]bool)
+	commitsByPeriod := make(map[string]map[string]int) // Period: Email: commit count
+	for _, report := range branchReports {
+		for email, contribution := range report.Contributions {
+			contributors[email] = true
+			summary.CommitCount += contribution.CommitCount
+			summary.LinesEdited += contribution.LinesEdited
+			summary.WithoutTicket += contribution.CommitsWithoutTicket
+			for period, count := range contribution.ContributionTimeline {
+				if commitsByPeriod[period] == nil {
+					commitsByPeriod[period] = make(map[string]int)
+				}
+				commitsByPeriod[period][email] += count
+			}
+		}
+
+		if report.Divergence != nil && report.Divergence.AtRisk {
+			summary.BranchesAtRisk++
+		}
+		if report.Backports != nil {
+			summary.MissingBackports += len(report.Backports.Fixes) - report.Backports.BackportedCount
+		}
+		if report.Signatures != nil {
+			summary.UnverifiedTags = len(report.Signatures.Tags) - report.Signatures.ValidCount
+		}
+		if report.Naming != nil {
+			summary.NamingViolations = len(report.Naming.Violations)
+		}
+		if report.Reviews != nil {
+			summary.ReviewsAvailable = true
+			summary.ReviewMedianHours = report.Reviews.MedianHours
+		}
+	}
+	summary.ContributorCount = len(contributors)
+	if mainReport, ok := branchReports[run.mainBranch]; ok && mainReport.Totals != nil {
+		summary.CommitCount = mainReport.Totals.CommitCount
+		summary.LinesEdited = mainReport.Totals.LinesEdited
+	}
+	summary.TicketPolicyActive = run.config.TicketPolicy != nil
+
+	periods := make([]string, 0, len(commitsByPeriod))
+	for period := range commitsByPeriod {
+		periods = append(periods, period)
+	}
+	periods = sortPeriods(periods)
+	if len(periods) < 2 {
+		return summary
+	}
+
+	summary.CurrentPeriod = periods[len(periods)-1]
+	summary.PreviousPeriod = periods[len(periods)-2]
+	current, previous := commitsByPeriod[summary.CurrentPeriod], commitsByPeriod[summary.PreviousPeriod]
+
+	for email := range contributors {
+		mover := &TopMover{Email: email, Previous: previous[email], Current: current[email]}
+		mover.Delta = mover.Current - mover.Previous
+		if mover.Delta != 0 {
+			summary.TopMovers = append(summary.TopMovers, mover)
+		}
+	}
+	sort.Slice(summary.TopMovers, func(i, j int) bool {
+		a, b := summary.TopMovers[i].Delta, summary.TopMovers[j].Delta
+		if a < 0 {
+			a = -a
+		}
+		if b < 0 {
+			b = -b
+		}
+		if a != b {
+			return a > b
+		}
+		return summary.TopMovers[i].Email < summary.TopMovers[j].Email
+	})
+	if len(summary.TopMovers) > maxTopMovers {
+		summary.TopMovers = summary.TopMovers[:maxTopMovers]
+	}
+
+	return summary
+}
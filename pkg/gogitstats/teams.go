@@ -0,0 +1,119 @@
+package gogitstats
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UNASSIGNED_TEAM collects the contributors the team mapping does not list.
+const UNASSIGNED_TEAM = "Unassigned"
+
+// TeamRollup sums the contributions of the members of a team.
+type TeamRollup struct {
+	Team         string
+	Contributors []string
+	CommitCount  int
+	LinesAdded   int
+	LinesRemoved int
+	LinesEdited  int
+	Timeline     map[string]int // Period: commits
+}
+
+// loadTeams reads the YAML (or JSON) file located at teamsPath mapping emails to team names, e.g.
+// "jane@example.com: Payments".
+//
+// Returns:
+//   - The mappings from lower case emails to team names.
+//   - An error if the file could not be read or parsed, or lists an email or team name empty.
+func loadTeams(teamsPath string) (map[string]string, error) {
+	content, err := os.ReadFile(teamsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read teams file %s: %w", teamsPath, err)
+	}
+	var entries map[string]string
+	if err := yaml.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse teams file %s: %w", teamsPath, err)
+	}
+
+	teams := make(map[string]string, len(entries))
+	for email, team := range entries {
+		email, team = strings.TrimSpace(email), strings.TrimSpace(team)
+		if email == "" || team == "" {
+			return nil, fmt.Errorf("invalid entry in teams file %s: both the email and the team must be set", teamsPath)
+		}
+		teams[strings.ToLower(email)] = team
+	}
+	return teams, nil
+}
+
+// rollupTeams sums the given contributions (of all branches, or the repository totals counting each commit
+// once) by the team of their author, contributors the mapping does not list are collected in UNASSIGNED_TEAM.
+//
+// Parameters:
+//   - contributions: The contributions, reported by canonical email.
+//   - teams: The mappings from lower case emails to team names, see loadTeams.
+//
+// Returns:
+//   - The teams ordered by commit count, descending, nil if there is no mapping.
+func rollupTeams(contributions []*UserContribution, teams map[string]string) []*TeamRollup {
+	if len(teams) == 0 {
+		return nil
+	}
+
+	byName := make(map[string]*TeamRollup)
+	var rollups []*TeamRollup
+	for _, contribution := range contributions {
+		name, ok := teams[strings.ToLower(contribution.Email)]
+		if !ok {
+			name = UNASSIGNED_TEAM
+		}
+
+		team, ok := byName[name]
+		if !ok {
+			team = &TeamRollup{Team: name, Timeline: make(map[string]int)}
+			byName[name] = team
+			rollups = append(rollups, team)
+		}
+		if !slices.Contains(team.Contributors, contribution.Email) {
+			team.Contributors = append(team.Contributors, contribution.Email)
+		}
+		team.CommitCount += contribution.CommitCount
+		team.LinesAdded += contribution.LinesAdded
+		team.LinesRemoved += contribution.LinesRemoved
+		team.LinesEdited += contribution.LinesEdited
+		for period, count := range contribution.ContributionTimeline {
+			team.Timeline[period] += count
+		}
+	}
+
+	for _, team := range rollups {
+		sort.Strings(team.Contributors)
+	}
+	sort.Slice(rollups, func(i, j int) bool {
+		if rollups[i].CommitCount != rollups[j].CommitCount {
+			return rollups[i].CommitCount > rollups[j].CommitCount
+		}
+		return rollups[i].Team < rollups[j].Team
+	})
+	return rollups
+}
+
+// teamPeriods returns the periods of the timelines of all teams, oldest first.
+func teamPeriods(teams []*TeamRollup) []string {
+	seen := make(map[string]bool)
+	var periods []string
+	for _, team := range teams {
+		for period := range team.Timeline {
+			if !seen[period] {
+				seen[period] = true
+				periods = append(periods, period)
+			}
+		}
+	}
+	return sortPeriods(periods)
+}
@@ -0,0 +1,216 @@
+package gogitstats
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// limits of the commit themes: terms of the word cloud, terms per period or author, periods and authors listed.
+const maxThemeCloudTerms = 40
+const maxThemeTerms = 8
+const maxThemePeriods = 6
+const maxThemeAuthors = 20
+
+// minThemeTermLength is the minimum number of letters of a term, shorter words are rarely meaningful.
+const minThemeTermLength = 3
+
+// conventionalCommitPattern matches the type and optional scope prefixing conventional commit subjects
+// (e.g., "feat(billing)!: "), the scope is kept as a term.
+var conventionalCommitPattern = regexp.MustCompile(`^[a-z]+(?:\(([^)]*)\))?!?:\s*`)
+
+// referencePrefixPattern matches bracketed references prefixing subjects (e.g., "[org/repo#123] "), which name
+// where the work is tracked rather than what it is about.
+var referencePrefixPattern = regexp.MustCompile(`^\[[^\]]*[#/][^\]]*\]\s*`)
+
+// themeStopwords are the words left out of the commit themes: common English words and the boilerplate of
+// generated subjects (e.g., "Merge branch 'x' of ...", "Revert ...").
+var themeStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "into": true, "onto": true, "not": true,
+	"are": true, "was": true, "were": true, "this": true, "that": true, "these": true, "those": true, "its": true,
+	"has": true, "have": true, "had": true, "but": true, "all": true, "any": true, "some": true, "more": true,
+	"less": true, "when": true, "than": true, "then": true, "also": true, "only": true, "via": true, "per": true,
+	"out": true, "off": true, "can": true, "use": true, "now": true, "new": true, "get": true, "set": true,
+	"merge": true, "merged": true, "branch": true, "branches": true, "pull": true, "request": true,
+	"remote": true, "tracking": true, "origin": true, "master": true, "main": true, "revert": true,
+	"reverts": true, "commit": true, "wip": true, "squash": true, "fixup": true, "amend": true,
+}
+
+// addSubjectTerms counts the terms of the subject of a commit in the period of the commit.
+func addSubjectTerms(contribution *UserContribution, commit CommitRecord, period string) {
+	if period == "" {
+		return
+	}
+	terms := subjectTerms(commit.Subject)
+	if len(terms) == 0 {
+		return
+	}
+
+	if contribution.Terms == nil {
+		contribution.Terms = make(map[string]map[string]int)
+	}
+	counts, ok := contribution.Terms[period]
+	if !ok {
+		counts = make(map[string]int)
+		contribution.Terms[period] = counts
+	}
+	for _, term := range terms {
+		counts[term]++
+	}
+}
+
+// subjectTerms splits a commit subject into its distinct lowercase terms, leaving out ticket keys, numbers,
+// hashes, short words and stopwords.
+func subjectTerms(subject string) []string {
+	subject = referencePrefixPattern.ReplaceAllString(subject, "")
+	subject = strings.ToLower(jiraKeyPattern.ReplaceAllString(subject, " "))
+	if match := conventionalCommitPattern.FindStringSubmatch(subject); match != nil {
+		subject = match[1] + " " + subject[len(match[0]):]
+	}
+
+	var terms []string
+	seen := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(subject, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_'
+	}) {
+		word = strings.Trim(word, "-_")
+		if len([]rune(word)) < minThemeTermLength || themeStopwords[word] || seen[word] || !strings.ContainsFunc(word, unicode.IsLetter) || isHexHash(word) {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// isHexHash reports whether a word looks like an abbreviated commit hash (e.g., in "Revert 1a2b3c4").
+func isHexHash(word string) bool {
+	if len(word) < 7 {
+		return false
+	}
+	hasDigit := false
+	for _, r := range word {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+		hasDigit = hasDigit || unicode.IsDigit(r)
+	}
+	return hasDigit
+}
+
+// ThemeTerm is a term of commit subjects and the number of commits using it.
+type ThemeTerm struct {
+	Term  string
+	Count int
+	Size  int // font size of the word cloud from 1 (largest, like Bootstrap's fs-1) to 6
+}
+
+// PeriodThemes are the most frequent terms of the commit subjects of a period.
+type PeriodThemes struct {
+	Period string
+	Terms  []*ThemeTerm
+}
+
+// AuthorThemes are the most frequent terms of the commit subjects of an author.
+type AuthorThemes struct {
+	Email       string
+	CommitCount int
+	Terms       []*ThemeTerm
+}
+
+// CommitThemes give a quick qualitative sense of the work: the terms used most in commit subjects overall (the
+// word cloud), per period and per author.
+type CommitThemes struct {
+	Cloud   []*ThemeTerm    // sorted alphabetically
+	Periods []*PeriodThemes // latest first, at most maxThemePeriods
+	Authors []*AuthorThemes // most commits first, at most maxThemeAuthors
+}
+
+// buildCommitThemes extracts the themes of the given contributions (of all branches, or the repository totals
+// counting each commit once).
+//
+// Returns:
+//   - The themes, nil if no commit subject has a term.
+func buildCommitThemes(contributions []*UserContribution) *CommitThemes {
+	overall := make(map[string]int)
+	byPeriod := make(map[string]map[string]int)
+	byAuthor := make(map[string]map[string]int)
+	commits := make(map[string]int)
+	for _, contribution := range contributions {
+		commits[contribution.Email] += contribution.CommitCount
+		for period, counts := range contribution.Terms {
+			if byPeriod[period] == nil {
+				byPeriod[period] = make(map[string]int)
+			}
+			if byAuthor[contribution.Email] == nil {
+				byAuthor[contribution.Email] = make(map[string]int)
+			}
+			for term, count := range counts {
+				overall[term] += count
+				byPeriod[period][term] += count
+				byAuthor[contribution.Email][term] += count
+			}
+		}
+	}
+	if len(overall) == 0 {
+		return nil
+	}
+
+	themes := &CommitThemes{Cloud: topTerms(overall, maxThemeCloudTerms)}
+	sort.Slice(themes.Cloud, func(i, j int) bool {
+		return themes.Cloud[i].Term < themes.Cloud[j].Term
+	})
+
+	periods := make([]string, 0, len(byPeriod))
+	for period := range byPeriod {
+		periods = append(periods, period)
+	}
+	periods = sortPeriods(periods)
+	for i := len(periods) - 1; i >= 0 && len(themes.Periods) < maxThemePeriods; i-- {
+		themes.Periods = append(themes.Periods, &PeriodThemes{Period: periods[i], Terms: topTerms(byPeriod[periods[i]], maxThemeTerms)})
+	}
+
+	for email, counts := range byAuthor {
+		themes.Authors = append(themes.Authors, &AuthorThemes{Email: email, CommitCount: commits[email], Terms: topTerms(counts, maxThemeTerms)})
+	}
+	sort.Slice(themes.Authors, func(i, j int) bool {
+		if themes.Authors[i].CommitCount != themes.Authors[j].CommitCount {
+			return themes.Authors[i].CommitCount > themes.Authors[j].CommitCount
+		}
+		return themes.Authors[i].Email < themes.Authors[j].Email
+	})
+	if len(themes.Authors) > maxThemeAuthors {
+		themes.Authors = themes.Authors[:maxThemeAuthors]
+	}
+	return themes
+}
+
+// topTerms returns the most frequent terms, sized by the square root of their frequency relative to the most
+// frequent one, so a few dominant terms do not shrink all others to the smallest size.
+func topTerms(counts map[string]int, limit int) []*ThemeTerm {
+	terms := make([]*ThemeTerm, 0, len(counts))
+	for term, count := range counts {
+		terms = append(terms, &ThemeTerm{Term: term, Count: count})
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].Count != terms[j].Count {
+			return terms[i].Count > terms[j].Count
+		}
+		return terms[i].Term < terms[j].Term
+	})
+	if len(terms) > limit {
+		terms = terms[:limit]
+	}
+	for _, term := range terms {
+		term.Size = 6 - int(math.Round(5*math.Sqrt(float64(term.Count)/float64(terms[0].Count))))
+	}
+	return terms
+}
+
+// reportThemes extracts the commit themes of the repository totals, which count each commit once, or else of
+// the contributions to all branches.
+func reportThemes(branchReports map[string]*BranchReport, totals *RepositoryTotals) *CommitThemes {
+	return buildCommitThemes(reportContributions(branchReports, totals))
+}
@@ -0,0 +1,98 @@
+package gogitstats
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+)
+
+// dimensions of the timeline charts of contributors (in the contribution tables) and branches in pixels.
+const contributorChartWidth = 160
+const contributorChartHeight = 32
+const branchChartWidth = 640
+const branchChartHeight = 96
+
+// branchPeriods returns all periods from the first to the last commit on the branch, so the timelines of all
+// contributors of the branch share the same axis.
+func (run *analysisRun) branchPeriods(report *BranchReport) []string {
+	return run.periodRange(sortedPeriods(branchTimeline(report)))
+}
+
+// branchTimeline sums up the commits of all contributors of the branch per period.
+func branchTimeline(report *BranchReport) map[string]int {
+	timeline := make(map[string]int)
+	for _, contribution := range report.Contributions {
+		for period, count := range contribution.ContributionTimeline {
+			timeline[period] += count
+		}
+	}
+	return timeline
+}
+
+// timelineChart renders the commits of a contributor per period as a small inline SVG bar chart.
+func (run *analysisRun) timelineChart(timeline map[string]int, periods []string) template.HTML {
+	return run.renderTimelineChart(timeline, periods, contributorChartWidth, contributorChartHeight, false)
+}
+
+// branchTimelineChart renders the commits to a branch per period as an inline SVG bar chart with axis labels.
+func (run *analysisRun) branchTimelineChart(report *BranchReport) template.HTML {
+	return run.renderTimelineChart(branchTimeline(report), run.branchPeriods(report), branchChartWidth, branchChartHeight, true)
+}
+
+// renderTimelineChart renders a bar per period, each bar names its period and count as tooltip. The chart
+// is labeled with the counts of all active periods for screen readers.
+//
+// Parameters:
+//   - timeline: The commits per period.
+//   - periods: The periods shown, oldest first.
+//   - width: The width of the chart in pixels.
+//   - height: The height of the chart in pixels.
+//   - labeled: Whether the first and the last period and the maximum count are written below the bars.
+func (run *analysisRun) renderTimelineChart(timeline map[string]int, periods []string, width int, height int, labeled bool) template.HTML {
+	if len(periods) == 0 {
+		return ""
+	}
+
+	maxCount := 1
+	var description []string
+	for _, period := range periods {
+		if count := timeline[period]; count > 0 {
+			maxCount = max(maxCount, count)
+			description = append(description, fmt.Sprintf("%s: %d", period, count))
+		}
+	}
+
+	chartHeight := height
+	if labeled {
+		chartHeight = height - 14 // space of the labels
+	}
+	slot := float64(width) / float64(len(periods))
+	gap := min(slot*0.2, 2)
+	// bars of few periods stay narrow
+	barWidth := min(slot-gap, float64(height)/2)
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, `<svg class="timeline-chart" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="%s">`,
+		width, height, width, height, html.EscapeString(strings.Join(description, ", ")))
+	fmt.Fprintf(&svg, `<line x1="0" y1="%d" x2="%d" y2="%d" stroke="currentColor" stroke-opacity="0.3"/>`, chartHeight, width, chartHeight)
+	for i, period := range periods {
+		count := timeline[period]
+		if count == 0 {
+			continue
+		}
+		barHeight := max(float64(count)*float64(chartHeight-1)/float64(maxCount), 1)
+		fmt.Fprintf(&svg, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="currentColor"><title>%s: %d</title></rect>`,
+			slot*float64(i)+gap/2, float64(chartHeight)-barHeight, barWidth, barHeight, html.EscapeString(period), count)
+	}
+	if labeled {
+		fmt.Fprintf(&svg, `<text x="0" y="%d" font-size="11" fill="currentColor">%s</text>`, height-2, html.EscapeString(periods[0]))
+		if len(periods) > 1 {
+			fmt.Fprintf(&svg, `<text x="%d" y="%d" font-size="11" fill="currentColor" text-anchor="end">%s</text>`, width, height-2, html.EscapeString(periods[len(periods)-1]))
+		}
+		fmt.Fprintf(&svg, `<text x="%d" y="11" font-size="11" fill="currentColor" text-anchor="end">%s</text>`, width, html.EscapeString(run.translate("max. %d", maxCount)))
+	}
+	svg.WriteString(`</svg>`)
+
+	return template.HTML(svg.String())
+}
@@ -0,0 +1,86 @@
+package gogitstats
+
+import (
+	"log"
+	"strings"
+)
+
+// RepositoryTotals are the contributions to all analyzed branches with each commit counted once, unlike the
+// branch tables, which count a commit on every branch containing it (e.g., branches based on other branches).
+type RepositoryTotals struct {
+	Branches      int
+	CommitCount   int // distinct commits
+	LinesEdited   int
+	BranchCommits int // commits summed over the branch tables
+	Contributions map[string]*UserContribution
+}
+
+// assessRepositoryTotals aggregates the commits of all analyzed branches, deduplicated by hash, into the
+// repository totals, which are attached to the main branch report.
+//
+// Parameters:
+//   - repoPath: The path to the Git repository.
+//   - branchReports: The branch reports.
+//   - fileFilter: The pathspec limiting the commits and files, ignored if empty.
+func (run *analysisRun) assessRepositoryTotals(repoPath string, branchReports map[string]*BranchReport, fileFilter string) {
+	report, ok := branchReports[run.mainBranch]
+	if !ok {
+		return
+	}
+
+	// the main branch is analyzed in full, so the tips of all branches reach the commits of every branch table
+	totals := &RepositoryTotals{Branches: len(branchReports)}
+	for _, branchReport := range branchReports {
+		for _, contribution := range branchReport.Contributions {
+			totals.BranchCommits += contribution.CommitCount
+		}
+	}
+
+	// a single walk of the history lists every commit once
+	aggregated := newBranchReport("")
+	err := run.streamGitLog(gitCommand(repoPath, run.gitLogArgs(run.analyzedRevisions(branchReports), fileFilter)...), run.batchSize, func(commits []CommitRecord) {
+		run.aggregateCommits(aggregated, commits, fileFilter)
+	}, &aggregated.Parsing)
+	if err != nil {
+		log.Printf("Aggregating the repository totals failed: %v", err)
+		report.Totals = nil
+		return
+	}
+
+	totals.Contributions = aggregated.Contributions
+	for _, contribution := range totals.Contributions {
+		totals.CommitCount += contribution.CommitCount
+		totals.LinesEdited += contribution.LinesEdited
+	}
+	report.Totals = totals
+}
+
+// uniqueLogRange narrows the log range of a branch to the commits none of the other branches contains.
+func (run *analysisRun) uniqueLogRange(logRange string, otherBranches []string) string {
+	if len(otherBranches) == 0 {
+		return logRange
+	}
+	revisions := make([]string, 0, len(otherBranches))
+	for _, branchName := range otherBranches {
+		revisions = append(revisions, run.branchRevision(branchName))
+	}
+	return logRange + " --not " + strings.Join(revisions, " ")
+}
+
+// reportContributions returns the contributions of the repository totals, which count each commit once, or else
+// the contributions to all branches.
+func reportContributions(branchReports map[string]*BranchReport, totals *RepositoryTotals) []*UserContribution {
+	var contributions []*UserContribution
+	if totals != nil {
+		for _, contribution := range totals.Contributions {
+			contributions = append(contributions, contribution)
+		}
+		return contributions
+	}
+	for _, report := range branchReports {
+		for _, contribution := range report.Contributions {
+			contributions = append(contributions, contribution)
+		}
+	}
+	return contributions
+}
@@ -0,0 +1,445 @@
+package gogitstats
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// liveReloadScript is added to the served report. It polls the version of the report and reloads
+// the page once a refreshed report is available.
+const liveReloadScript = `<script>
+const servedVersion = "%s";
+setInterval(() => {
+	fetch('version').then(response => response.text()).then(version => {
+		if (version !== servedVersion) {
+			location.reload();
+		}
+	}).catch(() => {});
+}, %d);
+</script>
+</body>`
+
+// repositoryIndexTemplate lists the served reports readable by the user, if Watch serves multiple repositories.
+const repositoryIndexTemplate = `<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+<meta charset="utf-8">
+<title>{{t "Repositories"}}</title>
+</head>
+<body>
+<h1>{{t "Repositories"}}</h1>
+{{if .Repositories}}<ul>
+{{range .Repositories}}<li><a href="/r/{{.}}/">{{.}}</a></li>
+{{end}}</ul>
+{{else}}<p>{{t "No reports are shared with you."}}</p>
+{{end}}</body>
+</html>`
+
+// minRequestRefresh is the minimum time between refreshes of a report triggered by requests, see ServeOptions.
+const minRequestRefresh = 10 * time.Second
+
+// WatchedRepository is a repository served by Watch or Serve.
+type WatchedRepository struct {
+	Path string
+	Name string
+	// URL is the remote the local clone (see PrepareRepository) is fetched from before checking for changes,
+	// only supported by Serve
+	URL string
+}
+
+// ServeOptions control how Serve serves and refreshes the reports.
+type ServeOptions struct {
+	// Address the reports are served on (e.g., localhost:8080)
+	Address string
+	// Interval re-analyzes changed repositories periodically, if 0 they are checked when a report is requested
+	// (at most every minRequestRefresh)
+	Interval time.Duration
+	// Auth is the OIDC login protecting the reports, nil serves the reports to everyone
+	Auth *OIDCAuthenticator
+}
+
+// liveReport holds the most recent report of a repository served by Watch or Serve.
+type liveReport struct {
+	repository WatchedRepository
+	// state is the fingerprint of the branches the report was generated of, see branchState
+	state string
+	// refreshMutex serializes refreshes, checked is the time of the last one
+	refreshMutex sync.Mutex
+	checked      time.Time
+
+	mutex      sync.RWMutex
+	html       string
+	viewerHTML string
+	version    string
+}
+
+func (report *liveReport) update(html string, viewerHTML string) {
+	report.mutex.Lock()
+	defer report.mutex.Unlock()
+	report.html = html
+	report.viewerHTML = viewerHTML
+	report.version = fmt.Sprintf("%d", time.Now().UnixNano())
+}
+
+func (report *liveReport) get(admin bool) (string, string) {
+	report.mutex.RLock()
+	defer report.mutex.RUnlock()
+	if admin {
+		return report.html, report.version
+	}
+	return report.viewerHTML, report.version
+}
+
+// Watch serves live HTML reports of the local repositories and refreshes them whenever a branch of a
+// repository changes (new commits, new or deleted branches).
+//
+// The repositories are polled with `git for-each-ref`, which works on all platforms and file systems
+// (e.g., network drives) without watching the files below .git. Unchanged branches are only analyzed
+// once if the analyzer uses the cache (see Options.UseCache).
+//
+// A single repository is served on "/". Multiple repositories are served on "/r/<name>/" and listed on "/".
+// The access rules of the configuration (see AccessConfig) require the OIDC login, viewers get the
+// reports of their repositories only, generated with the viewer profile.
+//
+// Parameters:
+//   - analyzer: The analyzer of the repositories.
+//   - repositories: The repositories to serve, their names must be unique.
+//   - interval: The interval of checking the repositories for changes.
+//   - address: The address the reports are served on (e.g., localhost:8080).
+//   - auth: The OIDC login protecting the reports, nil serves the reports to everyone.
+//
+// Returns:
+//   - An error if the initial reports could not be generated or the server failed. Otherwise, it runs forever.
+func Watch(analyzer *Analyzer, repositories []WatchedRepository, interval time.Duration, address string, auth *OIDCAuthenticator) error {
+	if interval <= 0 {
+		return fmt.Errorf("watch interval must be positive, given: %s", interval)
+	}
+	for _, repository := range repositories {
+		if repository.URL != "" {
+			return fmt.Errorf("watching is only supported for local repositories, given: %s", repository.URL)
+		}
+	}
+	return serveLiveReports(analyzer, repositories, ServeOptions{Address: address, Interval: interval, Auth: auth})
+}
+
+// Serve serves the HTML reports of the repositories like Watch, which are re-analyzed on a timer or when a
+// report is requested (see ServeOptions.Interval), so teams can host an always current dashboard. Clones of
+// repositories given by URL are fetched before they are checked for changes.
+//
+// Parameters:
+//   - analyzer: The analyzer of the repositories.
+//   - repositories: The repositories to serve, their names must be unique.
+//   - options: The address and refreshing of the served reports.
+//
+// Returns:
+//   - An error if the initial reports could not be generated or the server failed. Otherwise, it runs forever.
+func Serve(analyzer *Analyzer, repositories []WatchedRepository, options ServeOptions) error {
+	if options.Interval < 0 {
+		return fmt.Errorf("refresh interval must not be negative, given: %s", options.Interval)
+	}
+	return serveLiveReports(analyzer, repositories, options)
+}
+
+// serveLiveReports serves the reports of the repositories and refreshes them when their branches change, see
+// Watch and Serve.
+func serveLiveReports(analyzer *Analyzer, repositories []WatchedRepository, options ServeOptions) error {
+	if len(repositories) == 0 {
+		return fmt.Errorf("no repositories to serve")
+	}
+	interval, address, auth := options.Interval, options.Address, options.Auth
+
+	access := analyzer.config.Access
+	if access != nil && auth == nil {
+		return fmt.Errorf("access rules of the configuration require the OIDC login")
+	}
+
+	viewer := analyzer
+	if access != nil && access.ViewerProfile != "" {
+		profile, err := selectOutputProfile(analyzer.config, access.ViewerProfile)
+		if err != nil {
+			return err
+		}
+		viewerAnalyzer := *analyzer
+		viewerAnalyzer.profile = profile
+		viewer = &viewerAnalyzer
+	}
+
+	generate := func(repository WatchedRepository) (string, string, error) {
+		report, err := analyzer.Analyze(repository.Path, repository.Name)
+		if err != nil {
+			return "", "", err
+		}
+		html, err := report.HTML()
+		if err != nil || viewer == analyzer {
+			return html, html, err
+		}
+		viewerReport := *report
+		viewerReport.analyzer = viewer
+		viewerHTML, err := viewerReport.HTML()
+		return html, viewerHTML, err
+	}
+
+	reports := make(map[string]*liveReport)
+	var names []string
+	for _, repository := range repositories {
+		if _, ok := reports[repository.Name]; ok {
+			return fmt.Errorf("repository name '%s' is not unique", repository.Name)
+		}
+
+		if repository.URL != "" {
+			if err := updateClone(repository.Path); err != nil {
+				return fmt.Errorf("fetching '%s' failed: %w", repository.URL, err)
+			}
+		}
+		state, err := branchState(repository.Path)
+		if err != nil {
+			return err
+		}
+		html, viewerHTML, err := generate(repository)
+		if err != nil {
+			return fmt.Errorf("generating the report of '%s' failed: %w", repository.Name, err)
+		}
+
+		report := &liveReport{repository: repository, state: state, checked: time.Now()}
+		report.update(html, viewerHTML)
+		reports[repository.Name] = report
+		names = append(names, repository.Name)
+	}
+	sort.Strings(names)
+
+	// refresh re-analyzes a repository whose branches changed, unless it was checked less than minAge ago
+	refresh := func(report *liveReport, minAge time.Duration) {
+		report.refreshMutex.Lock()
+		defer report.refreshMutex.Unlock()
+		if time.Since(report.checked) < minAge {
+			return
+		}
+		report.checked = time.Now()
+
+		name := report.repository.Name
+		if report.repository.URL != "" {
+			if err := updateClone(report.repository.Path); err != nil {
+				log.Printf("Fetching repository '%s' failed: %v", name, err)
+			}
+		}
+		current, err := branchState(report.repository.Path)
+		if err != nil {
+			log.Printf("Checking repository '%s' for changes failed: %v", name, err)
+			return
+		}
+		if current == report.state {
+			return
+		}
+
+		log.Printf("Repository '%s' changed, refreshing report", name)
+		html, viewerHTML, err := generate(report.repository)
+		if err != nil {
+			log.Printf("Refreshing report of '%s' failed: %v", name, err)
+			return
+		}
+		report.state = current
+		report.update(html, viewerHTML)
+		log.Printf("Report of '%s' refreshed", name)
+	}
+
+	canRead := func(r *http.Request, repoName string) bool {
+		return access == nil || access.canRead(requestUser(r), repoName)
+	}
+	isAdmin := func(r *http.Request) bool {
+		return access == nil || access.isAdmin(requestUser(r))
+	}
+
+	serveReport := func(w http.ResponseWriter, r *http.Request, report *liveReport, versionOnly bool) {
+		if !canRead(r, report.repository.Name) {
+			http.Error(w, "You are not allowed to read this report", http.StatusForbidden)
+			return
+		}
+		if interval == 0 && !versionOnly {
+			refresh(report, minRequestRefresh)
+		}
+		html, version := report.get(isAdmin(r))
+		if versionOnly {
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprint(w, version)
+			return
+		}
+		if interval > 0 {
+			html = strings.Replace(html, "</body>", fmt.Sprintf(liveReloadScript, version, interval.Milliseconds()), 1)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, html)
+	}
+
+	mux := http.NewServeMux()
+	if len(repositories) == 1 {
+		report := reports[repositories[0].Name]
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" {
+				http.NotFound(w, r)
+				return
+			}
+			serveReport(w, r, report, false)
+		})
+		mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+			serveReport(w, r, report, true)
+		})
+	} else {
+		index, err := template.New("index").Funcs(template.FuncMap{
+			"t": func(message string) string {
+				if translated, ok := analyzer.messages[message]; ok && translated != "" {
+					return translated
+				}
+				return message
+			},
+		}).Parse(repositoryIndexTemplate)
+		if err != nil {
+			return err
+		}
+
+		mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/" {
+				http.NotFound(w, r)
+				return
+			}
+			var readable []string
+			for _, name := range names {
+				if canRead(r, name) {
+					readable = append(readable, name)
+				}
+			}
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			data := map[string]interface{}{"Language": analyzer.options.Language, "Repositories": readable}
+			if err := index.Execute(w, data); err != nil {
+				log.Printf("Serving the list of repositories failed: %v", err)
+			}
+		})
+		mux.HandleFunc("/r/", func(w http.ResponseWriter, r *http.Request) {
+			name, versionOnly := strings.TrimPrefix(r.URL.Path, "/r/"), false
+			if strings.HasSuffix(name, "/version") {
+				name, versionOnly = strings.TrimSuffix(name, "/version"), true
+			} else if !strings.HasSuffix(name, "/") {
+				http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+				return
+			}
+			report, ok := reports[strings.TrimSuffix(name, "/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			serveReport(w, r, report, versionOnly)
+		})
+	}
+
+	var handler http.Handler = mux
+	if auth != nil {
+		auth.requestGroups = access != nil && access.usesGroups()
+		handler = auth.protect(mux)
+		log.Printf("Reports are protected by OIDC login at: %s", auth.issuer)
+	}
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- http.ListenAndServe(address, handler)
+	}()
+	checking := fmt.Sprintf("checking for changes every %s", interval)
+	if interval == 0 {
+		checking = "checking for changes when a report is requested"
+	}
+	if len(repositories) == 1 {
+		log.Printf("Serving live report of %s on http://%s, %s", repositories[0].Name, address, checking)
+	} else {
+		log.Printf("Serving live reports of %d repositories on http://%s, %s", len(repositories), address, checking)
+	}
+	if interval == 0 {
+		return fmt.Errorf("serving the report failed: %w", <-serverErrors)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-serverErrors:
+			return fmt.Errorf("serving the report failed: %w", err)
+		case <-ticker.C:
+			for _, name := range names {
+				refresh(reports[name], 0)
+			}
+		}
+	}
+}
+
+// branchState returns a fingerprint of the tips of all local branches and remote-tracking branches of TRACKED_REMOTE,
+// which are analyzed as well (see listBranchRefs).
+func branchState(repoPath string) (string, error) {
+	cmd := gitCommand(repoPath, "for-each-ref", "--format=%(objectname) %(refname)", "refs/heads", "refs/remotes/"+TRACKED_REMOTE)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git for-each-ref failed: %w, output: %s", err, output)
+	}
+	sum := sha1.Sum(output)
+	return fmt.Sprintf("%x", sum), nil
+}
+
+// updateClone fetches the clone of a remote repository (see PrepareRepository) and moves its local branches to the
+// fetched remote branches, local branches of deleted remote branches are deleted, except the checked out one. The
+// checked out branch is reset with its index and working tree, so the clone stays clean. Remote branches without a
+// local branch are analyzed by their remote-tracking refs, no local branch is created for them.
+func updateClone(repoPath string) error {
+	remoteURL := originURL(repoPath)
+	if output, err := gitRemoteCommand(repoPath, remoteURL, "fetch", "--prune", "origin").CombinedOutput(); err != nil {
+		return remoteCommandError("fetch "+repoPath, err, output)
+	}
+	output, err := gitCommand(repoPath, "for-each-ref", "--format=%(refname) %(objectname)", "refs/heads", "refs/remotes/origin").Output()
+	if err != nil {
+		return fmt.Errorf("git for-each-ref failed: %w", err)
+	}
+	headOutput, _ := gitCommand(repoPath, "symbolic-ref", "--quiet", "HEAD").Output()
+	head := strings.TrimPrefix(strings.TrimSpace(string(headOutput)), "refs/heads/")
+
+	remote := make(map[string]string)
+	var local []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		refName, objectName, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		if branch, ok := strings.CutPrefix(refName, "refs/remotes/origin/"); ok && branch != "HEAD" {
+			remote[branch] = objectName
+		} else if branch, ok := strings.CutPrefix(refName, "refs/heads/"); ok {
+			local = append(local, branch)
+		}
+	}
+
+	var updates strings.Builder
+	for _, branch := range local {
+		objectName, ok := remote[branch]
+		switch {
+		case branch == head:
+			// moving the checked out branch by update-ref would leave the index and the working tree behind
+		case ok:
+			fmt.Fprintf(&updates, "update refs/heads/%s %s\n", branch, objectName)
+		default:
+			fmt.Fprintf(&updates, "delete refs/heads/%s\n", branch)
+		}
+	}
+	cmd := gitCommand(repoPath, "update-ref", "--stdin")
+	cmd.Stdin = strings.NewReader(updates.String())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git update-ref failed: %w, output: %s", err, output)
+	}
+
+	if objectName, ok := remote[head]; ok && head != "" {
+		// blobs of partial clones are fetched on demand, hence the remote command
+		if output, err := gitRemoteCommand(repoPath, remoteURL, "reset", "--hard", "--quiet", objectName).CombinedOutput(); err != nil {
+			return remoteCommandError("reset "+repoPath+" to "+objectName, err, output)
+		}
+	}
+	return nil
+}
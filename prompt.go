@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/vdmitriyev/gogitstats/pkg/gogitstats"
+)
+
+// promptBranchThreshold is the number of branches from which the branches to analyze are asked for, if the
+// tool runs in a terminal and `--branches` is not given.
+const promptBranchThreshold = 10
+
+// isInteractive reports whether both stdin and stdout are terminals, so the user can answer prompts.
+func isInteractive() bool {
+	for _, file := range []*os.File{os.Stdin, os.Stdout} {
+		info, err := file.Stat()
+		if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// promptBranches lists the branches, most recently active first, and asks which of them are analyzed.
+//
+// Parameters:
+//   - input, output: The terminal.
+//   - repoName: The name of the repository.
+//   - branches: The branches ordered by recent activity.
+//   - mainBranch: The main branch, which is always analyzed.
+//
+// Returns:
+//   - The names of the selected branches, nil if all branches are analyzed.
+//   - An error if the input could not be read.
+func promptBranches(input io.Reader, output io.Writer, repoName string, branches []gogitstats.BranchActivity, mainBranch string) ([]string, error) {
+	fmt.Fprintf(output, "Repository '%s' has %d branches, most recently active first:\n", repoName, len(branches))
+	width := 0
+	for _, branch := range branches {
+		width = max(width, len(branch.Name))
+	}
+	for i, branch := range branches {
+		note := ""
+		if branch.Name == mainBranch {
+			note = " (always analyzed)"
+		}
+		fmt.Fprintf(output, "%4d) %-*s  %s%s\n", i+1, width, branch.Name, branch.LastCommit, note)
+	}
+
+	reader := bufio.NewReader(input)
+	for {
+		fmt.Fprint(output, "Select branches to analyze (e.g., 1-5,8), empty for all: ")
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, fmt.Errorf("failed to read selection: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return nil, nil
+		}
+
+		selected, err := parseBranchSelection(line, len(branches))
+		if err != nil {
+			fmt.Fprintf(output, "%v\n", err)
+			continue
+		}
+		var names []string
+		for _, index := range selected {
+			names = append(names, branches[index-1].Name)
+		}
+		return names, nil
+	}
+}
+
+// parseBranchSelection parses a comma-separated list of numbers and ranges (e.g., 1-5,8) of branches.
+//
+// Returns:
+//   - The selected numbers (starting at 1) in the order given, without duplicates.
+//   - An error if a number is out of range or the list is malformed.
+func parseBranchSelection(selection string, count int) ([]int, error) {
+	var selected []int
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(selection, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		first, last, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(first))
+		if err != nil {
+			return nil, fmt.Errorf("invalid selection '%s', expected numbers or ranges (e.g., 1-5,8)", part)
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(strings.TrimSpace(last)); err != nil {
+				return nil, fmt.Errorf("invalid selection '%s', expected numbers or ranges (e.g., 1-5,8)", part)
+			}
+		}
+		if from < 1 || to > count || from > to {
+			return nil, fmt.Errorf("invalid selection '%s', expected numbers between 1 and %d", part, count)
+		}
+		for index := from; index <= to; index++ {
+			if !seen[index] {
+				seen[index] = true
+				selected = append(selected, index)
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no branches selected")
+	}
+	return selected, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+
+	"github.com/vdmitriyev/gogitstats/pkg/gogitstats"
+)
+
+// runRender implements the `render` command, which renders a report exported with `--format json` again
+// without analyzing the repository (e.g., in another format, language or with fewer sections).
+func runRender(args []string) {
+	defaults := gogitstats.DefaultOptions()
+
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	optionData := flags.String("data", "", "Path to a report exported with `--format json`")
+	optionFormat := flags.String("format", defaults.Format, "Format of the generated report: "+strings.Join(gogitstats.REPORT_FORMATS, ", "))
+	optionOutput, optionOverwrite := outputOptions(flags)
+	optionOffline := flags.Bool("offline", false, "Inline Bootstrap into HTML reports instead of linking it from the CDN, so they work without internet access (e.g., on air-gapped networks)")
+	optionTheme := flags.String("theme", defaults.Theme, "Default theme of the HTML report: 'dark' or 'light'")
+	optionLanguage := flags.String("lang", defaults.Language, "Language of the report: "+strings.Join(gogitstats.ReportLanguages(), ", "))
+	optionSections := flags.String("sections", "", "Comma-separated list of report sections: "+strings.Join(gogitstats.REPORT_SECTIONS, ", ")+" (default all exported)")
+	optionColumns := flags.String("columns", strings.Join(gogitstats.REPORT_COLUMNS, ","), "Comma-separated list of columns shown in the report")
+	optionConfig := flags.String("config", "", "Path to a YAML configuration file defining output profiles. Optional")
+	optionProfile := flags.String("profile", "", "Name of an output profile defined in the configuration file (e.g., external). Optional")
+	flags.Parse(args)
+	useOutput(*optionOutput)
+
+	if *optionData == "" {
+		log.Fatal("Please provide the path to a report exported with `--format json` with option `--data`")
+	}
+
+	options := gogitstats.Options{
+		Profile:   *optionProfile,
+		Columns:   strings.Split(*optionColumns, ","),
+		Format:    *optionFormat,
+		Theme:     *optionTheme,
+		Offline:   *optionOffline,
+		Output:    *optionOutput,
+		Overwrite: *optionOverwrite,
+		Language:  *optionLanguage,
+	}
+	if *optionSections != "" {
+		options.Sections = strings.Split(*optionSections, ",")
+	}
+	if *optionConfig != "" {
+		config, err := gogitstats.LoadConfig(*optionConfig)
+		if err != nil {
+			log.Fatalf("Error loading configuration: %v", err)
+		}
+		options.Config = config
+	}
+
+	analyzer, err := gogitstats.NewAnalyzer(options)
+	if err != nil {
+		log.Fatalf("Given options are not supported: %v", err)
+	}
+
+	report, err := analyzer.LoadReport(*optionData)
+	if err != nil {
+		log.Fatalf("Error loading report data: %v", err)
+	}
+
+	if _, err := report.Write(); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}